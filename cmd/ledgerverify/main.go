@@ -0,0 +1,35 @@
+// Comando ledgerverify recorre el libro mayor completo y reporta el primer
+// eslabón roto de la cadena de hashes. Termina con código 1 si la cadena no es válida.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/sanchezta/batea-backend/internal/db"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+func main() {
+	cfg := config.Load()
+
+	// Solo lectura: no se migra el esquema de la base que se audita
+	gormDB, err := db.Open(cfg)
+	if err != nil {
+		log.Fatalf("No se pudo conectar a la base de datos: %v", err)
+	}
+
+	ledger := service.NewLedgerService(repository.NewLedgerRepository(gormDB))
+	report, err := ledger.Verify()
+	if err != nil {
+		log.Fatalf("No se pudo verificar el libro mayor: %v", err)
+	}
+
+	if !report.Valid {
+		log.Printf("Cadena ROTA en la entrada %d: %s (%d entradas válidas antes)", report.BrokenAt, report.Reason, report.Entries)
+		os.Exit(1)
+	}
+	log.Printf("Cadena íntegra: %d entradas, último hash %s", report.Entries, report.LastHash)
+}
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanchezta/batea-backend/internal/certificate"
 	"github.com/sanchezta/batea-backend/internal/config"
 	"github.com/sanchezta/batea-backend/internal/controller"
 	"github.com/sanchezta/batea-backend/internal/db"
+	"github.com/sanchezta/batea-backend/internal/geo"
+	"github.com/sanchezta/batea-backend/internal/identity"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/payout"
+	"github.com/sanchezta/batea-backend/internal/pricing"
 	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/secrets"
 	"github.com/sanchezta/batea-backend/internal/service"
+	"github.com/sanchezta/batea-backend/internal/storage"
 )
 
 func main() {
@@ -24,15 +35,181 @@ func main() {
 		log.Fatalf("No se pudo inicializar la base de datos: %v", err)
 	}
 
+	// Almacenamiento de documentos (local o GCS)
+	if cfg.StorageBackend == "local" && cfg.FileURLSecret == "" {
+		if !cfg.DevMode {
+			log.Fatal("FILE_URL_SECRET es obligatorio con STORAGE_BACKEND=local fuera de DEV_MODE")
+		}
+		log.Println("Advertencia: FILE_URL_SECRET no configurado. Las URLs de archivos firmadas dejan de servir al reiniciar.")
+		cfg.FileURLSecret = rand.Text()
+	}
+	store, err := storage.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("No se pudo inicializar el almacenamiento: %v", err)
+	}
+
 	// 3. Inyección de dependencias (Arquitectura limpia)
 	userRepo := repository.NewUserRepository(gormDB)
 	minerRepo := repository.NewMinerRepository(gormDB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(gormDB)
+	phoneVerificationRepo := repository.NewPhoneVerificationRepository(gormDB)
+	roleRepo := repository.NewRoleRepository(gormDB)
+	documentRepo := repository.NewDocumentRepository(gormDB)
+	saleRepo := repository.NewSaleRepository(gormDB)
+	buyerRepo := repository.NewBuyerRepository(gormDB)
+	siteRepo := repository.NewMiningSiteRepository(gormDB)
+	certificateRepo := repository.NewCertificateRepository(gormDB)
+	journalRepo := repository.NewJournalRepository(gormDB)
+	payoutRepo := repository.NewPayoutRepository(gormDB)
+	priceRepo := repository.NewPriceRepository(gormDB)
+	reportRepo := repository.NewReportRepository(gormDB)
+	ledgerRepo := repository.NewLedgerRepository(gormDB)
+
+	// Verificador de ID tokens de Identity Platform (opcional)
+	var idVerifier identity.Verifier
+	if cfg.FirebaseProjectID != "" {
+		idVerifier = identity.NewVerifier(cfg.FirebaseProjectID, identity.NewJWKSKeySource(cfg.IdentityJWKSURL))
+	} else {
+		log.Println("Advertencia: FIREBASE_PROJECT_ID no configurado. Login con ID token deshabilitado.")
+	}
+
+	// Proveedor de SMS
+	var smsSender service.SMSSender
+	switch cfg.SMSProvider {
+	case "memory":
+		smsSender = service.NewInMemorySMSSender()
+	default:
+		smsSender = service.NewLogSMSSender()
+	}
+
+	// Llaves maestras que cifran los secretos TOTP de los mineros
+	totpKeyFile, created, err := secrets.OpenOrCreateKeyFile(cfg.TOTPKeyFile)
+	if err != nil {
+		log.Fatalf("Error al cargar las llaves de cifrado TOTP: %v", err)
+	}
+	if created {
+		log.Printf("Advertencia: se creó un archivo de llaves TOTP nuevo en %s. Respáldelo: sin él no se pueden descifrar los secretos.", cfg.TOTPKeyFile)
+	}
+	if cfg.TOTPRecoverySecret == "" {
+		if !cfg.DevMode {
+			log.Fatal("TOTP_RECOVERY_SECRET es obligatorio fuera de DEV_MODE")
+		}
+		log.Println("Advertencia: TOTP_RECOVERY_SECRET no configurado. Los códigos de recuperación dejan de servir al reiniciar.")
+		cfg.TOTPRecoverySecret = rand.Text()
+	}
+
+	userService := service.NewUserService(userRepo, roleRepo, idVerifier)
+	phoneVerificationService := service.NewPhoneVerificationService(phoneVerificationRepo, userRepo, smsSender)
+	minerService := service.NewMinerService(minerRepo, userRepo, documentRepo, store, cfg, secrets.NewEnvelope(totpKeyFile), phoneVerificationService)
+	authService := service.NewAuthService(userRepo, minerRepo, refreshTokenRepo, idVerifier, cfg)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	buyerService := service.NewBuyerService(buyerRepo, roleRepo, store)
+	// Catálogo de municipios para validar la ubicación de los sitios
+	var gazetteer geo.Gazetteer
+	switch {
+	case cfg.MunicipalitiesFile != "":
+		catalog, err := geo.LoadBoundsGazetteer(cfg.MunicipalitiesFile)
+		if err != nil {
+			log.Fatalf("Error al cargar el catálogo de municipios: %v", err)
+		}
+		log.Printf("Catálogo de municipios cargado: %d municipios", catalog.Len())
+		gazetteer = catalog
+	case cfg.DevMode:
+		log.Println("Advertencia: MUNICIPALITIES_FILE no configurado. Los sitios mineros solo se validan contra los límites de Colombia.")
+	default:
+		log.Fatal("MUNICIPALITIES_FILE es obligatorio fuera de DEV_MODE")
+	}
+	siteService := service.NewMiningSiteService(siteRepo, minerService, gazetteer)
+	// Llave de firma de los certificados de origen
+	var signer *certificate.Signer
+	switch {
+	case cfg.CertSigningKey != "":
+		signer, err = certificate.ParseSigner(cfg.CertSigningKey)
+	case cfg.DevMode:
+		log.Println("Advertencia: CERT_SIGNING_KEY no configurado. Los certificados se firman con una llave efímera.")
+		signer, err = certificate.GenerateSigner()
+	default:
+		log.Fatal("CERT_SIGNING_KEY es obligatorio fuera de DEV_MODE")
+	}
+	if err != nil {
+		log.Fatalf("Error al cargar la llave de firma de certificados: %v", err)
+	}
+	for _, key := range cfg.CertPreviousPublicKeys {
+		if err := signer.AddPreviousKey(key); err != nil {
+			log.Fatalf("Error en CERT_PREVIOUS_PUBLIC_KEYS: %v", err)
+		}
+	}
+	certificateService := service.NewCertificateService(certificateRepo, saleRepo, buyerService, siteService, store, signer, cfg.PublicBaseURL)
+	walletService := service.NewWalletService(journalRepo, saleRepo)
+
+	// Fuentes de precios de referencia del oro
+	var priceSources []pricing.Source
+	if cfg.PriceFile != "" {
+		priceSources = append(priceSources, pricing.NewFileSource(cfg.PriceFile))
+	}
+	if cfg.PriceSourceURL != "" {
+		priceSources = append(priceSources, pricing.NewHTTPSource("http", cfg.PriceSourceURL, cfg.PriceSourceAPIKey))
+	}
+	if len(priceSources) == 0 {
+		log.Println("Advertencia: sin fuentes de precios de referencia. Las ventas no se comparan con el precio del día.")
+	}
+	priceService := service.NewPriceService(priceRepo, priceSources, cfg.PriceFlagBelowPercent)
+	reportService := service.NewReportService(reportRepo, store, cfg.ReportPlatformNIT)
+
+	// Proveedores de pago a mineros
+	var payoutProviders []payout.PayoutProvider
+	if cfg.PayoutBankURL != "" {
+		payoutProviders = append(payoutProviders, payout.NewBankTransferProvider(payout.HTTPConfig{
+			BaseURL: cfg.PayoutBankURL, APIKey: cfg.PayoutBankAPIKey, WebhookSecret: cfg.PayoutBankWebhookSecret,
+		}))
+	}
+	if cfg.PayoutWalletURL != "" {
+		payoutProviders = append(payoutProviders, payout.NewMobileWalletProvider(payout.HTTPConfig{
+			BaseURL: cfg.PayoutWalletURL, APIKey: cfg.PayoutWalletAPIKey, WebhookSecret: cfg.PayoutWalletWebhookSecret,
+		}))
+	}
+	if len(payoutProviders) == 0 {
+		// El proveedor falso da por pagado todo y acepta webhooks sin firma
+		if !cfg.DevMode {
+			log.Fatal("PAYOUT_BANK_URL o PAYOUT_WALLET_URL es obligatorio fuera de DEV_MODE")
+		}
+		log.Println("Advertencia: sin proveedores de pago configurados. Se usa el proveedor falso en memoria.")
+		payoutProviders = append(payoutProviders, payout.NewFakeProvider(true))
+	}
+	payoutService := service.NewPayoutService(payoutRepo, minerService, payout.NewRegistry(payoutProviders...), cfg.PayoutMaxAttempts)
+	saleService := service.NewSaleService(saleRepo, minerService, buyerService, siteService, service.NewQuotaEngine(cfg), certificateService, priceService)
+
+	// Barrido periódico de archivos que ninguna fila referencia
+	if cfg.StorageSweepInterval > 0 {
+		service.NewStorageSweeper(store, documentRepo, cfg.StorageSweepGrace).
+			Start(context.Background(), cfg.StorageSweepInterval)
+	}
 
-	userService := service.NewUserService(userRepo)
-	minerService := service.NewMinerService(minerRepo, userRepo, cfg)
+	if len(priceSources) > 0 && cfg.PriceRefreshInterval > 0 {
+		service.NewPriceRefresher(priceService).Start(context.Background(), cfg.PriceRefreshInterval)
+	}
+
+	// Envío y reintentos de pagos, y consulta de los que no recibieron webhook
+	if cfg.PayoutRetryInterval <= 0 {
+		log.Fatal("PAYOUT_RETRY_INTERVAL debe ser mayor que cero: los pagos se envían desde el proceso periódico")
+	}
+	service.NewPayoutWorker(payoutService).Start(context.Background(), cfg.PayoutRetryInterval)
 
 	userController := controller.NewUserController(userService, minerService)
 	minerController := controller.NewMinerController(minerService)
+	authController := controller.NewAuthController(authService)
+	phoneVerificationController := controller.NewPhoneVerificationController(phoneVerificationService)
+	roleController := controller.NewRoleController(roleService)
+	reviewController := controller.NewReviewController(minerService)
+	saleController := controller.NewSaleController(saleService, minerService)
+	buyerController := controller.NewBuyerController(buyerService)
+	siteController := controller.NewMiningSiteController(siteService, minerService)
+	certificateController := controller.NewCertificateController(certificateService, saleService)
+	walletController := controller.NewWalletController(walletService, minerService, buyerService)
+	payoutController := controller.NewPayoutController(payoutService, minerService)
+	priceController := controller.NewPriceController(priceService)
+	reportController := controller.NewReportController(reportService, buyerService)
+	ledgerController := controller.NewLedgerController(service.NewLedgerService(ledgerRepo))
 
 	// 4. Configurar router de Gin
 	router := gin.Default()
@@ -43,11 +220,129 @@ func main() {
 		// Registro de usuario
 		v1.POST("/users/register", userController.RegisterUser)
 
-		// Rutas de mineros
-		v1.POST("/miners", minerController.RegisterMiner)
-		v1.GET("/miners/:id", minerController.GetMinerByID)
-		v1.GET("/miners", minerController.GetAllMiners)
-		v1.GET("/miners/:id/totp", minerController.GetCurrentTOTP)
+		// Autenticación
+		v1.POST("/auth/login", authController.Login)
+		v1.POST("/auth/token", authController.LoginWithIDToken)
+		v1.POST("/auth/refresh", authController.Refresh)
+		v1.POST("/auth/logout", authController.Logout)
+		v1.POST("/auth/phone/challenge", phoneVerificationController.Challenge)
+		v1.POST("/auth/phone/verify", phoneVerificationController.Verify)
+
+		authRequired := middleware.AuthRequired(authService, userRepo)
+
+		// Rutas de mineros (requieren usuario autenticado)
+		miners := v1.Group("/miners")
+		miners.Use(authRequired)
+		{
+			miners.POST("", middleware.RequirePermission(models.PermMinersRegister), minerController.RegisterMiner)
+			miners.GET("/:id", minerController.GetMinerByID)
+			miners.GET("", middleware.RequirePermission(models.PermMinersList), minerController.GetAllMiners)
+			miners.PATCH("/:id", minerController.UpdateMiner)
+			miners.GET("/:id/documents/:kind", minerController.DownloadDocument)
+			miners.PUT("/:id/documents/:kind", minerController.ReplaceDocument)
+			miners.GET("/:id/documents/:kind/url", minerController.GetDocumentURL)
+			miners.POST("/:id/totp/confirm", minerController.ConfirmTOTP)
+			miners.POST("/:id/totp/reenroll", minerController.ReenrollTOTP)
+			miners.GET("/:id/sales", saleController.ListMinerSales)
+			miners.GET("/:id/quota", saleController.GetQuota)
+			miners.GET("/:id/wallet", walletController.GetMinerWallet)
+			miners.POST("/:id/payouts", payoutController.RequestPayout)
+			miners.GET("/:id/payouts", payoutController.ListMinerPayouts)
+			miners.POST("/:id/sites", siteController.CreateSite)
+			miners.GET("/:id/sites", siteController.ListSites)
+		}
+
+		// Compras de oro autorizadas con el TOTP del minero
+		sales := v1.Group("/sales")
+		sales.Use(authRequired)
+		{
+			sales.POST("", middleware.RequirePermission(models.PermSalesCreate), saleController.CreateSale)
+			sales.GET("/:id", saleController.GetSale)
+			sales.GET("/:id/certificate", certificateController.DownloadSaleCertificate)
+			sales.POST("/:id/settlement", middleware.RequirePermission(models.PermSalesSettle), walletController.SettleSale)
+		}
+
+		// Precios de referencia del oro
+		v1.GET("/prices", authRequired, priceController.ListPrices)
+
+		// Reportes regulatorios de compras a mineros de subsistencia
+		reports := v1.Group("/reports/regulatory")
+		reports.Use(authRequired)
+		{
+			reports.GET("", reportController.ListReports)
+			reports.POST("", reportController.Submit)
+			reports.GET("/preview", reportController.Preview)
+			reports.GET("/:id", reportController.GetReport)
+			reports.GET("/:id/file", reportController.DownloadFile)
+		}
+
+		// Pagos a mineros; los webhooks son públicos y cada proveedor verifica su firma
+		v1.GET("/payouts/:id", authRequired, payoutController.GetPayout)
+		v1.POST("/payouts/webhooks/:provider", payoutController.Webhook)
+
+		// Verificación pública de certificados de origen (destino del código QR)
+		v1.GET("/certificates/public-key", certificateController.PublicKey)
+		v1.GET("/certificates/:id", certificateController.Verify)
+		v1.POST("/certificates/:id/verify", certificateController.VerifyCopy)
+
+		// Revisión KYC de mineros (back-office)
+		reviews := v1.Group("/reviews/miners")
+		reviews.Use(authRequired, middleware.RequirePermission(models.PermMinersReview))
+		{
+			reviews.GET("", reviewController.ListQueue)
+			reviews.POST("/:id/claim", reviewController.Claim)
+			reviews.POST("/:id/approve", reviewController.Approve)
+			reviews.POST("/:id/reject", reviewController.Reject)
+			reviews.GET("/:id/history", reviewController.History)
+		}
+
+		// Comercializadores (cualquier usuario autenticado puede solicitar su registro)
+		buyers := v1.Group("/buyers")
+		buyers.Use(authRequired)
+		{
+			buyers.POST("", buyerController.RegisterBuyer)
+			buyers.GET("/me", buyerController.GetMyBuyer)
+			buyers.GET("/:id", buyerController.GetBuyer)
+			buyers.GET("/:id/documents/:kind", buyerController.DownloadDocument)
+			buyers.GET("/:id/wallet", walletController.GetBuyerWallet)
+			buyers.POST("/:id/points", buyerController.AddPurchasePoint)
+			buyers.DELETE("/:id/points/:pointId", buyerController.DeactivatePurchasePoint)
+		}
+
+		// Aprobación de comercializadores (administradores)
+		buyerReviews := v1.Group("/reviews/buyers")
+		buyerReviews.Use(authRequired, middleware.RequirePermission(models.PermBuyersReview))
+		{
+			buyerReviews.GET("", buyerController.ListQueue)
+			buyerReviews.POST("/:id/approve", buyerController.Approve)
+			buyerReviews.POST("/:id/reject", buyerController.Reject)
+		}
+
+		// Aprobación de puntos de compra (administradores)
+		pointReviews := v1.Group("/reviews/purchase-points")
+		pointReviews.Use(authRequired, middleware.RequirePermission(models.PermBuyersReview))
+		{
+			pointReviews.GET("", buyerController.ListPointQueue)
+			pointReviews.POST("/:id/approve", buyerController.ApprovePoint)
+			pointReviews.POST("/:id/reject", buyerController.RejectPoint)
+		}
+
+		// Descarga de archivos locales mediante URL firmada
+		if local, ok := store.(*storage.LocalStorage); ok {
+			v1.GET("/files/*key", controller.NewFileController(local).Serve)
+		}
+
+		// Historial del libro mayor (auditoría)
+		v1.GET("/ledger/:aggregate/:id", authRequired, middleware.RequirePermission(models.PermLedgerRead), ledgerController.History)
+
+		// Administración de roles
+		admin := v1.Group("/admin")
+		admin.Use(authRequired, middleware.RequirePermission(models.PermUsersManageRoles))
+		{
+			admin.GET("/roles", roleController.ListRoles)
+			admin.POST("/users/:id/roles", roleController.AssignRole)
+			admin.DELETE("/users/:id/roles/:role", roleController.RevokeRole)
+		}
 	}
 
 	// 6. Manejar cierre elegante
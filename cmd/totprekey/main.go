@@ -0,0 +1,67 @@
+// Comando totprekey vuelve a cifrar con la llave maestra vigente los secretos
+// TOTP de todos los mineros, incluidos los que aún estén en claro.
+//
+//	-rotate  genera antes una llave nueva y la deja vigente
+//	-prune   si la pasada termina sin fallos, borra del archivo las llaves anteriores
+//
+// El servidor relee el archivo de llaves al cambiar, así que puede seguir en
+// línea mientras corre el comando. Termina con código 1 si algún secreto falló.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/sanchezta/batea-backend/internal/db"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/secrets"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+func main() {
+	rotate := flag.Bool("rotate", false, "generar una llave maestra nueva antes de cifrar")
+	prune := flag.Bool("prune", false, "borrar las llaves anteriores si todos los secretos quedaron con la vigente")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+
+	keyFile, err := secrets.OpenKeyFile(cfg.TOTPKeyFile)
+	if err != nil {
+		log.Fatalf("No se pudo abrir el archivo de llaves: %v", err)
+	}
+	if *rotate {
+		id, err := keyFile.Rotate()
+		if err != nil {
+			log.Fatalf("No se pudo generar la llave nueva: %v", err)
+		}
+		log.Printf("Llave vigente: %s", id)
+	}
+
+	gormDB, err := db.InitPostgres(cfg)
+	if err != nil {
+		log.Fatalf("No se pudo inicializar la base de datos: %v", err)
+	}
+
+	rekeyer := service.NewTOTPRekeyer(repository.NewMinerRepository(gormDB), secrets.NewEnvelope(keyFile))
+	result, err := rekeyer.Run()
+	if err != nil {
+		log.Fatalf("Pasada interrumpida (%d revisados, %d cifrados de nuevo): %v", result.Scanned, result.Rekeyed, err)
+	}
+	log.Printf("%d mineros revisados, %d secretos cifrados de nuevo, %d fallidos", result.Scanned, result.Rekeyed, result.Failed)
+
+	if result.Failed > 0 {
+		if *prune {
+			log.Println("No se borran las llaves anteriores porque hubo secretos fallidos.")
+		}
+		os.Exit(1)
+	}
+	if *prune {
+		removed, err := keyFile.Prune()
+		if err != nil {
+			log.Fatalf("No se pudieron borrar las llaves anteriores: %v", err)
+		}
+		log.Printf("%d llaves anteriores borradas del archivo", removed)
+	}
+}
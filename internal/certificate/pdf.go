@@ -0,0 +1,148 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Data son los datos que se imprimen en el certificado. Las fechas deben venir
+// ya en la zona horaria con la que se quieren mostrar.
+type Data struct {
+	Number   string
+	IssuedAt time.Time
+	SaleID   string
+	SaleDate time.Time
+
+	MinerName string
+	MinerType string
+
+	SiteKind     string
+	SiteName     string
+	TitleNumber  string
+	Municipality string
+	Department   string
+	Mineral      string
+	Latitude     float64
+	Longitude    float64
+
+	BuyerName   string
+	BuyerNIT    string
+	BuyerRUCOM  string
+	PointOfSale string
+
+	WeightGrams   float64
+	Purity        float64
+	FineGoldGrams float64
+
+	VerifyURL string
+}
+
+// qrSide es el tamaño en px de la imagen del código QR antes de incrustarla.
+const qrSide = 256
+
+// Render genera el PDF del certificado con un QR que apunta a VerifyURL.
+func Render(d Data) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Certificado de origen "+d.Number, true)
+	pdf.SetCreator("Batea", true)
+	pdf.SetCreationDate(d.IssuedAt)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AddPage()
+
+	// Las fuentes estándar usan cp1252; sin traducir, las tildes salen corruptas
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr("CERTIFICADO DE ORIGEN DE MINERAL"), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr("No. "+d.Number+"  ·  Emitido el "+d.IssuedAt.Format("2006-01-02 15:04 MST")), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	section := func(title string) {
+		pdf.Ln(3)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(230, 230, 230)
+		pdf.CellFormat(0, 7, tr(title), "", 1, "L", true, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+	}
+	row := func(label, value string) {
+		if value == "" {
+			return
+		}
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(55, 6, tr(label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 6, tr(value), "", "L", false)
+	}
+
+	section("Transacción")
+	row("Venta", d.SaleID)
+	row("Fecha de la venta", d.SaleDate.Format("2006-01-02 15:04 MST"))
+
+	section("Minero")
+	row("Nombre", d.MinerName)
+	row("Tipo de minero", d.MinerType)
+
+	section("Origen del mineral")
+	row("Tipo de sitio", d.SiteKind)
+	row("Nombre del sitio", d.SiteName)
+	row("Título minero", d.TitleNumber)
+	row("Municipio", d.Municipality+", "+d.Department)
+	row("Coordenadas", fmt.Sprintf("%.6f, %.6f", d.Latitude, d.Longitude))
+	row("Mineral", d.Mineral)
+
+	section("Cantidad")
+	row("Peso bruto", fmt.Sprintf("%.3f g", d.WeightGrams))
+	row("Ley", fmt.Sprintf("%.4f", d.Purity))
+	row("Oro fino", fmt.Sprintf("%.3f g", d.FineGoldGrams))
+
+	section("Comprador")
+	row("Razón social", d.BuyerName)
+	row("NIT", d.BuyerNIT)
+	row("RUCOM", d.BuyerRUCOM)
+	row("Punto de compra", d.PointOfSale)
+
+	// Código QR con el enlace de verificación pública
+	code, err := qr.Encode(d.VerifyURL, qr.M, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar el código QR: %w", err)
+	}
+	code, err = barcode.Scale(code, qrSide, qrSide)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar el código QR: %w", err)
+	}
+	// gofpdf no admite PNG de 16 bits, que es lo que produce el escalado
+	gray := image.NewGray(code.Bounds())
+	draw.Draw(gray, gray.Bounds(), code, code.Bounds().Min, draw.Src)
+	var img bytes.Buffer
+	if err := png.Encode(&img, gray); err != nil {
+		return nil, fmt.Errorf("no se pudo generar el código QR: %w", err)
+	}
+
+	pdf.Ln(6)
+	y := pdf.GetY()
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, &img)
+	pdf.ImageOptions("qr", 20, y, 40, 40, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, d.VerifyURL)
+	pdf.SetXY(65, y+4)
+	pdf.MultiCell(0, 5, tr("Escanee el código o visite la dirección siguiente para verificar la autenticidad "+
+		"de este certificado. La huella SHA-256 del archivo y la firma digital del emisor se "+
+		"publican en esa dirección; cualquier modificación del PDF invalida la firma."), "", "L", false)
+	pdf.SetX(65)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.MultiCell(0, 5, d.VerifyURL, "", "L", false)
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		return nil, fmt.Errorf("no se pudo generar el PDF del certificado: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+// Package certificate genera el certificado de origen en PDF de cada venta y
+// lo firma con la llave Ed25519 del servidor para que cualquier alteración sea detectable.
+package certificate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidSigningKey = errors.New("la llave de firma de certificados no es válida")
+	ErrInvalidPublicKey  = errors.New("la llave pública de certificados no es válida")
+)
+
+// Signer firma la huella SHA-256 de cada certificado emitido. Conserva además
+// las llaves públicas anteriores a una rotación para seguir verificando los
+// certificados que se firmaron con ellas.
+type Signer struct {
+	priv     ed25519.PrivateKey
+	keyID    string
+	previous map[string]ed25519.PublicKey
+}
+
+// ParseSigner carga la llave desde la semilla Ed25519 de 32 bytes codificada en base64.
+func ParseSigner(encoded string) (*Signer, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigningKey, err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: la semilla debe tener %d bytes", ErrInvalidSigningKey, ed25519.SeedSize)
+	}
+	return newSigner(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// GenerateSigner crea una llave efímera. Solo sirve en desarrollo: los
+// certificados firmados con ella no se pueden verificar tras reiniciar el servidor.
+func GenerateSigner() (*Signer, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newSigner(priv), nil
+}
+
+func newSigner(priv ed25519.PrivateKey) *Signer {
+	return &Signer{
+		priv:     priv,
+		keyID:    keyID(priv.Public().(ed25519.PublicKey)),
+		previous: map[string]ed25519.PublicKey{},
+	}
+}
+
+// keyID deriva el identificador de una llave pública: los primeros 8 bytes de su SHA-256.
+func keyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// AddPreviousKey registra una llave pública retirada (base64) para verificar
+// los certificados firmados antes de la rotación.
+func (s *Signer) AddPreviousKey(encoded string) error {
+	pub, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: debe tener %d bytes", ErrInvalidPublicKey, ed25519.PublicKeySize)
+	}
+	s.previous[keyID(pub)] = ed25519.PublicKey(pub)
+	return nil
+}
+
+// KeyID identifica la llave pública con la que se firmó un certificado.
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey devuelve la llave pública para que terceros verifiquen las firmas.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.priv.Public().(ed25519.PublicKey)
+}
+
+// Sign firma la huella SHA-256 del PDF.
+func (s *Signer) Sign(digest []byte) []byte {
+	return ed25519.Sign(s.priv, digest)
+}
+
+// Knows indica si la llave keyID es la vigente o una anterior registrada.
+func (s *Signer) Knows(keyID string) bool {
+	_, ok := s.previous[keyID]
+	return ok || keyID == s.keyID
+}
+
+// Verify comprueba la firma de una huella con la llave keyID, vigente o anterior.
+func (s *Signer) Verify(keyID string, digest, signature []byte) bool {
+	pub := s.PublicKey()
+	if keyID != s.keyID {
+		var ok bool
+		if pub, ok = s.previous[keyID]; !ok {
+			return false
+		}
+	}
+	return ed25519.Verify(pub, digest, signature)
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -21,10 +24,113 @@ type Config struct {
 	//  Google Cloud Storage
 	GCSBucketName           string
 	GoogleCredentialsPath   string
+	GCSEndpoint             string // Servidor GCS local (fake-gcs-server) para desarrollo
+
+	// Almacenamiento de documentos: "local" o "gcs"
+	StorageBackend string
+	PublicBaseURL  string
+	// Llave del HMAC de las URLs firmadas del almacenamiento local, distinta de
+	// JWTSecret para que filtrar una no comprometa la otra (vacía solo con DevMode)
+	FileURLSecret string
+
+	// Barrido de archivos huérfanos (intervalo 0 = deshabilitado)
+	StorageSweepInterval time.Duration
+	StorageSweepGrace    time.Duration
+
+	// Topes de venta de oro para mineros de subsistencia (gramos por mes y por año calendario)
+	SubsistenceMonthlyCapGrams float64
+	SubsistenceAnnualCapGrams  float64
+
+	// CSV con el rectángulo envolvente de cada municipio (vacío solo con DevMode:
+	// entonces solo se valida que el sitio esté en Colombia)
+	MunicipalitiesFile string
+
+	// Archivo con las llaves maestras que cifran los secretos TOTP (se crea si no existe)
+	TOTPKeyFile string
+
+	// Llave del HMAC de los códigos de recuperación TOTP (vacía solo con DevMode).
+	// Cambiarla invalida los códigos ya emitidos
+	TOTPRecoverySecret string
+
+	// Bloqueo del TOTP: tras TOTPMaxFailures códigos errados dentro de TOTPFailureWindow,
+	// el usuario que los envió no puede validar códigos de ese minero durante TOTPLockout
+	TOTPMaxFailures   int
+	TOTPFailureWindow time.Duration
+	TOTPLockout       time.Duration
+
+	// Modo desarrollo: permite sustitutos que no sirven en producción, como la
+	// llave efímera de certificados, el proveedor de pagos falso o la falta de
+	// catálogo de municipios
+	DevMode bool
+
+	// Semilla Ed25519 en base64 para firmar los certificados de origen (vacía solo con DevMode)
+	CertSigningKey string
+	// Llaves públicas Ed25519 (base64, separadas por coma) de firmas anteriores a
+	// la rotación, para seguir verificando los certificados que firmaron
+	CertPreviousPublicKeys []string
+
+	// Pagos a mineros. Sin URL de proveedor se usa el proveedor falso en memoria (solo con DevMode)
+	PayoutBankURL             string
+	PayoutBankAPIKey          string
+	PayoutBankWebhookSecret   string
+	PayoutWalletURL           string
+	PayoutWalletAPIKey        string
+	PayoutWalletWebhookSecret string
+	PayoutMaxAttempts         int
+	PayoutRetryInterval       time.Duration // cada cuánto se envían los pagos nuevos y se reintentan los pendientes
+
+	// Precios de referencia del oro: CSV local y/o servicio HTTP
+	PriceFile             string
+	PriceSourceURL        string
+	PriceSourceAPIKey     string
+	PriceRefreshInterval  time.Duration
+	PriceFlagBelowPercent float64 // Se marca la venta pagada más de este % por debajo de la referencia
+
+	// NIT con que Batea presenta sus reportes regulatorios de compras
+	ReportPlatformNIT string
+
+	// Autenticación (JWT de acceso + refresh tokens)
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Identity Platform / Firebase (vacío = login con ID token deshabilitado)
+	FirebaseProjectID string
+	IdentityJWKSURL   string
+
+	// Envío de SMS: "log" (desarrollo) o "memory" (pruebas)
+	SMSProvider string
+
+	// Teléfono del usuario que recibe el rol admin al iniciar (opcional)
+	BootstrapAdminPhone string
 }
 // LoadConfig carga las variables de entorno desde el archivo .env.
 // LoadConfig carga las variables de entorno desde el archivo .env.
 func LoadConfig() *Config {
+	cfg := Load()
+
+	if cfg.JWTSecret == "" {
+		log.Fatal("JWT_SECRET es obligatorio para firmar los tokens de acceso")
+	}
+
+	// Crear el directorio local solo si se usa almacenamiento local
+	if cfg.UploadDir != "" {
+		if _, err := os.Stat(cfg.UploadDir); os.IsNotExist(err) {
+			log.Printf("Creando directorio local de carga: %s\n", cfg.UploadDir)
+			err = os.MkdirAll(cfg.UploadDir, 0755)
+			if err != nil {
+				log.Fatalf("Error al crear el directorio de carga: %v", err)
+			}
+		}
+	}
+
+	fmt.Println(" Configuración cargada exitosamente.")
+	return cfg
+}
+
+// Load solo lee las variables, sin exigir las que necesita el servidor; la
+// usan los comandos de mantenimiento.
+func Load() *Config {
 	// Carga el archivo .env si existe
 	if err := godotenv.Load(); err != nil {
 		log.Println("Advertencia: No se encontró archivo .env. Usando variables de entorno del sistema.")
@@ -42,20 +148,60 @@ func LoadConfig() *Config {
 		// Variables para Google Cloud Storage
 		GCSBucketName:         getEnv("GCS_BUCKET_NAME", ""),
 		GoogleCredentialsPath: getEnv("GOOGLE_APPLICATION_CREDENTIALS", ""),
+		GCSEndpoint:           getEnv("GCS_ENDPOINT", ""),
+
+		// Autenticación
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		// Identity Platform
+		FirebaseProjectID: getEnv("FIREBASE_PROJECT_ID", ""),
+		IdentityJWKSURL:   getEnv("IDENTITY_JWKS_URL", "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"),
+
+		// SMS
+		SMSProvider: getEnv("SMS_PROVIDER", "log"),
+
+		// Roles
+		BootstrapAdminPhone: getEnv("BOOTSTRAP_ADMIN_PHONE", ""),
 	}
 
-	// Crear el directorio local solo si se usa almacenamiento local
-	if cfg.UploadDir != "" {
-		if _, err := os.Stat(cfg.UploadDir); os.IsNotExist(err) {
-			log.Printf("Creando directorio local de carga: %s\n", cfg.UploadDir)
-			err = os.MkdirAll(cfg.UploadDir, 0755)
-			if err != nil {
-				log.Fatalf("Error al crear el directorio de carga: %v", err)
-			}
-		}
+	// Almacenamiento: GCS si hay bucket configurado, local en otro caso
+	defaultBackend := "local"
+	if cfg.GCSBucketName != "" {
+		defaultBackend = "gcs"
 	}
+	cfg.StorageBackend = getEnv("STORAGE_BACKEND", defaultBackend)
+	cfg.PublicBaseURL = getEnv("PUBLIC_BASE_URL", "http://localhost:"+cfg.Port)
+	cfg.FileURLSecret = getEnv("FILE_URL_SECRET", "")
+	cfg.StorageSweepInterval = getEnvDuration("STORAGE_SWEEP_INTERVAL", time.Hour)
+	cfg.StorageSweepGrace = getEnvDuration("STORAGE_SWEEP_GRACE", 24*time.Hour)
+	cfg.SubsistenceMonthlyCapGrams = getEnvFloat("SUBSISTENCE_MONTHLY_CAP_GRAMS", 35)
+	cfg.SubsistenceAnnualCapGrams = getEnvFloat("SUBSISTENCE_ANNUAL_CAP_GRAMS", 420)
+	cfg.MunicipalitiesFile = getEnv("MUNICIPALITIES_FILE", "")
+	cfg.DevMode = getEnvBool("DEV_MODE", false)
+	cfg.CertSigningKey = getEnv("CERT_SIGNING_KEY", "")
+	cfg.CertPreviousPublicKeys = getEnvList("CERT_PREVIOUS_PUBLIC_KEYS")
+	cfg.TOTPKeyFile = getEnv("TOTP_KEY_FILE", "./secrets/totp-keys.json")
+	cfg.TOTPRecoverySecret = getEnv("TOTP_RECOVERY_SECRET", "")
+	cfg.TOTPMaxFailures = getEnvInt("TOTP_MAX_FAILURES", 5)
+	cfg.TOTPFailureWindow = getEnvDuration("TOTP_FAILURE_WINDOW", 15*time.Minute)
+	cfg.TOTPLockout = getEnvDuration("TOTP_LOCKOUT", 15*time.Minute)
+	cfg.PayoutBankURL = getEnv("PAYOUT_BANK_URL", "")
+	cfg.PayoutBankAPIKey = getEnv("PAYOUT_BANK_API_KEY", "")
+	cfg.PayoutBankWebhookSecret = getEnv("PAYOUT_BANK_WEBHOOK_SECRET", "")
+	cfg.PayoutWalletURL = getEnv("PAYOUT_WALLET_URL", "")
+	cfg.PayoutWalletAPIKey = getEnv("PAYOUT_WALLET_API_KEY", "")
+	cfg.PayoutWalletWebhookSecret = getEnv("PAYOUT_WALLET_WEBHOOK_SECRET", "")
+	cfg.PayoutMaxAttempts = getEnvInt("PAYOUT_MAX_ATTEMPTS", 5)
+	cfg.PayoutRetryInterval = getEnvDuration("PAYOUT_RETRY_INTERVAL", time.Minute)
+	cfg.PriceFile = getEnv("PRICE_FILE", "")
+	cfg.PriceSourceURL = getEnv("PRICE_SOURCE_URL", "")
+	cfg.PriceSourceAPIKey = getEnv("PRICE_SOURCE_API_KEY", "")
+	cfg.PriceRefreshInterval = getEnvDuration("PRICE_REFRESH_INTERVAL", 6*time.Hour)
+	cfg.PriceFlagBelowPercent = getEnvFloat("PRICE_FLAG_BELOW_PERCENT", 10)
+	cfg.ReportPlatformNIT = getEnv("REPORT_PLATFORM_NIT", "")
 
-	fmt.Println(" Configuración cargada exitosamente.")
 	return cfg
 }
 
@@ -65,3 +211,70 @@ func getEnv(key string, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration lee una duración (por ejemplo "15m" o "720h") o usa el valor por defecto.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Advertencia: valor inválido para %s (%q). Usando %s.", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getEnvInt lee un número entero o usa el valor por defecto.
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Advertencia: valor inválido para %s (%q). Usando %d.", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvFloat lee un número decimal o usa el valor por defecto.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Advertencia: valor inválido para %s (%q). Usando %v.", key, value, defaultValue)
+		return defaultValue
+	}
+	return f
+}
+
+// getEnvBool lee true/false (también 1/0) o usa el valor por defecto.
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Advertencia: valor inválido para %s (%q). Usando %v.", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// getEnvList lee una lista separada por comas, sin elementos vacíos.
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type AuthController struct {
+	authService service.AuthService
+}
+
+func NewAuthController(a service.AuthService) *AuthController {
+	return &AuthController{authService: a}
+}
+
+// POST /api/v1/auth/login
+func (ctrl *AuthController) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := ctrl.authService.Login(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al iniciar sesión: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo iniciar sesión"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// POST /api/v1/auth/token
+// Inicia sesión con un ID token de Identity Platform (Firebase)
+func (ctrl *AuthController) LoginWithIDToken(c *gin.Context) {
+	var req models.IDTokenLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := ctrl.authService.LoginWithIDToken(req.IDToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "ID token inválido o usuario no registrado"})
+		case errors.Is(err, service.ErrIdentityDisabled):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Error al iniciar sesión con ID token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo iniciar sesión"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// POST /api/v1/auth/refresh
+func (ctrl *AuthController) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := ctrl.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al rotar refresh token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo renovar la sesión"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// POST /api/v1/auth/logout
+func (ctrl *AuthController) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.authService.Logout(req.RefreshToken); err != nil {
+		log.Printf("Error al cerrar sesión: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo cerrar la sesión"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
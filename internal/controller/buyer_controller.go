@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type BuyerController struct {
+	buyerService service.BuyerService
+}
+
+func NewBuyerController(s service.BuyerService) *BuyerController {
+	return &BuyerController{buyerService: s}
+}
+
+// RegisterBuyer registra al usuario como comercializador (queda pendiente de aprobación).
+// Multipart con legal_name, nit, rucom_number y los PDF rucom_certificate, rut y chamber_of_commerce.
+// POST /api/v1/buyers
+func (c *BuyerController) RegisterBuyer(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req models.CreateBuyerRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	files := make(map[string]*multipart.FileHeader)
+	for _, kind := range models.BuyerDocumentKinds {
+		files[string(kind)], _ = ctx.FormFile(string(kind))
+	}
+
+	buyer, err := c.buyerService.RegisterBuyer(userID, &req, files)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, buyer)
+}
+
+// GET /api/v1/buyers/me
+func (c *BuyerController) GetMyBuyer(ctx *gin.Context) {
+	userID, _ := middleware.CurrentUserID(ctx)
+	buyer, err := c.buyerService.GetBuyerByUserID(userID)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, buyer)
+}
+
+// GET /api/v1/buyers/:id
+func (c *BuyerController) GetBuyer(ctx *gin.Context) {
+	buyer, ok := c.loadBuyer(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, buyer)
+}
+
+// GET /api/v1/buyers/:id/documents/:kind
+func (c *BuyerController) DownloadDocument(ctx *gin.Context) {
+	buyer, ok := c.loadBuyer(ctx)
+	if !ok {
+		return
+	}
+
+	kind := models.DocumentKind(ctx.Param("kind"))
+	content, contentType, err := c.buyerService.OpenDocument(buyer, kind)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, content, nil)
+}
+
+// POST /api/v1/buyers/:id/points
+func (c *BuyerController) AddPurchasePoint(ctx *gin.Context) {
+	buyerID, userID, ok := buyerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePurchasePointRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	point, err := c.buyerService.AddPurchasePoint(buyerID, userID, &req)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, point)
+}
+
+// DELETE /api/v1/buyers/:id/points/:pointId
+func (c *BuyerController) DeactivatePurchasePoint(ctx *gin.Context) {
+	buyerID, userID, ok := buyerParams(ctx)
+	if !ok {
+		return
+	}
+	pointID, err := uuid.Parse(ctx.Param("pointId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de punto de compra inválido"})
+		return
+	}
+
+	if err := c.buyerService.DeactivatePurchasePoint(buyerID, userID, pointID); err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListQueue lista los comercializadores en un estado (por defecto pending)
+// GET /api/v1/reviews/buyers?status=pending&page=1&limit=10
+func (c *BuyerController) ListQueue(ctx *gin.Context) {
+	status := models.BuyerStatus(ctx.DefaultQuery("status", string(models.BuyerPending)))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	result, err := c.buyerService.ListBuyers(status, page, limit)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// POST /api/v1/reviews/buyers/:id/approve
+func (c *BuyerController) Approve(ctx *gin.Context) {
+	buyerID, adminID, ok := buyerParams(ctx)
+	if !ok {
+		return
+	}
+
+	buyer, err := c.buyerService.ApproveBuyer(buyerID, adminID)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, buyer)
+}
+
+// POST /api/v1/reviews/buyers/:id/reject
+func (c *BuyerController) Reject(ctx *gin.Context) {
+	buyerID, adminID, ok := buyerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.RejectBuyerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	buyer, err := c.buyerService.RejectBuyer(buyerID, adminID, req.Reason)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, buyer)
+}
+
+// ListPointQueue lista los puntos de compra en un estado (por defecto pending)
+// GET /api/v1/reviews/purchase-points?status=pending&page=1&limit=10
+func (c *BuyerController) ListPointQueue(ctx *gin.Context) {
+	status := models.PurchasePointStatus(ctx.DefaultQuery("status", string(models.PointPending)))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	result, err := c.buyerService.ListPurchasePoints(status, page, limit)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// POST /api/v1/reviews/purchase-points/:id/approve
+func (c *BuyerController) ApprovePoint(ctx *gin.Context) {
+	pointID, adminID, ok := pointParams(ctx)
+	if !ok {
+		return
+	}
+
+	point, err := c.buyerService.ApprovePurchasePoint(pointID, adminID)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, point)
+}
+
+// POST /api/v1/reviews/purchase-points/:id/reject
+func (c *BuyerController) RejectPoint(ctx *gin.Context) {
+	pointID, adminID, ok := pointParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.RejectBuyerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	point, err := c.buyerService.RejectPurchasePoint(pointID, adminID, req.Reason)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, point)
+}
+
+// loadBuyer carga el comercializador del :id; solo lo ve su dueño o quien tenga buyers:review.
+func (c *BuyerController) loadBuyer(ctx *gin.Context) (*models.Buyer, bool) {
+	buyerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de comercializador inválido"})
+		return nil, false
+	}
+
+	buyer, err := c.buyerService.GetBuyer(buyerID)
+	if err != nil {
+		respondBuyerError(ctx, err)
+		return nil, false
+	}
+
+	userID, _ := middleware.CurrentUserID(ctx)
+	if buyer.UserID != userID && !middleware.HasPermission(ctx, models.PermBuyersReview) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver este comercializador"})
+		return nil, false
+	}
+	return buyer, true
+}
+
+// buyerParams devuelve el :id de la ruta y el usuario autenticado.
+func buyerParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	buyerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de comercializador inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return buyerID, userID, true
+}
+
+// pointParams devuelve el :id del punto de compra y el usuario autenticado.
+func pointParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	pointID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de punto de compra inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return pointID, userID, true
+}
+
+func respondBuyerError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrBuyerNotFound), errors.Is(err, repository.ErrPurchasePointNotFound),
+		errors.Is(err, service.ErrDocumentNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotBuyerOwner):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrBuyerExists), errors.Is(err, service.ErrBuyerTaken), errors.Is(err, service.ErrBuyerNotPending),
+		errors.Is(err, service.ErrPointNotPending):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en comercializadores: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo procesar la solicitud", "details": err.Error()})
+	}
+}
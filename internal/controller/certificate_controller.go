@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type CertificateController struct {
+	certService service.CertificateService
+	saleService service.SaleService
+}
+
+func NewCertificateController(c service.CertificateService, s service.SaleService) *CertificateController {
+	return &CertificateController{certService: c, saleService: s}
+}
+
+// DownloadSaleCertificate descarga el certificado de origen de una venta a las
+// mismas personas que pueden ver la venta.
+// GET /api/v1/sales/:id/certificate
+func (c *CertificateController) DownloadSaleCertificate(ctx *gin.Context) {
+	saleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de venta inválido"})
+		return
+	}
+
+	sale, err := c.saleService.GetSale(saleID)
+	if err != nil {
+		respondCertificateError(ctx, err)
+		return
+	}
+	if !canViewSale(ctx, sale) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver esta venta"})
+		return
+	}
+
+	content, cert, err := c.certService.Open(sale)
+	if err != nil {
+		respondCertificateError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.DataFromReader(http.StatusOK, -1, "application/pdf", content, map[string]string{
+		"Content-Disposition": `attachment; filename="certificado-` + cert.Number + `.pdf"`,
+	})
+}
+
+// Verify es el destino público del QR: informa si el certificado guardado
+// sigue intacto y muestra sus datos principales.
+// GET /api/v1/certificates/:id
+func (c *CertificateController) Verify(ctx *gin.Context) {
+	certID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de certificado inválido"})
+		return
+	}
+
+	result, err := c.certService.Verify(certID)
+	if err != nil {
+		respondCertificateError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// VerifyCopy compara una copia del PDF (multipart, campo "file") con la huella
+// y la firma registradas al emitir el certificado.
+// POST /api/v1/certificates/:id/verify
+func (c *CertificateController) VerifyCopy(ctx *gin.Context) {
+	certID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de certificado inválido"})
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Debe adjuntar el certificado en el campo 'file'"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer el archivo"})
+		return
+	}
+	defer file.Close()
+
+	result, err := c.certService.VerifyCopy(certID, file)
+	if err != nil {
+		respondCertificateError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// PublicKey publica la llave Ed25519 con la que se firman los certificados.
+// GET /api/v1/certificates/public-key
+func (c *CertificateController) PublicKey(ctx *gin.Context) {
+	keyID, key := c.certService.PublicKey()
+	ctx.JSON(http.StatusOK, gin.H{
+		"algorithm":  "Ed25519",
+		"key_id":     keyID,
+		"public_key": base64.StdEncoding.EncodeToString(key),
+		"signs":      "SHA-256 del archivo PDF",
+	})
+}
+
+func respondCertificateError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCertificateNotFound), errors.Is(err, repository.ErrSaleNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCertificateTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSaleWithoutParties):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error con el certificado de origen: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo procesar el certificado de origen"})
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanchezta/batea-backend/internal/storage"
+)
+
+// FileController sirve los archivos del almacenamiento local a través de URLs
+// firmadas (el equivalente local de las URLs firmadas de GCS).
+type FileController struct {
+	store *storage.LocalStorage
+}
+
+func NewFileController(store *storage.LocalStorage) *FileController {
+	return &FileController{store: store}
+}
+
+// GET /api/v1/files/*key?expires=...&signature=...
+func (c *FileController) Serve(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	if err := c.store.VerifySignature(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	content, err := c.store.Get(ctx.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al leer archivo %s: %v", key, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo obtener el archivo"})
+		return
+	}
+	defer content.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, content, nil)
+}
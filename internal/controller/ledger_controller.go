@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type LedgerController struct {
+	ledgerService service.LedgerService
+}
+
+func NewLedgerController(l service.LedgerService) *LedgerController {
+	return &LedgerController{ledgerService: l}
+}
+
+// History lista en orden las entradas del libro mayor de una venta, un minero
+// o un comercializador, con sus hashes para que un auditor las verifique.
+// GET /api/v1/ledger/:aggregate/:id  (aggregate: sale, miner, buyer o purchase_point)
+func (c *LedgerController) History(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	entries, err := c.ledgerService.History(ctx.Param("aggregate"), id)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownAggregate) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al consultar el libro mayor: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo consultar el libro mayor"})
+		return
+	}
+	if entries == nil {
+		entries = []models.LedgerEntry{}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"entries": entries})
+}
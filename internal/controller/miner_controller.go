@@ -1,14 +1,18 @@
 package controller
 
 import (
+	"errors"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
 	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
 	"github.com/sanchezta/batea-backend/internal/service"
 )
 
@@ -35,11 +39,10 @@ func (c *MinerController) RegisterMiner(ctx *gin.Context) {
 		return
 	}
 
-	// Obtener user_id del formulario
-	userIDStr := ctx.PostForm("user_id")
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El user_id proporcionado no es válido"})
+	// El minero siempre se vincula al usuario autenticado
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
 		return
 	}
 
@@ -65,7 +68,15 @@ func (c *MinerController) RegisterMiner(ctx *gin.Context) {
 	files["technical_tool"], _ = ctx.FormFile("technical_tool")
 
 	// Llamar al servicio (pasando userID)
-	miner, code, qrURL, err := c.minerService.CreateMiner(userID, &req, files)
+	miner, enrollment, err := c.minerService.CreateMiner(userID, &req, files)
+	if errors.Is(err, service.ErrProductionNotDeclared) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrMinerDuplicate) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Printf("Error al crear minero: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -76,29 +87,205 @@ func (c *MinerController) RegisterMiner(ctx *gin.Context) {
 	}
 
 	// Respuesta
+	// El TOTP queda pendiente hasta POST /miners/:id/totp/confirm
 	ctx.JSON(http.StatusCreated, gin.H{
-		"message":     "Minero registrado exitosamente.",
-		"miner":       miner,
-		"totp_code":   code,
-		"qr_code_url": qrURL,
+		"message": "Minero registrado exitosamente. Escanee el código QR y confirme el primer código de su aplicación.",
+		"miner":   models.NewMinerResponse(miner, true),
+		"totp":    enrollment,
 	})
 }
 
-// GetMinerByID obtiene un minero por su ID
+// GetMinerByID obtiene un minero por su ID.
+// Solo el dueño o quien tenga miners:read_any puede verlo; los documentos
+// solo se describen al dueño o a quien tenga documents:read_any.
 // GET /miners/:id
 func (c *MinerController) GetMinerByID(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	_, err := strconv.ParseUint(idStr, 10, 64)
+	miner, ok := c.loadMiner(ctx)
+	if !ok {
+		return
+	}
+	if !isMinerOwner(ctx, miner) && !middleware.HasPermission(ctx, models.PermMinersReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver este minero"})
+		return
+	}
+
+	includeDocs := isMinerOwner(ctx, miner) || middleware.HasPermission(ctx, models.PermDocumentsReadAny)
+	ctx.JSON(http.StatusOK, models.NewMinerResponse(miner, includeDocs))
+}
+
+// DownloadDocument descarga un documento del minero
+// GET /miners/:id/documents/:kind
+func (c *MinerController) DownloadDocument(ctx *gin.Context) {
+	miner, ok := c.loadMiner(ctx)
+	if !ok {
+		return
+	}
+	if !isMinerOwner(ctx, miner) && !middleware.HasPermission(ctx, models.PermDocumentsReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver los documentos de este minero"})
+		return
+	}
+
+	kind := models.DocumentKind(ctx.Param("kind"))
+	content, contentType, err := c.minerService.OpenDocument(miner, kind)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al abrir documento %s del minero %s: %v", kind, miner.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo obtener el documento"})
+		return
+	}
+	defer content.Close()
+
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, content, nil)
+}
+
+// GetDocumentURL devuelve una URL firmada de corta duración para descargar el documento
+// GET /miners/:id/documents/:kind/url
+func (c *MinerController) GetDocumentURL(ctx *gin.Context) {
+	miner, ok := c.loadMiner(ctx)
+	if !ok {
+		return
+	}
+	if !isMinerOwner(ctx, miner) && !middleware.HasPermission(ctx, models.PermDocumentsReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver los documentos de este minero"})
+		return
+	}
+
+	url, err := c.minerService.DocumentURL(miner, models.DocumentKind(ctx.Param("kind")))
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al firmar URL de documento del minero %s: %v", miner.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo generar la URL del documento"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// UpdateMiner actualiza los datos de perfil del minero
+// PATCH /miners/:id
+func (c *MinerController) UpdateMiner(ctx *gin.Context) {
+	minerID, userID, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMinerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos de entrada inválidos", "details": err.Error()})
+		return
+	}
+
+	miner, err := c.minerService.UpdateProfile(minerID, userID, &req)
+	if err != nil {
+		respondMinerUpdateError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewMinerResponse(miner, true))
+}
+
+// ReplaceDocument reemplaza un documento del minero (multipart, campo "file")
+// PUT /miners/:id/documents/:kind
+func (c *MinerController) ReplaceDocument(ctx *gin.Context) {
+	minerID, userID, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Debe adjuntar el documento en el campo 'file'"})
 		return
 	}
 
+	kind := models.DocumentKind(ctx.Param("kind"))
+	miner, err := c.minerService.ReplaceDocument(minerID, userID, kind, file)
+	if err != nil {
+		respondMinerUpdateError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Funcionalidad pendiente: obtener minero por ID " + idStr,
+		"message": "Documento actualizado. El registro vuelve a revisión.",
+		"miner":   models.NewMinerResponse(miner, true),
 	})
 }
 
+func ownerParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return minerID, userID, true
+}
+
+func respondMinerUpdateError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrMinerNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotMinerOwner):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrEmailTaken), errors.Is(err, service.ErrMinerLocked), errors.Is(err, service.ErrInvalidTransition):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidDocument), errors.Is(err, service.ErrInvalidFile),
+		errors.Is(err, service.ErrProductionNotApplicable):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error al actualizar minero: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo actualizar el minero"})
+	}
+}
+
+// loadMiner parsea el :id de la ruta y carga el minero, respondiendo 400/404 si falla.
+func (c *MinerController) loadMiner(ctx *gin.Context) (*models.Miner, bool) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return nil, false
+	}
+
+	miner, err := c.minerService.GetMinerByID(minerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMinerNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		log.Printf("Error al buscar minero %s: %v", minerID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo obtener el minero"})
+		return nil, false
+	}
+	return miner, true
+}
+
+// isMinerOwner indica si el usuario autenticado es el dueño del minero.
+func isMinerOwner(ctx *gin.Context, miner *models.Miner) bool {
+	userID, ok := middleware.CurrentUserID(ctx)
+	return ok && miner.UserID == userID
+}
+
+// respondTOTPLocked responde 429 con Retry-After si err es un bloqueo del TOTP
+// por códigos errados; devuelve false si no lo es.
+func respondTOTPLocked(ctx *gin.Context, err error) bool {
+	var locked *service.TOTPLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	retryAfter := int(math.Ceil(locked.RetryAfter().Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after_seconds": retryAfter})
+	return true
+}
+
 // GetAllMiners lista todos los mineros con paginación
 // GET /api/v1/miners?page=1&limit=10
 func (c *MinerController) GetAllMiners(ctx *gin.Context) {
@@ -116,21 +303,79 @@ func (c *MinerController) GetAllMiners(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
-// Obtener el código TOTP actual para un minero (para Flutter)
-// GET /miners/:id/totp
-func (c *MinerController) GetCurrentTOTP(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	minerID, err := uuid.Parse(idStr)
+// ConfirmTOTP activa el TOTP pendiente con el primer código de la aplicación.
+// Responde los códigos de recuperación, que no se vuelven a mostrar.
+// POST /miners/:id/totp/confirm
+func (c *MinerController) ConfirmTOTP(ctx *gin.Context) {
+	minerID, userID, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos de entrada inválidos", "details": err.Error()})
+		return
+	}
+
+	codes, err := c.minerService.ConfirmTOTP(minerID, userID, req.Code)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondTOTPEnrollmentError(ctx, err)
 		return
 	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "TOTP activado. Guarde los códigos de recuperación: no se volverán a mostrar.",
+		"recovery_codes": codes,
+	})
+}
 
-	code, err := c.minerService.GenerateTOTP(minerID)
+// ReenrollTOTP emite un secreto TOTP nuevo (por ejemplo, tras perder el
+// teléfono) si el minero confirma su identidad con el código SMS de
+// /auth/phone/challenge o con un código de recuperación. El secreto anterior
+// deja de servir de inmediato.
+// POST /miners/:id/totp/reenroll
+func (c *MinerController) ReenrollTOTP(ctx *gin.Context) {
+	minerID, userID, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ReenrollTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos de entrada inválidos", "details": err.Error()})
+		return
+	}
+
+	enrollment, err := c.minerService.ReenrollTOTP(minerID, userID, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondTOTPEnrollmentError(ctx, err)
 		return
 	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Escanee el código QR y confirme el primer código de su aplicación.",
+		"totp":    enrollment,
+	})
+}
 
-	ctx.JSON(http.StatusOK, gin.H{"totp_code": code})
+func respondTOTPEnrollmentError(ctx *gin.Context, err error) {
+	if respondTOTPLocked(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, repository.ErrMinerNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotMinerOwner):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTOTP), errors.Is(err, service.ErrTOTPReused),
+		errors.Is(err, service.ErrRecoveryCodeInvalid), errors.Is(err, service.ErrIdentityNotVerified),
+		errors.Is(err, service.ErrPhoneCodeInvalid), errors.Is(err, service.ErrPhoneCodeExpired):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPhoneCodeMaxAttempts):
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTOTPNoPendingEnrollment), errors.Is(err, repository.ErrTOTPSecretChanged):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en el registro de TOTP: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo completar el registro del TOTP"})
+	}
 }
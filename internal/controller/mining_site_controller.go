@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanchezta/batea-backend/internal/geo"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type MiningSiteController struct {
+	siteService  service.MiningSiteService
+	minerService service.MinerService
+}
+
+func NewMiningSiteController(s service.MiningSiteService, m service.MinerService) *MiningSiteController {
+	return &MiningSiteController{siteService: s, minerService: m}
+}
+
+// CreateSite registra un sitio de extracción del minero (solo el dueño).
+// POST /api/v1/miners/:id/sites
+func (c *MiningSiteController) CreateSite(ctx *gin.Context) {
+	minerID, userID, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.CreateMiningSiteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	site, err := c.siteService.CreateSite(minerID, userID, &req)
+	if err != nil {
+		respondSiteError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, site)
+}
+
+// ListSites lista los sitios del minero. Los comercializadores también los ven
+// para elegir el origen al registrar una compra.
+// GET /api/v1/miners/:id/sites
+func (c *MiningSiteController) ListSites(ctx *gin.Context) {
+	minerID, _, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+	miner, err := c.minerService.GetMinerByID(minerID)
+	if err != nil {
+		respondSiteError(ctx, err)
+		return
+	}
+	if !isMinerOwner(ctx, miner) &&
+		!middleware.HasPermission(ctx, models.PermMinersReadAny) &&
+		!middleware.HasPermission(ctx, models.PermSalesCreate) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver los sitios de este minero"})
+		return
+	}
+
+	sites, err := c.siteService.ListSites(miner.ID)
+	if err != nil {
+		respondSiteError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sites)
+}
+
+func respondSiteError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrMinerNotFound), errors.Is(err, repository.ErrMiningSiteNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotMinerOwner):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTitleNumberTaken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTitleNumberRequired), errors.Is(err, service.ErrSiteLocationRequired),
+		errors.Is(err, geo.ErrOutsideColombia), errors.Is(err, geo.ErrOutsideMunicipality),
+		errors.Is(err, geo.ErrUnknownMunicipality):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en sitios mineros: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo procesar el sitio minero"})
+	}
+}
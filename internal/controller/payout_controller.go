@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/payout"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+// maxWebhookBody limita el cuerpo que se acepta en los webhooks de pagos.
+const maxWebhookBody = 1 << 20
+
+type PayoutController struct {
+	payoutService service.PayoutService
+	minerService  service.MinerService
+}
+
+func NewPayoutController(p service.PayoutService, m service.MinerService) *PayoutController {
+	return &PayoutController{payoutService: p, minerService: m}
+}
+
+// RequestPayout envía el saldo disponible del minero a su cuenta. Solo el dueño
+// puede solicitarlo y lo confirma con su código TOTP.
+// POST /api/v1/miners/:id/payouts
+func (c *PayoutController) RequestPayout(ctx *gin.Context) {
+	minerID, userID, ok := ownerParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePayoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos de entrada inválidos", "details": err.Error()})
+		return
+	}
+
+	p, err := c.payoutService.RequestPayout(minerID, userID, &req)
+	if err != nil {
+		respondPayoutError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, p)
+}
+
+// GET /api/v1/miners/:id/payouts?page=1&limit=10
+func (c *PayoutController) ListMinerPayouts(ctx *gin.Context) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return
+	}
+	if !c.canViewPayouts(ctx, minerID) {
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	result, err := c.payoutService.ListMinerPayouts(minerID, page, limit)
+	if err != nil {
+		respondPayoutError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GET /api/v1/payouts/:id
+func (c *PayoutController) GetPayout(ctx *gin.Context) {
+	payoutID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de pago inválido"})
+		return
+	}
+
+	p, err := c.payoutService.GetPayout(payoutID)
+	if err != nil {
+		respondPayoutError(ctx, err)
+		return
+	}
+	if !c.canViewPayouts(ctx, p.MinerID) {
+		return
+	}
+	ctx.JSON(http.StatusOK, p)
+}
+
+// Webhook recibe las notificaciones del proveedor. Cada adaptador verifica la
+// firma; un 2xx le indica al proveedor que no reenvíe la notificación.
+// POST /api/v1/payouts/webhooks/:provider
+func (c *PayoutController) Webhook(ctx *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxWebhookBody))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer la notificación"})
+		return
+	}
+
+	if err := c.payoutService.HandleWebhook(ctx.Param("provider"), ctx.Request.Header, body); err != nil {
+		respondPayoutError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// canViewPayouts permite ver los pagos al dueño del minero o a quien tenga wallets:read_any.
+func (c *PayoutController) canViewPayouts(ctx *gin.Context, minerID uuid.UUID) bool {
+	miner, err := c.minerService.GetMinerByID(minerID)
+	if err != nil {
+		respondPayoutError(ctx, err)
+		return false
+	}
+	if !isMinerOwner(ctx, miner) && !middleware.HasPermission(ctx, models.PermWalletsReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver los pagos de este minero"})
+		return false
+	}
+	return true
+}
+
+func respondPayoutError(ctx *gin.Context, err error) {
+	if respondTOTPLocked(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, repository.ErrMinerNotFound), errors.Is(err, repository.ErrPayoutNotFound),
+		errors.Is(err, service.ErrUnknownPayoutProvider):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, payout.ErrInvalidSignature):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotMinerOwner), errors.Is(err, service.ErrInvalidTOTP),
+		errors.Is(err, service.ErrTOTPReused):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, service.ErrMinerNotApproved),
+		errors.Is(err, service.ErrTOTPNotConfigured), errors.Is(err, repository.ErrPayoutStateChanged):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, payout.ErrInvalidDestination), errors.Is(err, payout.ErrUnknownPayoutStatus):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, payout.ErrUnsupportedMethod):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en pagos: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo procesar el pago"})
+	}
+}
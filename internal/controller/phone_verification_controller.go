@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type PhoneVerificationController struct {
+	phoneService service.PhoneVerificationService
+}
+
+func NewPhoneVerificationController(p service.PhoneVerificationService) *PhoneVerificationController {
+	return &PhoneVerificationController{phoneService: p}
+}
+
+// POST /api/v1/auth/phone/challenge
+func (ctrl *PhoneVerificationController) Challenge(c *gin.Context) {
+	var req models.PhoneChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.phoneService.RequestChallenge(req.PhoneNumber); err != nil {
+		if errors.Is(err, service.ErrPhoneCodeCooldown) || errors.Is(err, service.ErrPhoneCodeLimit) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al enviar código de verificación: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo enviar el código de verificación"})
+		return
+	}
+
+	// Respuesta genérica: no revela si el número está registrado
+	c.JSON(http.StatusAccepted, gin.H{"message": "Si el número está registrado, recibirá un código por SMS."})
+}
+
+// POST /api/v1/auth/phone/verify
+func (ctrl *PhoneVerificationController) Verify(c *gin.Context) {
+	var req models.PhoneVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.phoneService.Verify(req.PhoneNumber, req.Code); err != nil {
+		switch {
+		case errors.Is(err, service.ErrPhoneCodeInvalid), errors.Is(err, service.ErrPhoneCodeExpired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrPhoneCodeMaxAttempts):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Error al verificar código: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo verificar el código"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Teléfono verificado exitosamente.", "is_verified": true})
+}
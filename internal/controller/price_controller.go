@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanchezta/batea-backend/internal/pricing"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+// defaultPriceDays es el rango que se devuelve si no se indican fechas.
+const defaultPriceDays = 30
+
+type PriceController struct {
+	priceService service.PriceService
+}
+
+func NewPriceController(s service.PriceService) *PriceController {
+	return &PriceController{priceService: s}
+}
+
+// ListPrices devuelve el histórico de precios de referencia. Sin fechas, los
+// últimos 30 días; kind filtra por serie (banrep o international_spot).
+// GET /api/v1/prices?from=2026-10-01&to=2026-10-16&kind=banrep
+func (c *PriceController) ListPrices(ctx *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -defaultPriceDays)
+
+	var err error
+	if v := ctx.Query("from"); v != "" {
+		if from, err = pricing.ParseDay(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro 'from' inválido (use AAAA-MM-DD)"})
+			return
+		}
+	}
+	if v := ctx.Query("to"); v != "" {
+		if to, err = pricing.ParseDay(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro 'to' inválido (use AAAA-MM-DD)"})
+			return
+		}
+	}
+
+	kind := pricing.Kind(ctx.Query("kind"))
+	if kind != "" && kind != pricing.KindBanRep && kind != pricing.KindInternationalSpot {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro 'kind' inválido (use banrep o international_spot)"})
+		return
+	}
+
+	prices, err := c.priceService.ListPrices(from, to, kind)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPriceRange) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error al listar precios de referencia: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudieron obtener los precios"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"prices": prices,
+	})
+}
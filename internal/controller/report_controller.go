@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/reporting"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+// ReportController expone el reporte regulatorio de compras. Quien tiene
+// reports:manage reporta en nombre de Batea; un comercializador, solo sus compras.
+type ReportController struct {
+	reportService service.ReportService
+	buyerService  service.BuyerService
+}
+
+func NewReportController(r service.ReportService, b service.BuyerService) *ReportController {
+	return &ReportController{reportService: r, buyerService: b}
+}
+
+// Preview descarga el archivo con las compras pendientes de reportar, sin registrarlo.
+// GET /api/v1/reports/regulatory/preview?from=2026-09-01&to=2026-09-30&municipality=Segovia&format=fixed_width
+func (c *ReportController) Preview(ctx *gin.Context) {
+	var req models.RegulatoryReportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := reporting.ParseFormat(ctx.DefaultQuery("format", string(reporting.FormatCSV)))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	buyer, ok := c.reporterBuyer(ctx)
+	if !ok {
+		return
+	}
+
+	content, err := c.reportService.Preview(&req, buyer, format)
+	if err != nil {
+		respondReportError(ctx, err)
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="reporte-borrador.`+format.Extension()+`"`)
+	ctx.Data(http.StatusOK, format.ContentType(), content)
+}
+
+// Submit presenta el reporte: guarda los archivos y marca sus compras como reportadas.
+// POST /api/v1/reports/regulatory
+func (c *ReportController) Submit(ctx *gin.Context) {
+	userID, _ := middleware.CurrentUserID(ctx)
+
+	var req models.RegulatoryReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	buyer, ok := c.reporterBuyer(ctx)
+	if !ok {
+		return
+	}
+
+	report, err := c.reportService.Submit(userID, &req, buyer)
+	if err != nil {
+		respondReportError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, report)
+}
+
+// ListReports lista los reportes presentados. Con reports:manage se puede
+// filtrar por declarante (?reporter=batea o buyer:<id>).
+// GET /api/v1/reports/regulatory?page=1&limit=10
+func (c *ReportController) ListReports(ctx *gin.Context) {
+	buyer, ok := c.reporterBuyer(ctx)
+	if !ok {
+		return
+	}
+	reporter := ctx.Query("reporter")
+	if buyer != nil {
+		reporter = models.BuyerReporter(buyer.ID)
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	result, err := c.reportService.ListReports(reporter, page, limit)
+	if err != nil {
+		respondReportError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GET /api/v1/reports/regulatory/:id
+func (c *ReportController) GetReport(ctx *gin.Context) {
+	report, ok := c.visibleReport(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// DownloadFile descarga el archivo tal como se presentó.
+// GET /api/v1/reports/regulatory/:id/file?format=csv
+func (c *ReportController) DownloadFile(ctx *gin.Context) {
+	format, err := reporting.ParseFormat(ctx.DefaultQuery("format", string(reporting.FormatCSV)))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report, ok := c.visibleReport(ctx)
+	if !ok {
+		return
+	}
+
+	content, err := c.reportService.OpenFile(report, format)
+	if err != nil {
+		respondReportError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.DataFromReader(http.StatusOK, -1, format.ContentType(), content, map[string]string{
+		"Content-Disposition": `attachment; filename="reporte-` + report.ID.String() + `.` + format.Extension() + `"`,
+	})
+}
+
+// reporterBuyer devuelve nil si el usuario reporta en nombre de Batea, o su
+// registro de comercializador. Si no es ninguno de los dos, responde 403.
+func (c *ReportController) reporterBuyer(ctx *gin.Context) (*models.Buyer, bool) {
+	if middleware.HasPermission(ctx, models.PermReportsManage) {
+		return nil, true
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	buyer, err := c.buyerService.GetBuyerByUserID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBuyerNotFound) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Solo los comercializadores y los administradores pueden generar reportes"})
+			return nil, false
+		}
+		respondReportError(ctx, err)
+		return nil, false
+	}
+	return buyer, true
+}
+
+// visibleReport carga el reporte de la ruta si el usuario puede verlo.
+func (c *ReportController) visibleReport(ctx *gin.Context) (*models.RegulatoryReport, bool) {
+	reportID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de reporte inválido"})
+		return nil, false
+	}
+	buyer, ok := c.reporterBuyer(ctx)
+	if !ok {
+		return nil, false
+	}
+	report, err := c.reportService.GetReport(reportID)
+	if err != nil {
+		respondReportError(ctx, err)
+		return nil, false
+	}
+	if buyer != nil && report.Reporter != models.BuyerReporter(buyer.ID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver este reporte"})
+		return nil, false
+	}
+	return report, true
+}
+
+func respondReportError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrReportNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidReportPeriod), errors.Is(err, service.ErrInvalidReportBuyer):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReportOtherBuyer):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNothingToReport), errors.Is(err, reporting.ErrFieldOverflow):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrSalesAlreadyReported):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en reportes regulatorios: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo generar el reporte"})
+	}
+}
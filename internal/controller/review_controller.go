@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+// ReviewController expone la revisión KYC de mineros para el personal de back-office.
+type ReviewController struct {
+	minerService service.MinerService
+}
+
+// NewReviewController crea una nueva instancia del controlador de revisión.
+func NewReviewController(s service.MinerService) *ReviewController {
+	return &ReviewController{minerService: s}
+}
+
+// ListQueue lista los mineros en un estado KYC (por defecto pending)
+// GET /api/v1/reviews/miners?status=pending&page=1&limit=10
+func (c *ReviewController) ListQueue(ctx *gin.Context) {
+	status := models.VerificationStatus(ctx.DefaultQuery("status", string(models.VerificationPending)))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	result, err := c.minerService.ListMinersForReview(status, page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Claim toma un caso pendiente
+// POST /api/v1/reviews/miners/:id/claim
+func (c *ReviewController) Claim(ctx *gin.Context) {
+	minerID, reviewerID, ok := reviewParams(ctx)
+	if !ok {
+		return
+	}
+
+	miner, err := c.minerService.ClaimReview(minerID, reviewerID)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewMinerResponse(miner, true))
+}
+
+// Approve aprueba el registro del minero
+// POST /api/v1/reviews/miners/:id/approve
+func (c *ReviewController) Approve(ctx *gin.Context) {
+	minerID, reviewerID, ok := reviewParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ApproveMinerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && ctx.Request.ContentLength > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	miner, err := c.minerService.ApproveMiner(minerID, reviewerID, req.Notes)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewMinerResponse(miner, true))
+}
+
+// Reject rechaza el registro completo o documentos puntuales con sus motivos
+// POST /api/v1/reviews/miners/:id/reject
+func (c *ReviewController) Reject(ctx *gin.Context) {
+	minerID, reviewerID, ok := reviewParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.RejectMinerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	miner, err := c.minerService.RejectMiner(minerID, reviewerID, &req)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewMinerResponse(miner, true))
+}
+
+// History devuelve el historial de transiciones KYC
+// GET /api/v1/reviews/miners/:id/history
+func (c *ReviewController) History(ctx *gin.Context) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return
+	}
+
+	events, err := c.minerService.GetReviewHistory(minerID)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, events)
+}
+
+func reviewParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	reviewerID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return minerID, reviewerID, true
+}
+
+func respondReviewError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrMinerNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTransition):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReviewNotClaimed), errors.Is(err, service.ErrSelfReview):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidDocument):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en la revisión KYC: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo procesar la revisión"})
+	}
+}
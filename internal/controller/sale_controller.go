@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type SaleController struct {
+	saleService  service.SaleService
+	minerService service.MinerService
+}
+
+func NewSaleController(s service.SaleService, m service.MinerService) *SaleController {
+	return &SaleController{saleService: s, minerService: m}
+}
+
+// POST /api/v1/sales
+func (c *SaleController) CreateSale(ctx *gin.Context) {
+	buyerID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req models.CreateSaleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sale, err := c.saleService.CreateSale(buyerID, &req)
+	if err != nil {
+		respondSaleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, sale)
+}
+
+// GetSale muestra una venta al comprador que la registró, al minero que vendió
+// o a quien tenga sales:read_any.
+// GET /api/v1/sales/:id
+func (c *SaleController) GetSale(ctx *gin.Context) {
+	saleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de venta inválido"})
+		return
+	}
+
+	sale, err := c.saleService.GetSale(saleID)
+	if err != nil {
+		respondSaleError(ctx, err)
+		return
+	}
+
+	if !canViewSale(ctx, sale) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver esta venta"})
+		return
+	}
+	ctx.JSON(http.StatusOK, sale)
+}
+
+// canViewSale indica si el usuario es parte de la venta o tiene sales:read_any.
+func canViewSale(ctx *gin.Context, sale *models.Sale) bool {
+	userID, _ := middleware.CurrentUserID(ctx)
+	isParty := sale.BuyerUserID == userID || (sale.Miner != nil && sale.Miner.UserID == userID)
+	return isParty || middleware.HasPermission(ctx, models.PermSalesReadAny)
+}
+
+// GET /api/v1/miners/:id/sales?page=1&limit=10
+func (c *SaleController) ListMinerSales(ctx *gin.Context) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return
+	}
+
+	miner, err := c.minerService.GetMinerByID(minerID)
+	if err != nil {
+		respondSaleError(ctx, err)
+		return
+	}
+	if !isMinerOwner(ctx, miner) && !middleware.HasPermission(ctx, models.PermSalesReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver las ventas de este minero"})
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	result, err := c.saleService.ListMinerSales(miner.ID, page, limit)
+	if err != nil {
+		respondSaleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetQuota muestra el cupo de venta del minero a su dueño, a los
+// comercializadores y a quien tenga sales:read_any.
+// GET /api/v1/miners/:id/quota
+func (c *SaleController) GetQuota(ctx *gin.Context) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return
+	}
+
+	miner, err := c.minerService.GetMinerByID(minerID)
+	if err != nil {
+		respondSaleError(ctx, err)
+		return
+	}
+	if !isMinerOwner(ctx, miner) &&
+		!middleware.HasPermission(ctx, models.PermSalesReadAny) &&
+		!middleware.HasPermission(ctx, models.PermSalesCreate) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver el cupo de este minero"})
+		return
+	}
+
+	quota, err := c.saleService.GetQuota(miner)
+	if err != nil {
+		respondSaleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, quota)
+}
+
+func respondSaleError(ctx *gin.Context, err error) {
+	if respondTOTPLocked(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, repository.ErrSaleNotFound), errors.Is(err, repository.ErrMinerNotFound),
+		errors.Is(err, repository.ErrPurchasePointNotFound), errors.Is(err, repository.ErrMiningSiteNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTOTP), errors.Is(err, service.ErrTOTPReused):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMinerNotApproved), errors.Is(err, service.ErrTOTPNotConfigured),
+		errors.Is(err, service.ErrProductionNotDeclared), errors.Is(err, service.ErrPointInactive),
+		errors.Is(err, service.ErrPointNotApproved):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrQuotaExceeded):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSelfSale), errors.Is(err, service.ErrBuyerNotApproved):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSiteNotOwned):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en ventas: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo procesar la venta"})
+	}
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/middleware"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+type WalletController struct {
+	walletService service.WalletService
+	minerService  service.MinerService
+	buyerService  service.BuyerService
+}
+
+func NewWalletController(w service.WalletService, m service.MinerService, b service.BuyerService) *WalletController {
+	return &WalletController{walletService: w, minerService: m, buyerService: b}
+}
+
+// GetMinerWallet muestra el saldo y el extracto del minero a su dueño o a quien
+// tenga wallets:read_any.
+// GET /api/v1/miners/:id/wallet?page=1&limit=10
+func (c *WalletController) GetMinerWallet(ctx *gin.Context) {
+	minerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de minero inválido"})
+		return
+	}
+	miner, err := c.minerService.GetMinerByID(minerID)
+	if err != nil {
+		respondWalletError(ctx, err)
+		return
+	}
+	if !isMinerOwner(ctx, miner) && !middleware.HasPermission(ctx, models.PermWalletsReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver la billetera de este minero"})
+		return
+	}
+	c.respondWallet(ctx, models.OwnerMiner, miner.ID)
+}
+
+// GetBuyerWallet muestra lo que el comercializador adeuda por sus compras.
+// GET /api/v1/buyers/:id/wallet?page=1&limit=10
+func (c *WalletController) GetBuyerWallet(ctx *gin.Context) {
+	buyerID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de comercializador inválido"})
+		return
+	}
+	buyer, err := c.buyerService.GetBuyer(buyerID)
+	if err != nil {
+		respondWalletError(ctx, err)
+		return
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	if buyer.UserID != userID && !middleware.HasPermission(ctx, models.PermWalletsReadAny) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver la billetera de este comercializador"})
+		return
+	}
+	c.respondWallet(ctx, models.OwnerBuyer, buyer.ID)
+}
+
+// SettleSale registra que llegó el pago del comercializador por la venta; desde
+// ese momento el minero puede retirar el dinero. Repetirlo no cambia nada.
+// POST /api/v1/sales/:id/settlement
+func (c *WalletController) SettleSale(ctx *gin.Context) {
+	saleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de venta inválido"})
+		return
+	}
+	txn, err := c.walletService.SettleSale(saleID)
+	if err != nil {
+		respondWalletError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, txn)
+}
+
+func (c *WalletController) respondWallet(ctx *gin.Context, ownerType models.AccountOwnerType, ownerID uuid.UUID) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	wallet, err := c.walletService.GetWallet(ownerType, ownerID, page, limit)
+	if err != nil {
+		respondWalletError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, wallet)
+}
+
+func respondWalletError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrMinerNotFound), errors.Is(err, repository.ErrBuyerNotFound),
+		errors.Is(err, repository.ErrSaleNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, service.ErrSaleNotJournaled):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error en billeteras: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo obtener la billetera"})
+	}
+}
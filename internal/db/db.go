@@ -11,8 +11,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// InitPostgres inicializa la conexión a PostgreSQL y realiza las migraciones.
-func InitPostgres(cfg *config.Config) (*gorm.DB, error) {
+// Open se conecta a PostgreSQL sin migrar ni sembrar nada. Los comandos de
+// mantenimiento la usan para no tocar el esquema.
+func Open(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=America/Santiago",
 		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort,
@@ -34,18 +35,63 @@ func InitPostgres(cfg *config.Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("fallo fatal al conectar con la base de datos: %w", err)
 	}
+	return db, nil
+}
+
+// InitPostgres inicializa la conexión a PostgreSQL y realiza las migraciones.
+func InitPostgres(cfg *config.Config) (*gorm.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// Crear tipo ENUM miner_type si no existe
 	createMinerTypeEnum(db)
 
-	// Migrar modelos de User y Miner
+	// Migrar modelos
 	if err := db.AutoMigrate(
+		&models.Permission{},
+		&models.Role{},
 		&models.User{},
 		&models.Miner{},
+		&models.Document{},
+		&models.MinerReviewEvent{},
+		&models.TOTPGuard{},
+		&models.TOTPRecoveryCode{},
+		&models.DocumentRejection{},
+		&models.RefreshToken{},
+		&models.PhoneVerification{},
+		&models.Buyer{},
+		&models.BuyerPurchasePoint{},
+		&models.MiningSite{},
+		&models.Sale{},
+		&models.LedgerEntry{},
+		&models.OriginCertificate{},
+		&models.Account{},
+		&models.JournalTransaction{},
+		&models.JournalEntry{},
+		&models.Payout{},
+		&models.ReferencePrice{},
+		&models.RegulatoryReport{},
+		&models.RegulatoryReportSale{},
 	); err != nil {
 		return nil, fmt.Errorf("fallo en la migración de la base de datos: %w", err)
 	}
 
+	// Tablas de solo inserción: la base de datos rechaza UPDATE y DELETE
+	if err := protectImmutableTables(db, "sales", "ledger_entries", "origin_certificates",
+		"journal_transactions", "journal_entries", "regulatory_reports", "regulatory_report_sales"); err != nil {
+		return nil, fmt.Errorf("fallo al proteger las tablas inmutables: %w", err)
+	}
+
+	// Sembrar roles y permisos
+	if err := seedRoles(db); err != nil {
+		return nil, fmt.Errorf("fallo al sembrar roles y permisos: %w", err)
+	}
+	if cfg.BootstrapAdminPhone != "" {
+		bootstrapAdmin(db, cfg.BootstrapAdminPhone)
+	}
+
 	log.Println("Conexión a PostgreSQL y migración completadas con éxito.")
 	return db, nil
 }
@@ -65,3 +111,91 @@ func createMinerTypeEnum(db *gorm.DB) {
 	}
 	log.Println("Tipo ENUM 'miner_type' verificado o creado correctamente.")
 }
+
+// seedRoles crea los roles y permisos por defecto. Es idempotente: solo agrega lo que falte.
+func seedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		perms := make(map[string]*models.Permission, len(models.PermissionDescriptions))
+		for name, desc := range models.PermissionDescriptions {
+			p := models.Permission{Name: name}
+			if err := tx.Where(models.Permission{Name: name}).
+				Attrs(models.Permission{Description: desc}).
+				FirstOrCreate(&p).Error; err != nil {
+				return err
+			}
+			perms[name] = &p
+		}
+
+		for _, def := range models.DefaultRoles {
+			role := models.Role{Name: def.Name}
+			if err := tx.Where(models.Role{Name: def.Name}).
+				Attrs(models.Role{Description: def.Description}).
+				FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+			rolePerms := make([]*models.Permission, 0, len(def.Permissions))
+			for _, name := range def.Permissions {
+				rolePerms = append(rolePerms, perms[name])
+			}
+			if err := tx.Model(&role).Association("Permissions").Append(rolePerms); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// bootstrapAdmin asigna el rol admin al usuario con el teléfono indicado, si existe.
+func bootstrapAdmin(db *gorm.DB, phone string) {
+	var user models.User
+	if err := db.Where("phone_number = ?", phone).First(&user).Error; err != nil {
+		log.Printf("Advertencia: no se encontró el usuario administrador inicial %s: %v", phone, err)
+		return
+	}
+	var role models.Role
+	if err := db.Where("name = ?", models.RoleAdmin).First(&role).Error; err != nil {
+		log.Printf("Advertencia: no se encontró el rol admin: %v", err)
+		return
+	}
+	if err := db.Model(&user).Association("Roles").Append(&role); err != nil {
+		log.Printf("Advertencia: no se pudo asignar el rol admin a %s: %v", phone, err)
+		return
+	}
+	log.Printf("Rol admin asignado al usuario %s.", phone)
+}
+
+// protectImmutableTables instala triggers que rechazan UPDATE, DELETE y TRUNCATE
+// sobre cada tabla indicada, de modo que ni siquiera un error del código pueda
+// modificar registros que deben ser permanentes.
+func protectImmutableTables(db *gorm.DB, tables ...string) error {
+	fn := `
+	CREATE OR REPLACE FUNCTION forbid_mutation() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'la tabla % es inmutable: % no permitido', TG_TABLE_NAME, TG_OP;
+	END;
+	$$ LANGUAGE plpgsql;
+	`
+	if err := db.Exec(fn).Error; err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		trigger := fmt.Sprintf(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = '%[1]s_immutable') THEN
+				CREATE TRIGGER %[1]s_immutable BEFORE UPDATE OR DELETE ON %[1]s
+					FOR EACH ROW EXECUTE FUNCTION forbid_mutation();
+			END IF;
+			IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = '%[1]s_no_truncate') THEN
+				CREATE TRIGGER %[1]s_no_truncate BEFORE TRUNCATE ON %[1]s
+					FOR EACH STATEMENT EXECUTE FUNCTION forbid_mutation();
+			END IF;
+		END$$;
+		`, table)
+		if err := db.Exec(trigger).Error; err != nil {
+			return fmt.Errorf("tabla %s: %w", table, err)
+		}
+	}
+	return nil
+}
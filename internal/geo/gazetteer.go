@@ -0,0 +1,85 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Gazetteer valida que un punto pertenezca a un municipio.
+type Gazetteer interface {
+	CheckMunicipality(department, municipality string, p Point) error
+}
+
+// BoundsGazetteer usa el rectángulo envolvente de cada municipio. Es una
+// aproximación: descarta coordenadas claramente erróneas (otro municipio u otra
+// región) sin necesidad de cargar los polígonos oficiales completos.
+type BoundsGazetteer struct {
+	municipalities map[string]BBox // llave: "departamento|municipio" normalizado
+}
+
+// LoadBoundsGazetteer lee un CSV con encabezado y columnas
+// department,municipality,min_lat,min_lon,max_lat,max_lon
+// (por ejemplo, exportado de la cartografía municipal del IGAC/DANE).
+func LoadBoundsGazetteer(path string) (*BoundsGazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el catálogo de municipios: %w", err)
+	}
+	defer f.Close()
+	return ParseBoundsGazetteer(f)
+}
+
+// ParseBoundsGazetteer lee el catálogo desde r con el formato de LoadBoundsGazetteer.
+func ParseBoundsGazetteer(r io.Reader) (*BoundsGazetteer, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 6
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil { // encabezado
+		return nil, fmt.Errorf("catálogo de municipios vacío o inválido: %w", err)
+	}
+
+	g := &BoundsGazetteer{municipalities: make(map[string]BBox)}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catálogo de municipios, línea %d: %w", line, err)
+		}
+
+		var v [4]float64
+		for i := range v {
+			if v[i], err = strconv.ParseFloat(strings.TrimSpace(record[2+i]), 64); err != nil {
+				return nil, fmt.Errorf("catálogo de municipios, línea %d: coordenada inválida %q", line, record[2+i])
+			}
+		}
+		g.municipalities[municipalityKey(record[0], record[1])] = BBox{MinLat: v[0], MinLon: v[1], MaxLat: v[2], MaxLon: v[3]}
+	}
+	return g, nil
+}
+
+// Len devuelve cuántos municipios tiene el catálogo.
+func (g *BoundsGazetteer) Len() int {
+	return len(g.municipalities)
+}
+
+func (g *BoundsGazetteer) CheckMunicipality(department, municipality string, p Point) error {
+	box, ok := g.municipalities[municipalityKey(department, municipality)]
+	if !ok {
+		return fmt.Errorf("%w: %s, %s", ErrUnknownMunicipality, municipality, department)
+	}
+	if !box.Contains(p) {
+		return fmt.Errorf("%w: %s, %s", ErrOutsideMunicipality, municipality, department)
+	}
+	return nil
+}
+
+func municipalityKey(department, municipality string) string {
+	return normalizeName(department) + "|" + normalizeName(municipality)
+}
@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testCatalog = `department,municipality,min_lat,min_lon,max_lat,max_lon
+Antioquia,Segovia,7.0,-74.9,7.4,-74.5
+ Chocó , Quibdó ,5.4,-76.9,6.0,-76.4
+Bogotá D.C.,Bogotá D.C.,3.7,-74.5,4.9,-73.9
+`
+
+func TestParseBoundsGazetteer(t *testing.T) {
+	g, err := ParseBoundsGazetteer(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatalf("ParseBoundsGazetteer: %v", err)
+	}
+	if g.Len() != 3 {
+		t.Fatalf("Len = %d, se esperaban 3 municipios", g.Len())
+	}
+
+	tests := []struct {
+		name         string
+		department   string
+		municipality string
+		p            Point
+		wantErr      error
+	}{
+		{name: "dentro del municipio", department: "Antioquia", municipality: "Segovia", p: Point{Lat: 7.08, Lon: -74.7}},
+		{name: "sin tildes ni mayúsculas", department: "choco", municipality: "QUIBDO", p: Point{Lat: 5.69, Lon: -76.66}},
+		{name: "espacios en el catálogo", department: "Chocó", municipality: "Quibdó", p: Point{Lat: 5.69, Lon: -76.66}},
+		{name: "en otro municipio", department: "Antioquia", municipality: "Segovia", p: Point{Lat: 5.69, Lon: -76.66}, wantErr: ErrOutsideMunicipality},
+		{name: "municipio de otro departamento", department: "Chocó", municipality: "Segovia", p: Point{Lat: 7.08, Lon: -74.7}, wantErr: ErrUnknownMunicipality},
+		{name: "municipio desconocido", department: "Antioquia", municipality: "Remedios", p: Point{Lat: 7.03, Lon: -74.69}, wantErr: ErrUnknownMunicipality},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := g.CheckMunicipality(tt.department, tt.municipality, tt.p); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckMunicipality = %v, se esperaba %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseBoundsGazetteerRejectsInvalid(t *testing.T) {
+	const header = "department,municipality,min_lat,min_lon,max_lat,max_lon\n"
+
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr string
+	}{
+		{name: "vacío", csv: "", wantErr: "vacío"},
+		{name: "coordenada no numérica", csv: header + "Antioquia,Segovia,siete,-74.9,7.4,-74.5\n", wantErr: "línea 2"},
+		{name: "columnas faltantes", csv: header + "Antioquia,Segovia,7.0,-74.9\n", wantErr: "línea 2"},
+		{name: "error en una línea posterior", csv: header + "Antioquia,Segovia,7.0,-74.9,7.4,-74.5\nCaldas,Marmato,5.4,,5.5,-75.5\n", wantErr: "línea 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBoundsGazetteer(strings.NewReader(tt.csv))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseBoundsGazetteer = %v, se esperaba un error con %q", err, tt.wantErr)
+			}
+		})
+	}
+}
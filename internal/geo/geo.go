@@ -0,0 +1,62 @@
+// Package geo valida coordenadas de sitios mineros: que caigan dentro de
+// Colombia y, si hay un catálogo de municipios cargado, dentro del municipio declarado.
+package geo
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrOutsideColombia     = errors.New("las coordenadas están fuera del territorio colombiano")
+	ErrOutsideMunicipality = errors.New("las coordenadas no corresponden al municipio declarado")
+	ErrUnknownMunicipality = errors.New("el municipio o departamento no está en el catálogo")
+)
+
+// Point es una coordenada WGS84 en grados decimales.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// BBox es un rectángulo de latitud/longitud.
+type BBox struct {
+	MinLat, MinLon, MaxLat, MaxLon float64
+}
+
+// Contains indica si el punto está dentro del rectángulo (bordes incluidos).
+func (b BBox) Contains(p Point) bool {
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+}
+
+// ColombiaBounds es el rectángulo que contiene el territorio continental e
+// insular de Colombia (incluido el archipiélago de San Andrés y Providencia).
+// Es un filtro grueso que también cubre zonas de los países vecinos: la
+// ubicación la confirma el catálogo de municipios.
+var ColombiaBounds = BBox{MinLat: -4.23, MinLon: -81.74, MaxLat: 13.40, MaxLon: -66.85}
+
+// InColombia indica si el punto cae dentro de ColombiaBounds.
+func InColombia(p Point) bool {
+	return ColombiaBounds.Contains(p)
+}
+
+// Centroid devuelve el promedio de los vértices; suficiente como punto de
+// referencia para polígonos pequeños como los de un título minero.
+func Centroid(points []Point) Point {
+	var c Point
+	for _, p := range points {
+		c.Lat += p.Lat
+		c.Lon += p.Lon
+	}
+	n := float64(len(points))
+	return Point{Lat: c.Lat / n, Lon: c.Lon / n}
+}
+
+// normalizeName pasa a minúsculas y quita tildes y espacios extra para comparar
+// nombres de municipios y departamentos ("Bogotá, D.C." ~ "bogota, d.c.").
+func normalizeName(s string) string {
+	replacer := strings.NewReplacer(
+		"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	)
+	return strings.Join(strings.Fields(replacer.Replace(strings.ToLower(s))), " ")
+}
@@ -0,0 +1,103 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBBoxContains(t *testing.T) {
+	box := BBox{MinLat: 5.0, MinLon: -76.0, MaxLat: 6.0, MaxLon: -75.0}
+
+	tests := []struct {
+		name string
+		p    Point
+		want bool
+	}{
+		{name: "centro", p: Point{Lat: 5.5, Lon: -75.5}, want: true},
+		{name: "esquina inferior", p: Point{Lat: 5.0, Lon: -76.0}, want: true},
+		{name: "esquina superior", p: Point{Lat: 6.0, Lon: -75.0}, want: true},
+		{name: "al norte", p: Point{Lat: 6.0001, Lon: -75.5}},
+		{name: "al sur", p: Point{Lat: 4.9999, Lon: -75.5}},
+		{name: "al oriente", p: Point{Lat: 5.5, Lon: -74.9999}},
+		{name: "al occidente", p: Point{Lat: 5.5, Lon: -76.0001}},
+		{name: "latitud y longitud invertidas", p: Point{Lat: -75.5, Lon: 5.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := box.Contains(tt.p); got != tt.want {
+				t.Fatalf("Contains(%+v) = %v, se esperaba %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInColombia(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Point
+		want bool
+	}{
+		{name: "Medellín", p: Point{Lat: 6.2442, Lon: -75.5812}, want: true},
+		{name: "San Andrés", p: Point{Lat: 12.5847, Lon: -81.7006}, want: true},
+		{name: "Leticia", p: Point{Lat: -4.2153, Lon: -69.9406}, want: true},
+		{name: "Quito", p: Point{Lat: -0.1807, Lon: -78.4678}, want: true}, // el rectángulo es grueso
+		{name: "Ciudad de Panamá", p: Point{Lat: 8.9824, Lon: -79.5199}, want: true},
+		{name: "Lima", p: Point{Lat: -12.0464, Lon: -77.0428}},
+		{name: "Miami", p: Point{Lat: 25.7617, Lon: -80.1918}},
+		{name: "coordenadas en cero", p: Point{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InColombia(tt.p); got != tt.want {
+				t.Fatalf("InColombia(%+v) = %v, se esperaba %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Point
+		want   Point
+	}{
+		{name: "un punto", points: []Point{{Lat: 5.1, Lon: -75.2}}, want: Point{Lat: 5.1, Lon: -75.2}},
+		{
+			name:   "cuadrado",
+			points: []Point{{Lat: 5, Lon: -76}, {Lat: 5, Lon: -75}, {Lat: 6, Lon: -75}, {Lat: 6, Lon: -76}},
+			want:   Point{Lat: 5.5, Lon: -75.5},
+		},
+		{
+			name:   "triángulo",
+			points: []Point{{Lat: 7, Lon: -74}, {Lat: 7.3, Lon: -74}, {Lat: 7, Lon: -73.7}},
+			want:   Point{Lat: 7.1, Lon: -73.9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Centroid(tt.points)
+			if math.Abs(got.Lat-tt.want.Lat) > 1e-9 || math.Abs(got.Lon-tt.want.Lon) > 1e-9 {
+				t.Fatalf("Centroid = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Bogotá, D.C.", "bogota, d.c."},
+		{"  San   José del  Guaviare ", "san jose del guaviare"},
+		{"NARIÑO", "narino"},
+		{"MEDELLÍN", "medellin"},
+		{"Nariño", "narino"},
+		{"Güepsa", "guepsa"},
+		{"Quibdó", "quibdo"},
+	}
+	for _, tt := range tests {
+		if got := normalizeName(tt.in); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, se esperaba %q", tt.in, got, tt.want)
+		}
+	}
+}
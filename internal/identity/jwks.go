@@ -0,0 +1,155 @@
+package identity
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSCacheTTL = time.Hour
+	// Tiempo mínimo entre descargas forzadas por un kid desconocido
+	minJWKSRefreshInterval = time.Minute
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSKeySource descarga y cachea las llaves públicas de un endpoint JWKS.
+// Respeta el Cache-Control max-age de la respuesta y vuelve a descargar
+// cuando aparece un kid desconocido (rotación de llaves).
+type JWKSKeySource struct {
+	url    string
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	expiresAt   time.Time
+	lastFetched time.Time
+}
+
+// NewJWKSKeySource crea una fuente de llaves para la URL JWKS indicada.
+func NewJWKSKeySource(url string) *JWKSKeySource {
+	return &JWKSKeySource{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   map[string]*rsa.PublicKey{},
+	}
+}
+
+func (s *JWKSKeySource) PublicKey(kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	fresh := time.Now().Before(s.expiresAt)
+	recentlyFetched := time.Since(s.lastFetched) < minJWKSRefreshInterval
+	s.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+	// kid desconocido con caché vigente: solo se reintenta si no se descargó hace poco
+	if !ok && fresh && recentlyFetched {
+		return nil, fmt.Errorf("llave de firma desconocida: %s", kid)
+	}
+
+	if err := s.refresh(); err != nil {
+		// Si la descarga falla se sigue usando la llave cacheada, aunque esté vencida
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("llave de firma desconocida: %s", kid)
+}
+
+func (s *JWKSKeySource) refresh() error {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return fmt.Errorf("error al descargar JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("respuesta inesperada del endpoint JWKS: %s", resp.Status)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("JWKS con formato inválido: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		pub, err := parseRSAPublicKey(k.N, k.E)
+		if err != nil {
+			return fmt.Errorf("llave %s inválida en JWKS: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.keys = keys
+	s.lastFetched = now
+	s.expiresAt = now.Add(cacheMaxAge(resp.Header.Get("Cache-Control")))
+	s.mu.Unlock()
+	return nil
+}
+
+// cacheMaxAge extrae max-age del encabezado Cache-Control.
+func cacheMaxAge(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultJWKSCacheTTL
+}
+
+func parseRSAPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(eBytes)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponente fuera de rango")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(exponent.Int64()),
+	}, nil
+}
@@ -0,0 +1,88 @@
+package identity
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer base de los ID tokens de Firebase / Identity Platform
+const firebaseIssuerPrefix = "https://securetoken.google.com/"
+
+var ErrInvalidIDToken = errors.New("ID token inválido")
+
+// Claims es la identidad derivada de un ID token verificado.
+type Claims struct {
+	UID            string
+	PhoneNumber    string
+	PhoneVerified  bool
+	SignInProvider string
+}
+
+// Verifier verifica ID tokens emitidos por el proveedor de identidad.
+type Verifier interface {
+	Verify(idToken string) (*Claims, error)
+}
+
+// KeySource entrega la llave pública con la que se firmó un token (por kid).
+type KeySource interface {
+	PublicKey(kid string) (*rsa.PublicKey, error)
+}
+
+// firebaseClaims son los claims que Identity Platform incluye en el ID token.
+type firebaseClaims struct {
+	jwt.RegisteredClaims
+	AuthTime    int64  `json:"auth_time"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Firebase    struct {
+		SignInProvider string `json:"sign_in_provider"`
+	} `json:"firebase"`
+}
+
+type tokenVerifier struct {
+	projectID string
+	keys      KeySource
+}
+
+// NewVerifier crea un verificador de ID tokens para el proyecto indicado
+// usando las llaves públicas de keys (JWKS en producción, LocalSigner en pruebas).
+func NewVerifier(projectID string, keys KeySource) Verifier {
+	return &tokenVerifier{projectID: projectID, keys: keys}
+}
+
+func (v *tokenVerifier) Verify(idToken string) (*Claims, error) {
+	var claims firebaseClaims
+	_, err := jwt.ParseWithClaims(idToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("el token no indica kid")
+		}
+		return v.keys.PublicKey(kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithAudience(v.projectID),
+		jwt.WithIssuer(firebaseIssuerPrefix+v.projectID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" || len(claims.Subject) > 128 {
+		return nil, fmt.Errorf("%w: sub vacío o demasiado largo", ErrInvalidIDToken)
+	}
+
+	// Un número de teléfono solo se considera verificado si el usuario
+	// inició sesión con el proveedor "phone" (código SMS de Identity Platform)
+	phoneVerified := claims.PhoneNumber != "" && claims.Firebase.SignInProvider == "phone"
+
+	return &Claims{
+		UID:            claims.Subject,
+		PhoneNumber:    claims.PhoneNumber,
+		PhoneVerified:  phoneVerified,
+		SignInProvider: claims.Firebase.SignInProvider,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package identity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testProject = "batea-test"
+
+func newTestSigner(t *testing.T, projectID string) *LocalSigner {
+	t.Helper()
+	signer, err := NewLocalSigner(projectID)
+	if err != nil {
+		t.Fatalf("NewLocalSigner: %v", err)
+	}
+	return signer
+}
+
+func signToken(t *testing.T, signer *LocalSigner, claims Claims, ttl time.Duration) string {
+	t.Helper()
+	token, err := signer.Sign(claims, ttl)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return token
+}
+
+func TestVerifierAcceptsValidToken(t *testing.T) {
+	signer := newTestSigner(t, testProject)
+	verifier := NewVerifier(testProject, signer)
+
+	tests := []struct {
+		name          string
+		identity      Claims
+		phoneVerified bool
+	}{
+		{"inicio con teléfono", Claims{UID: "uid-1", PhoneNumber: "+573001112233", PhoneVerified: true}, true},
+		{"teléfono sin proveedor phone", Claims{UID: "uid-2", PhoneNumber: "+573001112233", SignInProvider: "password"}, false},
+		{"sin teléfono", Claims{UID: "uid-3", SignInProvider: "google.com"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(signToken(t, signer, tt.identity, time.Hour))
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.UID != tt.identity.UID {
+				t.Errorf("UID = %q, se esperaba %q", claims.UID, tt.identity.UID)
+			}
+			if claims.PhoneVerified != tt.phoneVerified {
+				t.Errorf("PhoneVerified = %v, se esperaba %v", claims.PhoneVerified, tt.phoneVerified)
+			}
+		})
+	}
+}
+
+func TestVerifierRejectsInvalidTokens(t *testing.T) {
+	signer := newTestSigner(t, testProject)
+	otherKey := newTestSigner(t, testProject)
+	otherProject := newTestSigner(t, "otro-proyecto")
+	verifier := NewVerifier(testProject, signer)
+	identity := Claims{UID: "uid-1", PhoneVerified: true, PhoneNumber: "+573001112233"}
+
+	valid := signToken(t, signer, identity, time.Hour)
+	parts := strings.Split(valid, ".")
+
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    firebaseIssuerPrefix + testProject,
+		Audience:  jwt.ClaimStrings{testProject},
+		Subject:   "uid-1",
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	hmacToken.Header["kid"] = signer.kid
+	hmacSigned, err := hmacToken.SignedString([]byte("secreto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"vacío", ""},
+		{"malformado", "no-es-un-jwt"},
+		{"expirado", signToken(t, signer, identity, -time.Minute)},
+		{"llave desconocida", signToken(t, otherKey, identity, time.Hour)},
+		{"otro proyecto", signToken(t, otherProject, identity, time.Hour)},
+		{"firma alterada", parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2]))},
+		{"algoritmo HS256", hmacSigned},
+		{"sin sub", signToken(t, signer, Claims{PhoneVerified: true}, time.Hour)},
+		{"sub demasiado largo", signToken(t, signer, Claims{UID: strings.Repeat("x", 129)}, time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(tt.token)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("Verify = (%v, %v), se esperaba ErrInvalidIDToken", claims, err)
+			}
+		})
+	}
+}
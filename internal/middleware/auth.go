@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/service"
+)
+
+// Claves bajo las que se guarda el usuario autenticado en el gin.Context
+const (
+	ContextUserKey   = "user"
+	ContextUserIDKey = "user_id"
+)
+
+// AuthRequired valida el token Bearer del encabezado Authorization, carga el
+// models.User correspondiente (con roles y permisos) y lo deja disponible en el contexto.
+func AuthRequired(authService service.AuthService, userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Se requiere un token de acceso (Authorization: Bearer <token>)"})
+			return
+		}
+
+		userID, err := authService.ValidateAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token de acceso inválido o expirado"})
+			return
+		}
+
+		user, err := userRepo.FindByIDWithRoles(userID.String())
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "El usuario del token no existe o fue eliminado"})
+				return
+			}
+			log.Printf("Error al cargar el usuario autenticado: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "No se pudo validar la sesión"})
+			return
+		}
+
+		ctx.Set(ContextUserKey, user)
+		ctx.Set(ContextUserIDKey, user.ID)
+		ctx.Next()
+	}
+}
+
+// CurrentUser devuelve el usuario autenticado por AuthRequired.
+func CurrentUser(ctx *gin.Context) (*models.User, bool) {
+	value, exists := ctx.Get(ContextUserKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok
+}
+
+// CurrentUserID devuelve el ID del usuario autenticado por AuthRequired.
+func CurrentUserID(ctx *gin.Context) (uuid.UUID, bool) {
+	value, exists := ctx.Get(ContextUserIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission permite el acceso solo si el usuario autenticado tiene
+// alguno de los permisos indicados. Debe ir después de AuthRequired.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := CurrentUser(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+			return
+		}
+
+		for _, p := range permissions {
+			if user.HasPermission(p) {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para realizar esta acción"})
+	}
+}
+
+// HasPermission indica si el usuario autenticado tiene el permiso indicado.
+// Útil en controladores donde el acceso depende también de la propiedad del recurso.
+func HasPermission(ctx *gin.Context, permission string) bool {
+	user, ok := CurrentUser(ctx)
+	return ok && user.HasPermission(permission)
+}
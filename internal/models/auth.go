@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken guarda del lado del servidor los refresh tokens emitidos.
+// Solo se almacena el hash SHA-256 del token, nunca el valor en claro.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+
+	// Token que reemplazó a este al rotarlo (para detectar reutilización)
+	ReplacedByID *uuid.UUID `gorm:"type:uuid" json:"-"`
+}
+
+// DTO de entrada para iniciar sesión con teléfono y contraseña
+type LoginRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Password    string `json:"password" binding:"required"`
+}
+
+// DTO de entrada para iniciar sesión con un ID token de Identity Platform
+type IDTokenLoginRequest struct {
+	IDToken string `json:"id_token" binding:"required"`
+}
+
+// DTO de entrada para rotar o revocar un refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// DTO de salida con el par de tokens emitido
+type TokenResponse struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int64     `json:"expires_in"` // Segundos de vida del access token
+	RefreshToken string    `json:"refresh_token"`
+	UserID       uuid.UUID `json:"user_id"`
+
+	// Minero asociado al usuario, si ya completó el registro como minero
+	MinerID *uuid.UUID `json:"miner_id,omitempty"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BuyerStatus es el estado de la revisión del comercializador.
+type BuyerStatus string
+
+const (
+	BuyerPending  BuyerStatus = "pending"  // Esperando aprobación de un administrador
+	BuyerApproved BuyerStatus = "approved" // Puede registrar compras
+	BuyerRejected BuyerStatus = "rejected" // Solicitud rechazada
+)
+
+// PurchasePointStatus es el estado de la revisión de un punto de compra.
+type PurchasePointStatus string
+
+const (
+	PointPending  PurchasePointStatus = "pending"  // Esperando aprobación; no admite compras
+	PointApproved PurchasePointStatus = "approved" // Admite compras mientras esté activo
+	PointRejected PurchasePointStatus = "rejected" // Rechazado
+)
+
+// Certificados que sube el comercializador
+const (
+	DocRucomCertificate  DocumentKind = "rucom_certificate"   // Certificado de inscripción en el RUCOM
+	DocRUT               DocumentKind = "rut"                 // Registro Único Tributario
+	DocChamberOfCommerce DocumentKind = "chamber_of_commerce" // Certificado de existencia y representación legal
+)
+
+// BuyerDocumentKinds son los certificados obligatorios del comercializador.
+var BuyerDocumentKinds = []DocumentKind{DocRucomCertificate, DocRUT, DocChamberOfCommerce}
+
+// Buyer es el comercializador de oro, contraparte de cada venta.
+// Sus certificados se guardan como filas de documents con BuyerID.
+type Buyer struct {
+	ID              uuid.UUID   `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	UserID          uuid.UUID   `gorm:"type:uuid;not null;unique" json:"user_id"`
+	LegalName       string      `gorm:"not null" json:"legal_name"`
+	NIT             string      `gorm:"column:nit;not null;unique" json:"nit"`
+	RUCOMNumber     string      `gorm:"column:rucom_number;not null;unique" json:"rucom_number"`
+	Status          BuyerStatus `gorm:"type:varchar(32);not null;default:'pending';index" json:"status"`
+	ReviewedBy      *uuid.UUID  `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time  `json:"reviewed_at,omitempty"`
+	RejectionReason string      `json:"rejection_reason,omitempty"`
+
+	PurchasePoints []BuyerPurchasePoint `gorm:"foreignKey:BuyerID" json:"purchase_points,omitempty"`
+	Documents      []Document           `gorm:"foreignKey:BuyerID" json:"documents,omitempty"`
+}
+
+// BuyerPurchasePoint es un punto de compra del comercializador. Su dirección
+// aparece en las ventas y en los certificados de origen, así que solo admite
+// compras después de que un administrador lo aprueba. Los puntos no se borran
+// (las ventas los referencian); se desactivan.
+type BuyerPurchasePoint struct {
+	ID              uuid.UUID           `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt       time.Time           `json:"created_at"`
+	BuyerID         uuid.UUID           `gorm:"type:uuid;not null;index" json:"buyer_id"`
+	Name            string              `gorm:"not null" json:"name"`
+	Address         string              `gorm:"not null" json:"address"`
+	Municipality    string              `gorm:"not null" json:"municipality"`
+	Department      string              `gorm:"not null" json:"department"`
+	Active          bool                `gorm:"not null;default:true" json:"active"`
+	Status          PurchasePointStatus `gorm:"type:varchar(32);not null;default:'pending';index" json:"status"`
+	ReviewedBy      *uuid.UUID          `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time          `json:"reviewed_at,omitempty"`
+	RejectionReason string              `json:"rejection_reason,omitempty"`
+}
+
+// DTO de entrada para registrarse como comercializador (multipart con los certificados)
+type CreateBuyerRequest struct {
+	LegalName   string `form:"legal_name" binding:"required,max=200"`
+	NIT         string `form:"nit" binding:"required,max=20"`
+	RUCOMNumber string `form:"rucom_number" binding:"required,max=50"`
+}
+
+// DTO de entrada para agregar un punto de compra
+type CreatePurchasePointRequest struct {
+	Name         string `json:"name" binding:"required,max=200"`
+	Address      string `json:"address" binding:"required,max=300"`
+	Municipality string `json:"municipality" binding:"required,max=100"`
+	Department   string `json:"department" binding:"required,max=100"`
+}
+
+// DTO de entrada para rechazar a un comercializador
+type RejectBuyerRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=1000"`
+}
+
+// Límites de los certificados del comercializador
+const (
+	BuyerCertificateMaxSize  = 5 * Megabyte
+	BuyerCertificateMaxPages = 20
+)
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OriginCertificate registra el certificado de origen emitido para una venta.
+// El PDF vive en el almacenamiento de documentos; aquí se guarda su huella y la
+// firma del servidor. Es inmutable, como la venta que lo respalda.
+type OriginCertificate struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"issued_at"`
+	SaleID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"sale_id"`
+	Number     string    `gorm:"not null;uniqueIndex" json:"number"`
+	StorageKey string    `gorm:"not null" json:"-"`
+	SHA256     string    `gorm:"type:char(64);not null" json:"sha256"`
+	Signature  string    `gorm:"not null" json:"signature"` // Ed25519 sobre la huella, en base64
+	KeyID      string    `gorm:"not null" json:"key_id"`
+}
+
+// CertificateVerification es la respuesta pública al escanear el QR del certificado.
+type CertificateVerification struct {
+	Valid         bool      `json:"valid"`
+	Reason        string    `json:"reason,omitempty"`
+	Number        string    `json:"number"`
+	IssuedAt      time.Time `json:"issued_at"`
+	SaleDate      time.Time `json:"sale_date"`
+	MinerName     string    `json:"miner_name"`
+	Municipality  string    `json:"municipality"`
+	Department    string    `json:"department"`
+	TitleNumber   string    `json:"title_number,omitempty"`
+	WeightGrams   float64   `json:"weight_grams"`
+	FineGoldGrams float64   `json:"fine_gold_grams"`
+	BuyerName     string    `json:"buyer_name"`
+	SHA256        string    `json:"sha256"`
+	Signature     string    `json:"signature"`
+	KeyID         string    `json:"key_id"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Document es la metadata de un archivo subido por un minero o un comercializador
+// (solo uno de MinerID y BuyerID). El archivo se guarda en el almacenamiento bajo
+// StorageKey; el nombre original solo se conserva saneado.
+type Document struct {
+	ID           uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt    time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	MinerID      *uuid.UUID   `gorm:"type:uuid;index" json:"miner_id,omitempty"`
+	BuyerID      *uuid.UUID   `gorm:"type:uuid;index;check:chk_documents_owner,(miner_id IS NULL) <> (buyer_id IS NULL)" json:"buyer_id,omitempty"`
+	Kind         DocumentKind `gorm:"type:varchar(64);not null" json:"kind"`
+	StorageKey   string       `gorm:"not null;uniqueIndex" json:"-"`
+	OriginalName string       `gorm:"not null" json:"original_name"`
+	Size         int64        `gorm:"not null" json:"size"`
+	ContentType  string       `gorm:"not null" json:"content_type"`
+	Checksum     string       `gorm:"type:char(64);not null" json:"checksum"` // SHA-256 en hexadecimal
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de evento registrados en el libro mayor
+const (
+	LedgerSaleCreated   = "sale.created"
+	LedgerKYCTransition = "kyc.transition"
+	LedgerBuyerReviewed = "buyer.reviewed"
+	LedgerPointReviewed = "purchase_point.reviewed"
+)
+
+// Tipos de agregado al que pertenece cada evento
+const (
+	AggregateSale  = "sale"
+	AggregateMiner = "miner"
+	AggregateBuyer = "buyer"
+	AggregatePoint = "purchase_point"
+)
+
+// LedgerGenesisHash es el PrevHash de la primera entrada.
+const LedgerGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// LedgerEntry es una entrada del libro mayor de solo inserción. Cada entrada
+// guarda el hash de la anterior, así que editar o borrar cualquier fila rompe
+// la cadena desde ese punto. El payload se guarda como texto (no jsonb) para
+// conservar exactamente los bytes sobre los que se calculó el hash.
+type LedgerEntry struct {
+	Seq           int64     `gorm:"primaryKey;autoIncrement:false" json:"seq"`
+	CreatedAt     time.Time `gorm:"not null" json:"created_at"`
+	EventType     string    `gorm:"type:varchar(64);not null;index" json:"event_type"`
+	AggregateType string    `gorm:"type:varchar(32);not null" json:"aggregate_type"`
+	AggregateID   uuid.UUID `gorm:"type:uuid;not null;index" json:"aggregate_id"`
+	Payload       string    `gorm:"type:text;not null" json:"payload"`
+	PrevHash      string    `gorm:"type:char(64);not null" json:"prev_hash"`
+	Hash          string    `gorm:"type:char(64);not null;uniqueIndex" json:"hash"`
+}
+
+// ComputeHash calcula el SHA-256 de la entrada encadenada a PrevHash. La fecha
+// se toma en UTC con precisión de microsegundos, la misma que guarda Postgres.
+func (e *LedgerEntry) ComputeHash() string {
+	fields := []string{
+		e.PrevHash,
+		strconv.FormatInt(e.Seq, 10),
+		e.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		e.EventType,
+		e.AggregateType,
+		e.AggregateID.String(),
+		e.Payload,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testLedgerEntry() LedgerEntry {
+	return LedgerEntry{
+		Seq:           7,
+		CreatedAt:     time.Date(2026, time.March, 4, 15, 30, 0, 123456000, time.UTC),
+		EventType:     LedgerSaleCreated,
+		AggregateType: AggregateSale,
+		AggregateID:   uuid.MustParse("6f1c2c1e-5d0a-4b8e-9a57-3a8f1f0f9b11"),
+		Payload:       `{"weight_grams":12.5}`,
+		PrevHash:      LedgerGenesisHash,
+	}
+}
+
+func TestLedgerComputeHashIsStable(t *testing.T) {
+	e := testLedgerEntry()
+	hash := e.ComputeHash()
+	if len(hash) != 64 {
+		t.Fatalf("ComputeHash = %q, se esperaban 64 caracteres hex", hash)
+	}
+
+	// La misma fecha en otra zona, o con nanosegundos que Postgres no guarda, da el mismo hash
+	bogota := e
+	bogota.CreatedAt = e.CreatedAt.In(time.FixedZone("COT", -5*60*60))
+	nanos := e
+	nanos.CreatedAt = e.CreatedAt.Add(789 * time.Nanosecond)
+	for name, other := range map[string]LedgerEntry{"otra zona": bogota, "nanosegundos": nanos} {
+		if got := other.ComputeHash(); got != hash {
+			t.Errorf("%s: ComputeHash = %s, se esperaba %s", name, got, hash)
+		}
+	}
+}
+
+func TestLedgerComputeHashCoversEveryField(t *testing.T) {
+	base := testLedgerEntry()
+	hash := base.ComputeHash()
+
+	tests := []struct {
+		name   string
+		change func(e *LedgerEntry)
+	}{
+		{"seq", func(e *LedgerEntry) { e.Seq++ }},
+		{"fecha", func(e *LedgerEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{"tipo de evento", func(e *LedgerEntry) { e.EventType = LedgerKYCTransition }},
+		{"tipo de agregado", func(e *LedgerEntry) { e.AggregateType = AggregateMiner }},
+		{"agregado", func(e *LedgerEntry) { e.AggregateID = uuid.New() }},
+		{"payload", func(e *LedgerEntry) { e.Payload = `{"weight_grams":125}` }},
+		{"hash anterior", func(e *LedgerEntry) { e.PrevHash = hash }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := base
+			tt.change(&e)
+			if e.ComputeHash() == hash {
+				t.Fatalf("cambiar %s no cambió el hash", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MinerReviewEvent registra cada transición del estado KYC de un minero:
+// quién la hizo, cuándo, desde y hacia qué estado, y por qué.
+type MinerReviewEvent struct {
+	ID         uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt  time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	MinerID    uuid.UUID          `gorm:"type:uuid;not null;index" json:"miner_id"`
+	ActorID    uuid.UUID          `gorm:"type:uuid;not null" json:"actor_id"`
+	FromStatus VerificationStatus `gorm:"type:varchar(32);not null" json:"from_status"`
+	ToStatus   VerificationStatus `gorm:"type:varchar(32);not null" json:"to_status"`
+	Reason     string             `json:"reason,omitempty"`
+
+	// Documentos rechazados en esta transición (si aplica)
+	DocumentRejections []DocumentRejection `gorm:"foreignKey:EventID" json:"document_rejections,omitempty"`
+}
+
+// DocumentRejection es el rechazo de un documento puntual con su motivo.
+type DocumentRejection struct {
+	ID           uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	EventID      uuid.UUID    `gorm:"type:uuid;not null;index" json:"event_id"`
+	MinerID      uuid.UUID    `gorm:"type:uuid;not null;index" json:"miner_id"`
+	DocumentKind DocumentKind `gorm:"type:varchar(64);not null" json:"document_kind"`
+	Reason       string       `gorm:"not null" json:"reason"`
+}
+
+// DTO de entrada para aprobar un registro
+type ApproveMinerRequest struct {
+	Notes string `json:"notes"`
+}
+
+// DTO de entrada para rechazar un registro. Si se indican documentos, el minero
+// queda en needs_resubmission; si no, el rechazo es definitivo.
+type RejectMinerRequest struct {
+	Reason    string                   `json:"reason" binding:"required"`
+	Documents []DocumentRejectionInput `json:"documents" binding:"omitempty,dive"`
+}
+
+type DocumentRejectionInput struct {
+	Kind   DocumentKind `json:"kind" binding:"required"`
+	Reason string       `json:"reason" binding:"required"`
+}
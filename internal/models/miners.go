@@ -16,6 +16,51 @@ const (
 	SubsistenceMiner  MinerType = "subsistencia" // Minero de subsistencia
 )
 
+// VerificationStatus es el estado de la revisión KYC del registro del minero.
+type VerificationStatus string
+
+const (
+	VerificationPending           VerificationStatus = "pending"            // Esperando revisión
+	VerificationInReview          VerificationStatus = "in_review"          // Tomado por un revisor
+	VerificationApproved          VerificationStatus = "approved"           // Documentos aprobados
+	VerificationRejected          VerificationStatus = "rejected"           // Registro rechazado (final)
+	VerificationNeedsResubmission VerificationStatus = "needs_resubmission" // Debe volver a subir documentos
+)
+
+// DocumentKind identifica cada documento que sube el minero.
+type DocumentKind string
+
+const (
+	DocIDPhotoFront         DocumentKind = "id_photo_front"
+	DocIDPhotoBack          DocumentKind = "id_photo_back"
+	DocFacialPhoto          DocumentKind = "facial_photo"
+	DocRucon                DocumentKind = "rucon"
+	DocOther                DocumentKind = "other_doc"
+	DocExploitationContract DocumentKind = "exploitation_contract"
+	DocEnvironmentalTool    DocumentKind = "environmental_tool"
+	DocTechnicalTool        DocumentKind = "technical_tool"
+)
+
+// AllDocumentKinds enumera todos los documentos, sin importar el tipo de minero.
+var AllDocumentKinds = []DocumentKind{
+	DocIDPhotoFront, DocIDPhotoBack, DocFacialPhoto,
+	DocRucon, DocOther,
+	DocExploitationContract, DocEnvironmentalTool, DocTechnicalTool,
+}
+
+// DocumentKindsFor devuelve los documentos que aplican a cada tipo de minero.
+func DocumentKindsFor(minerType MinerType) []DocumentKind {
+	common := []DocumentKind{DocIDPhotoFront, DocIDPhotoBack, DocFacialPhoto}
+	switch minerType {
+	case SubsistenceMiner:
+		return append(common, DocRucon, DocOther)
+	case TitularMiner:
+		return append(common, DocExploitationContract, DocEnvironmentalTool, DocTechnicalTool)
+	default:
+		return common
+	}
+}
+
 // Miner representa la entidad del minero en la base de datos.
 type Miner struct {
 	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
@@ -32,29 +77,131 @@ type Miner struct {
 	Email       string    `gorm:"unique;not null" json:"email"`
 	MinerType   MinerType `gorm:"type:miner_type;not null" json:"miner_type"`
 
-	// TOTP Secret (almacenado de forma segura)
-	TOTPSecret string `gorm:"not null" json:"totp_secret"` 
+	// Revisión KYC
+	VerificationStatus VerificationStatus `gorm:"type:varchar(32);not null;default:'pending';index" json:"verification_status"`
+	ReviewerID         *uuid.UUID         `gorm:"type:uuid" json:"reviewer_id,omitempty"`
 
-	// Archivos
-	IDPhotoFrontPath string `json:"id_photo_front_path"`
-	IDPhotoBackPath  string `json:"id_photo_back_path"`
-	FacialPhotoPath  string `json:"facial_photo_path"`
+	// Secreto TOTP cifrado con secrets.Envelope (prefijo enc:v1:<llave>); nunca se expone en JSON
+	TOTPSecret string `gorm:"not null" json:"-"`
+	// Secreto recién emitido que espera el primer código válido para reemplazar a TOTPSecret
+	TOTPPendingSecret string     `gorm:"not null;default:''" json:"-"`
+	TOTPConfirmedAt   *time.Time `json:"totp_confirmed_at,omitempty"`
+
+	// Archivos (rutas internas de almacenamiento, nunca se exponen en JSON)
+	IDPhotoFrontPath string `json:"-"`
+	IDPhotoBackPath  string `json:"-"`
+	FacialPhotoPath  string `json:"-"`
 
 	// Documentos específicos
-	RuconPath       string `json:"rucon_path,omitempty"`
-	OtherDocPath    string `json:"other_doc_path,omitempty"`
-	ExploitationContractPath string `json:"exploitation_contract_path,omitempty"`
-	EnvironmentalToolPath    string `json:"environmental_tool_path,omitempty"`
-	TechnicalToolPath        string `json:"technical_tool_path,omitempty"`
+	RuconPath                string `json:"-"`
+	OtherDocPath             string `json:"-"`
+	ExploitationContractPath string `json:"-"`
+	EnvironmentalToolPath    string `json:"-"`
+	TechnicalToolPath        string `json:"-"`
+
+	// Producción anual declarada en el contrato de explotación (solo titulares, en gramos)
+	DeclaredAnnualProductionGrams *float64 `gorm:"type:numeric(12,3)" json:"declared_annual_production_grams,omitempty"`
+}
+
+// documentPaths devuelve punteros a los campos de ruta de cada documento.
+func (m *Miner) documentPaths() map[DocumentKind]*string {
+	return map[DocumentKind]*string{
+		DocIDPhotoFront:         &m.IDPhotoFrontPath,
+		DocIDPhotoBack:          &m.IDPhotoBackPath,
+		DocFacialPhoto:          &m.FacialPhotoPath,
+		DocRucon:                &m.RuconPath,
+		DocOther:                &m.OtherDocPath,
+		DocExploitationContract: &m.ExploitationContractPath,
+		DocEnvironmentalTool:    &m.EnvironmentalToolPath,
+		DocTechnicalTool:        &m.TechnicalToolPath,
+	}
+}
+
+// DocumentPath devuelve la ruta almacenada del documento indicado ("" si no existe).
+func (m *Miner) DocumentPath(kind DocumentKind) string {
+	if p, ok := m.documentPaths()[kind]; ok {
+		return *p
+	}
+	return ""
+}
+
+// SetDocumentPath actualiza la ruta almacenada del documento indicado.
+func (m *Miner) SetDocumentPath(kind DocumentKind, path string) {
+	if p, ok := m.documentPaths()[kind]; ok {
+		*p = path
+	}
 }
 
 // CreateMinerRequest es el DTO para recibir datos de entrada del formulario.
 type CreateMinerRequest struct {
-	FullName     string    `form:"full_name" binding:"required"`
-	LastName     string    `form:"last_name" binding:"required"`
-	PhoneNumber  string    `form:"phone_number"`
-	Email        string    `form:"email" binding:"required,email"`
-	MinerType    MinerType `form:"miner_type" binding:"required,oneof=titular subsistencia"`
+	FullName                      string    `form:"full_name" binding:"required"`
+	LastName                      string    `form:"last_name" binding:"required"`
+	PhoneNumber                   string    `form:"phone_number"`
+	Email                         string    `form:"email" binding:"required,email"`
+	MinerType                     MinerType `form:"miner_type" binding:"required,oneof=titular subsistencia"`
+	DeclaredAnnualProductionGrams *float64  `form:"declared_annual_production_grams" binding:"omitempty,gt=0"`
+}
+
+// UpdateMinerRequest es el DTO para actualizar el perfil del minero (PATCH parcial).
+type UpdateMinerRequest struct {
+	FullName                      *string  `json:"full_name" binding:"omitempty,min=1"`
+	LastName                      *string  `json:"last_name" binding:"omitempty,min=1"`
+	Email                         *string  `json:"email" binding:"omitempty,email"`
+	DeclaredAnnualProductionGrams *float64 `json:"declared_annual_production_grams" binding:"omitempty,gt=0"`
+}
+
+// MinerResponse es el DTO de salida del minero. No incluye el secreto TOTP
+// ni las rutas internas de almacenamiento de los documentos.
+type MinerResponse struct {
+	ID                            uuid.UUID            `json:"id"`
+	UserID                        uuid.UUID            `json:"user_id"`
+	FullName                      string               `json:"full_name"`
+	LastName                      string               `json:"last_name"`
+	Email                         string               `json:"email"`
+	MinerType                     MinerType            `json:"miner_type"`
+	VerificationStatus            VerificationStatus   `json:"verification_status"`
+	DeclaredAnnualProductionGrams *float64             `json:"declared_annual_production_grams,omitempty"`
+	TOTPEnabled                   bool                 `json:"totp_enabled"`
+	TOTPPending                   bool                 `json:"totp_pending"` // hay un registro de TOTP sin confirmar
+	CreatedAt                     time.Time            `json:"created_at"`
+	UpdatedAt                     time.Time            `json:"updated_at"`
+	Documents                     []DocumentDescriptor `json:"documents,omitempty"`
+}
+
+// DocumentDescriptor describe un documento del minero sin revelar dónde está guardado.
+type DocumentDescriptor struct {
+	Kind     DocumentKind `json:"kind"`
+	Uploaded bool         `json:"uploaded"`
+	URL      string       `json:"url,omitempty"` // Endpoint de descarga (requiere autorización)
+}
+
+// NewMinerResponse construye el DTO de salida. Los descriptores de documentos
+// solo se incluyen si includeDocuments es true (dueño o revisor).
+func NewMinerResponse(m *Miner, includeDocuments bool) *MinerResponse {
+	resp := &MinerResponse{
+		ID:                            m.ID,
+		UserID:                        m.UserID,
+		FullName:                      m.FullName,
+		LastName:                      m.LastName,
+		Email:                         m.Email,
+		MinerType:                     m.MinerType,
+		VerificationStatus:            m.VerificationStatus,
+		DeclaredAnnualProductionGrams: m.DeclaredAnnualProductionGrams,
+		TOTPEnabled:                   m.TOTPSecret != "",
+		TOTPPending:                   m.TOTPPendingSecret != "",
+		CreatedAt:                     m.CreatedAt,
+		UpdatedAt:                     m.UpdatedAt,
+	}
+	if includeDocuments {
+		for _, kind := range DocumentKindsFor(m.MinerType) {
+			d := DocumentDescriptor{Kind: kind, Uploaded: m.DocumentPath(kind) != ""}
+			if d.Uploaded {
+				d.URL = "/api/v1/miners/" + m.ID.String() + "/documents/" + string(kind)
+			}
+			resp.Documents = append(resp.Documents, d)
+		}
+	}
+	return resp
 }
 
 // MinerTOTPResponse es el DTO para devolver información de TOTP al cliente
@@ -62,7 +209,7 @@ type MinerTOTPResponse struct {
 	ID         uuid.UUID `json:"id"`
 	FullName   string    `json:"full_name"`
 	Email      string    `json:"email"`
-	TOTPSecret string    `json:"totp_secret"` // Solo se devuelve una vez durante el registro
+	TOTPSecret string    `json:"totp_secret"` // Solo se devuelve al emitir el secreto, para ingresarlo a mano
 	QRCodeURL  string    `json:"qr_code_url"` // URL para generar el QR
 }
 
@@ -77,10 +224,25 @@ const (
 	TechnicalToolMaxSize = 50 * Megabyte
 )
 
+// Límites de páginas de los PDF y de dimensiones de las fotos.
+const (
+	RuconMaxPages                = 5
+	SubsistenceOtherMaxPages     = 30
+	ExploitationContractMaxPages = 60
+	EnvironmentalToolMaxPages    = 400
+	TechnicalToolMaxPages        = 300
+
+	PhotoMinSide = 480  // px; por debajo la cédula no es legible
+	PhotoMaxSide = 8000 // px; evita imágenes que expanden a cientos de MB en memoria
+)
+
 // DocumentField define la estructura para validar un campo de documento
 type DocumentField struct {
-	FileHeader  *multipart.FileHeader
-	Required    bool
-	MaxSizeBytes int64
+	FileHeader       *multipart.FileHeader
+	Required         bool
+	MaxSizeBytes     int64
 	AllowedMimeTypes []string
+	MaxPages         int // solo PDF; 0 = sin límite
+	MinImageSide     int // solo imágenes; ancho y alto mínimos en px
+	MaxImageSide     int // solo imágenes; ancho y alto máximos en px
 }
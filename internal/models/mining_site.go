@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/geo"
+)
+
+// MiningSiteKind distingue un área titulada de una zona declarada por un minero de subsistencia.
+type MiningSiteKind string
+
+const (
+	SiteTitled          MiningSiteKind = "titulo"            // Título minero o contrato de concesión
+	SiteSubsistenceZone MiningSiteKind = "zona_subsistencia" // Zona de extracción declarada
+)
+
+// MiningSite es el lugar de extracción del que proviene el oro de un minero.
+// Se ubica con un punto y, opcionalmente, el polígono del área.
+type MiningSite struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	MinerID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"miner_id"`
+	Kind         MiningSiteKind `gorm:"type:varchar(32);not null" json:"kind"`
+	TitleNumber  *string        `gorm:"uniqueIndex" json:"title_number,omitempty"` // Código del título o concesión (solo titulares)
+	Name         string         `json:"name,omitempty"`
+	Municipality string         `gorm:"not null" json:"municipality"`
+	Department   string         `gorm:"not null" json:"department"`
+	Mineral      string         `gorm:"not null;default:'oro'" json:"mineral"`
+	Latitude     float64        `gorm:"not null" json:"latitude"`
+	Longitude    float64        `gorm:"not null" json:"longitude"`
+	Polygon      []geo.Point    `gorm:"type:jsonb;serializer:json" json:"polygon,omitempty"`
+}
+
+// DTO de entrada para registrar un sitio. Se envía el punto, el polígono o ambos;
+// con solo el polígono el punto se calcula como su centroide.
+type CreateMiningSiteRequest struct {
+	TitleNumber  string      `json:"title_number" binding:"omitempty,max=50"`
+	Name         string      `json:"name" binding:"omitempty,max=200"`
+	Municipality string      `json:"municipality" binding:"required,max=100"`
+	Department   string      `json:"department" binding:"required,max=100"`
+	Mineral      string      `json:"mineral" binding:"omitempty,max=50"`
+	Latitude     *float64    `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude    *float64    `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Polygon      []geo.Point `json:"polygon" binding:"omitempty,min=3,max=500"`
+}
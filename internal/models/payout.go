@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/payout"
+)
+
+// PayoutStatus es el estado de un pago al minero.
+//
+//	pending -> submitted -> succeeded | failed
+//	pending -> pending (reintento) | succeeded | failed
+//	pending -> unknown -> submitted | succeeded | failed
+type PayoutStatus string
+
+const (
+	PayoutPending   PayoutStatus = "pending"   // Fondos retenidos; por enviar o esperando reintento
+	PayoutSubmitted PayoutStatus = "submitted" // El proveedor lo aceptó y falta la confirmación
+	PayoutUnknown   PayoutStatus = "unknown"   // No se sabe si el proveedor lo recibió; los fondos siguen retenidos
+	PayoutSucceeded PayoutStatus = "succeeded" // Pagado (final)
+	PayoutFailed    PayoutStatus = "failed"    // Rechazado o sin más reintentos; fondos liberados (final)
+)
+
+// Payout es un envío de dinero desde la billetera del minero a su cuenta.
+type Payout struct {
+	ID            uuid.UUID          `gorm:"type:uuid;primaryKey" json:"id"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	MinerID       uuid.UUID          `gorm:"type:uuid;not null;index" json:"miner_id"`
+	AmountCOP     int64              `gorm:"not null;check:chk_payouts_amount,amount_cop > 0" json:"amount_cop"`
+	Method        payout.Method      `gorm:"type:varchar(16);not null" json:"method"`
+	Provider      string             `gorm:"type:varchar(32);not null;index:idx_payouts_provider_ref" json:"provider"`
+	ProviderRef   *string            `gorm:"index:idx_payouts_provider_ref" json:"provider_ref,omitempty"`
+	Destination   payout.Destination `gorm:"type:jsonb;serializer:json;not null" json:"destination"`
+	Status        PayoutStatus       `gorm:"type:varchar(16);not null;index" json:"status"`
+	Attempts      int                `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time         `gorm:"index" json:"next_attempt_at,omitempty"`
+	LastError     string             `json:"last_error,omitempty"`
+	CompletedAt   *time.Time         `json:"completed_at,omitempty"`
+}
+
+// IsFinal indica si el pago ya no puede cambiar de estado.
+func (p *Payout) IsFinal() bool {
+	return p.Status == PayoutSucceeded || p.Status == PayoutFailed
+}
+
+// DTO de entrada para solicitar un pago. El minero lo autoriza con su código TOTP.
+type CreatePayoutRequest struct {
+	AmountCOP          int64         `json:"amount_cop" binding:"required,gt=0"`
+	Method             payout.Method `json:"method" binding:"required,oneof=bank_transfer nequi daviplata"`
+	HolderName         string        `json:"holder_name" binding:"required,max=200"`
+	HolderDocumentType string        `json:"holder_document_type" binding:"required,oneof=CC CE NIT PPT"`
+	HolderDocument     string        `json:"holder_document" binding:"required,numeric,max=20"`
+	BankCode           string        `json:"bank_code" binding:"omitempty,max=10"`
+	AccountType        string        `json:"account_type" binding:"omitempty,oneof=ahorros corriente"`
+	AccountNumber      string        `json:"account_number" binding:"omitempty,numeric,max=20"`
+	Phone              string        `json:"phone" binding:"omitempty,numeric,len=10"`
+	TOTPCode           string        `json:"totp_code" binding:"required,len=6,numeric"`
+}
+
+// Destination arma la cuenta de destino a partir de la solicitud.
+func (r *CreatePayoutRequest) Destination() payout.Destination {
+	return payout.Destination{
+		Method:             r.Method,
+		HolderName:         r.HolderName,
+		HolderDocumentType: r.HolderDocumentType,
+		HolderDocument:     r.HolderDocument,
+		BankCode:           r.BankCode,
+		AccountType:        r.AccountType,
+		AccountNumber:      r.AccountNumber,
+		Phone:              r.Phone,
+	}
+}
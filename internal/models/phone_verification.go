@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhoneVerification es un código de un solo uso enviado por SMS para verificar el teléfono.
+// El código se guarda con hash (bcrypt), expira y admite un número limitado de intentos.
+type PhoneVerification struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	PhoneNumber string     `gorm:"not null;index" json:"phone_number"`
+	CodeHash    string     `gorm:"not null" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	ConsumedAt  *time.Time `json:"consumed_at,omitempty"`
+}
+
+// Restricciones del flujo de verificación por SMS
+const (
+	PhoneCodeLength      = 6
+	PhoneCodeTTL         = 10 * time.Minute
+	PhoneCodeMaxAttempts = 5
+	PhoneCodeResendDelay = time.Minute
+	// Tope de códigos emitidos por usuario y por número dentro de la ventana,
+	// para que los intentos por código no se multipliquen pidiendo códigos nuevos
+	PhoneCodeWindow       = time.Hour
+	PhoneCodeMaxPerWindow = 5
+)
+
+// DTO de entrada para solicitar un código por SMS
+type PhoneChallengeRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+}
+
+// DTO de entrada para confirmar el código recibido
+type PhoneVerifyRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/pricing"
+)
+
+// ReferencePrice es el precio de referencia de un día para una serie, en pesos
+// por gramo de oro fino. Si una fuente corrige el dato del día, se sobrescribe.
+type ReferencePrice struct {
+	ID              uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Date            time.Time    `gorm:"type:date;not null;uniqueIndex:idx_reference_prices_day" json:"date"`
+	Kind            pricing.Kind `gorm:"type:varchar(32);not null;uniqueIndex:idx_reference_prices_day" json:"kind"`
+	PricePerGramCOP int64        `gorm:"not null" json:"price_per_gram_cop"`
+	Source          string       `gorm:"type:varchar(64);not null" json:"source"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// SaleUsage son los gramos vendidos por un minero en el mes y el año en curso.
+type SaleUsage struct {
+	MonthGrams float64
+	YearGrams  float64
+}
+
+// QuotaResponse es el DTO de GET /miners/:id/quota. Para titulares no hay tope
+// mensual y el anual es la producción declarada en el contrato de explotación.
+type QuotaResponse struct {
+	MinerID             uuid.UUID `json:"miner_id"`
+	MinerType           MinerType `json:"miner_type"`
+	Month               string    `json:"month"` // "2006-01"
+	Year                int       `json:"year"`
+	MonthlyCapGrams     *float64  `json:"monthly_cap_grams,omitempty"`
+	UsedMonthGrams      float64   `json:"used_month_grams"`
+	RemainingMonthGrams *float64  `json:"remaining_month_grams,omitempty"`
+	AnnualCapGrams      *float64  `json:"annual_cap_grams,omitempty"`
+	UsedYearGrams       float64   `json:"used_year_grams"`
+	RemainingYearGrams  *float64  `json:"remaining_year_grams,omitempty"`
+}
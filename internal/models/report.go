@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlatformReporter identifica los reportes que Batea presenta en nombre propio.
+// Los de un comercializador usan BuyerReporter.
+const PlatformReporter = "batea"
+
+func BuyerReporter(buyerID uuid.UUID) string {
+	return "buyer:" + buyerID.String()
+}
+
+// RegulatoryReport es un reporte de compras a mineros de subsistencia ya
+// presentado a la autoridad minera. Guarda los filtros con que se generó, los
+// totales y los archivos entregados. Es inmutable.
+type RegulatoryReport struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime" json:"submitted_at"`
+	SubmittedBy        uuid.UUID  `gorm:"type:uuid;not null" json:"submitted_by"`
+	Reporter           string     `gorm:"type:varchar(64);not null;index" json:"reporter"`
+	BuyerID            *uuid.UUID `gorm:"type:uuid;index" json:"buyer_id,omitempty"`
+	Municipality       string     `json:"municipality,omitempty"`
+	PeriodStart        time.Time  `gorm:"type:date;not null" json:"period_start"`
+	PeriodEnd          time.Time  `gorm:"type:date;not null" json:"period_end"`
+	SaleCount          int        `gorm:"not null" json:"sale_count"`
+	TotalWeightGrams   float64    `gorm:"type:numeric(14,3);not null" json:"total_weight_grams"`
+	TotalFineGoldGrams float64    `gorm:"type:numeric(14,3);not null" json:"total_fine_gold_grams"`
+	TotalCOP           int64      `gorm:"not null" json:"total_cop"`
+	CSVKey             string     `gorm:"column:csv_key;not null" json:"-"`
+	CSVSHA256          string     `gorm:"column:csv_sha256;type:char(64);not null" json:"csv_sha256"`
+	FixedWidthKey      string     `gorm:"not null" json:"-"`
+	FixedWidthSHA256   string     `gorm:"column:fixed_width_sha256;type:char(64);not null" json:"fixed_width_sha256"`
+}
+
+// RegulatoryReportSale registra qué ventas incluyó cada reporte. El índice
+// único por declarante impide que una venta se reporte dos veces.
+type RegulatoryReportSale struct {
+	ReportID uuid.UUID `gorm:"type:uuid;primaryKey" json:"report_id"`
+	SaleID   uuid.UUID `gorm:"type:uuid;primaryKey;uniqueIndex:idx_report_sales_reporter_sale,priority:2" json:"sale_id"`
+	Reporter string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_report_sales_reporter_sale,priority:1" json:"reporter"`
+}
+
+// RegulatoryReportFilter selecciona las ventas de un reporte. From y To son
+// instantes: se incluyen las ventas con From <= created_at < To.
+type RegulatoryReportFilter struct {
+	Reporter     string
+	From         time.Time
+	To           time.Time
+	BuyerID      *uuid.UUID
+	Municipality string // municipio del sitio de origen; vacío = todos
+}
+
+// DTO de entrada para generar o presentar un reporte. Las fechas son días
+// (AAAA-MM-DD, inclusive) en hora de Colombia.
+type RegulatoryReportRequest struct {
+	From         string `json:"from" form:"from" binding:"required,datetime=2006-01-02"`
+	To           string `json:"to" form:"to" binding:"required,datetime=2006-01-02"`
+	BuyerID      string `json:"buyer_id" form:"buyer_id" binding:"omitempty,uuid"`
+	Municipality string `json:"municipality" form:"municipality" binding:"omitempty,max=100"`
+}
@@ -0,0 +1,79 @@
+package models
+
+// Role agrupa permisos y se asigna a usuarios (tabla intermedia user_roles).
+type Role struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"unique;not null" json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
+}
+
+// Permission es una acción autorizable sobre la API (por ejemplo "miners:list").
+type Permission struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"unique;not null" json:"name"`
+	Description string `json:"description"`
+}
+
+// Roles del sistema
+const (
+	RoleMiner    = "miner"    // Minero (titular o de subsistencia)
+	RoleBuyer    = "buyer"    // Comercializador que compra oro
+	RoleReviewer = "reviewer" // Personal de back-office que revisa documentos
+	RoleAdmin    = "admin"    // Administrador de la plataforma
+)
+
+// Permisos del sistema
+const (
+	PermMinersRegister   = "miners:register"
+	PermMinersList       = "miners:list"
+	PermMinersReadAny    = "miners:read_any"
+	PermDocumentsReadAny = "documents:read_any"
+	PermMinersReview     = "miners:review"
+	PermSalesCreate      = "sales:create"
+	PermSalesReadAny     = "sales:read_any"
+	PermSalesSettle      = "sales:settle"
+	PermUsersManageRoles = "users:manage_roles"
+	PermBuyersReview     = "buyers:review"
+	PermWalletsReadAny   = "wallets:read_any"
+	PermReportsManage    = "reports:manage"
+	PermLedgerRead       = "ledger:read"
+)
+
+// PermissionDescriptions describe cada permiso sembrado en la base de datos.
+var PermissionDescriptions = map[string]string{
+	PermMinersRegister:   "Registrarse como minero",
+	PermMinersList:       "Listar todos los mineros",
+	PermMinersReadAny:    "Ver el perfil de cualquier minero",
+	PermDocumentsReadAny: "Ver los documentos de cualquier minero",
+	PermMinersReview:     "Revisar y aprobar registros de mineros (KYC)",
+	PermSalesCreate:      "Registrar compras de oro",
+	PermSalesReadAny:     "Ver cualquier venta de oro",
+	PermSalesSettle:      "Registrar que se recibió el pago de una venta por parte del comercializador",
+	PermUsersManageRoles: "Asignar y quitar roles a usuarios",
+	PermBuyersReview:     "Revisar y aprobar comercializadores y sus puntos de compra",
+	PermWalletsReadAny:   "Ver la billetera y el extracto de cualquier minero o comercializador",
+	PermReportsManage:    "Generar y presentar los reportes regulatorios de Batea y consultar los de cualquier comercializador",
+	PermLedgerRead:       "Consultar el historial del libro mayor de ventas, mineros y comercializadores",
+}
+
+// DefaultRoles define los roles sembrados por db.InitPostgres y sus permisos.
+var DefaultRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleMiner, "Minero titular o de subsistencia", []string{PermMinersRegister}},
+	{RoleBuyer, "Comercializador de oro", []string{PermSalesCreate}},
+	{RoleReviewer, "Revisor de documentos (back-office)", []string{PermMinersReadAny, PermDocumentsReadAny, PermMinersReview, PermSalesReadAny, PermLedgerRead}},
+	{RoleAdmin, "Administrador de la plataforma", []string{
+		PermMinersRegister, PermMinersList, PermMinersReadAny, PermDocumentsReadAny,
+		PermMinersReview, PermSalesCreate, PermSalesReadAny, PermUsersManageRoles, PermBuyersReview,
+		PermWalletsReadAny, PermReportsManage, PermLedgerRead, PermSalesSettle,
+	}},
+}
+
+// DTO de entrada para asignar un rol a un usuario
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=miner buyer reviewer admin"`
+}
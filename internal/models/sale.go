@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sale es una compra de oro registrada por un comercializador y autorizada por
+// el minero con su código TOTP. Es inmutable: no se actualiza ni se borra.
+type Sale struct {
+	ID              uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	MinerID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"miner_id"`
+	Miner           *Miner     `gorm:"foreignKey:MinerID" json:"-"`
+	BuyerUserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"buyer_user_id"`
+	BuyerID         *uuid.UUID `gorm:"type:uuid;index" json:"buyer_id,omitempty"` // nil en ventas anteriores al registro de comercializadores
+	PurchasePointID *uuid.UUID `gorm:"type:uuid" json:"purchase_point_id,omitempty"`
+	OriginSiteID    *uuid.UUID `gorm:"type:uuid;index" json:"origin_site_id,omitempty"` // nil en ventas anteriores al registro de sitios
+	WeightGrams     float64    `gorm:"type:numeric(12,3);not null" json:"weight_grams"`
+	Purity          float64    `gorm:"type:numeric(5,4);not null" json:"purity"`           // ley como fracción (0.9999 = 24k)
+	FineGoldGrams   float64    `gorm:"type:numeric(12,3);not null" json:"fine_gold_grams"` // peso x ley
+	PricePerGramCOP int64      `gorm:"not null" json:"price_per_gram_cop"`
+	TotalCOP        int64      `gorm:"not null" json:"total_cop"`
+	PointOfSale     string     `gorm:"not null" json:"point_of_sale"` // copia del nombre y dirección del punto al momento de la venta
+
+	// Comparación con el precio de referencia vigente al momento de la venta (nil si no había)
+	ReferencePricePerGramCOP *int64     `json:"reference_price_per_gram_cop,omitempty"` // por gramo de oro fino
+	ReferencePriceKind       *string    `gorm:"type:varchar(32)" json:"reference_price_kind,omitempty"`
+	ReferencePriceDate       *time.Time `gorm:"type:date" json:"reference_price_date,omitempty"`
+	BelowReference           bool       `gorm:"not null;default:false;index" json:"below_reference"` // pagó menos del umbral permitido
+}
+
+// DTO de entrada para registrar una compra
+type CreateSaleRequest struct {
+	MinerID         uuid.UUID `json:"miner_id" binding:"required"`
+	WeightGrams     float64   `json:"weight_grams" binding:"required,gt=0,lte=100000"`
+	Purity          float64   `json:"purity" binding:"required,gt=0,lte=1"`
+	PricePerGramCOP int64     `json:"price_per_gram_cop" binding:"required,gt=0"`
+	PurchasePointID uuid.UUID `json:"purchase_point_id" binding:"required"`
+	OriginSiteID    uuid.UUID `json:"origin_site_id" binding:"required"`
+	TOTPCode        string    `json:"totp_code" binding:"required,len=6,numeric"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TOTPRecoveryCodeCount es cuántos códigos de recuperación recibe el minero al
+// confirmar su TOTP.
+const TOTPRecoveryCodeCount = 10
+
+// TOTPGuard protege el TOTP de cada minero: guarda el último paso de tiempo
+// aceptado, para rechazar un código ya usado, y los fallos recientes de cada
+// usuario que envía códigos, para bloquearle temporalmente los intentos de
+// adivinarlo. El bloqueo es por usuario: un comprador que envía códigos errados
+// no bloquea los retiros ni la recuperación del propio minero.
+type TOTPGuard struct {
+	MinerID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UpdatedAt time.Time
+	LastStep  *int64                            // contador TOTP (Unix / periodo) del último código aceptado
+	Callers   map[uuid.UUID]*TOTPCallerAttempts `gorm:"type:jsonb;serializer:json"` // por ID del usuario que envía el código
+}
+
+// TOTPCallerAttempts son los fallos y el bloqueo de un usuario sobre el TOTP de un minero.
+type TOTPCallerAttempts struct {
+	Failures    []time.Time `json:"failures,omitempty"` // fallos dentro de la ventana vigente
+	LockedUntil *time.Time  `json:"locked_until,omitempty"`
+}
+
+// TOTPRecoveryCode es un código de un solo uso con el que el minero prueba su
+// identidad para registrar el TOTP en otro teléfono. Solo se guarda el
+// HMAC-SHA256 con la llave del servidor (TOTP_RECOVERY_SECRET): los códigos son
+// aleatorios de 50 bits y sin la llave no se pueden probar fuera de línea.
+type TOTPRecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CreatedAt time.Time
+	MinerID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_totp_recovery_miner_hash"`
+	CodeHash  string    `gorm:"type:char(64);not null;uniqueIndex:idx_totp_recovery_miner_hash"`
+	UsedAt    *time.Time
+}
+
+// ConfirmTOTPRequest confirma el registro pendiente con el primer código de la aplicación.
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// ReenrollTOTPRequest pide un secreto TOTP nuevo. La identidad se prueba con el
+// código SMS de /auth/phone/challenge o con un código de recuperación.
+type ReenrollTOTPRequest struct {
+	PhoneCode    string `json:"phone_code" binding:"required_without=RecoveryCode,omitempty,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=PhoneCode,omitempty,max=32"`
+}
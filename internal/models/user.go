@@ -21,6 +21,41 @@ type User struct {
 
 	// Estado de verificación (por SMS/Email)
 	IsVerified   bool           `gorm:"default:false" json:"is_verified"`
+
+	// Roles asignados (miner, buyer, reviewer, admin)
+	Roles        []Role         `gorm:"many2many:user_roles;" json:"roles,omitempty"`
+}
+
+// HasRole indica si el usuario tiene el rol indicado (requiere Roles precargados).
+func (u *User) HasRole(name string) bool {
+	for _, r := range u.Roles {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission indica si alguno de los roles del usuario otorga el permiso
+// (requiere Roles.Permissions precargados).
+func (u *User) HasPermission(name string) bool {
+	for _, r := range u.Roles {
+		for _, p := range r.Permissions {
+			if p.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RoleNames devuelve los nombres de los roles del usuario.
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, r := range u.Roles {
+		names = append(names, r.Name)
+	}
+	return names
 }
 
 // DTO de entrada para registrar usuario
@@ -28,10 +63,9 @@ type UserRegisterRequest struct {
 	PhoneNumber string `json:"phone_number" binding:"required,e164"`
 	// Para flujo local con contraseña
 	Password    string `json:"password,omitempty" binding:"omitempty,min=6,max=32"`
-	// Para flujo con Identity Platform (opcional)
-	FirebaseUID string `json:"firebase_uid,omitempty"`
-	// Permite marcar verificado si viene de un proveedor externo
-	IsVerified  bool   `json:"is_verified,omitempty"`
+	// Para flujo con Identity Platform: el UID y la verificación del
+	// teléfono se derivan del ID token firmado, nunca del cliente
+	IDToken     string `json:"id_token,omitempty"`
 }
 
 // DTO de salida
@@ -39,5 +73,6 @@ type UserResponse struct {
 	ID          uuid.UUID `json:"id"`
 	PhoneNumber string    `json:"phone_number"`
 	IsVerified  bool      `json:"is_verified"`
+	Roles       []string  `json:"roles,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
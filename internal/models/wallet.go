@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountOwnerType indica a quién pertenece una cuenta.
+type AccountOwnerType string
+
+const (
+	OwnerMiner  AccountOwnerType = "miner"
+	OwnerBuyer  AccountOwnerType = "buyer"
+	OwnerSystem AccountOwnerType = "system" // Cuentas de la plataforma (OwnerID = uuid.Nil)
+)
+
+// AccountKind distingue las cuentas de un mismo dueño.
+type AccountKind string
+
+const (
+	AccountWallet     AccountKind = "wallet"     // Saldo disponible (minero) o por pagar (comercializador, negativo)
+	AccountPending    AccountKind = "pending"    // Minero: ventas que el comercializador aún no ha pagado
+	AccountHold       AccountKind = "hold"       // Fondos retenidos, por ejemplo mientras se procesa un pago
+	AccountSettlement AccountKind = "settlement" // Sistema: dinero recibido de los comercializadores
+	AccountPayouts    AccountKind = "payouts"    // Sistema: dinero enviado a los mineros
+)
+
+// EntryDirection es el lado del asiento. El saldo de una cuenta es créditos menos débitos.
+type EntryDirection string
+
+const (
+	Debit  EntryDirection = "debit"
+	Credit EntryDirection = "credit"
+)
+
+// JournalKind clasifica cada transacción contable.
+type JournalKind string
+
+const (
+	JournalSale       JournalKind = "sale"       // El comercializador queda debiendo al minero: por cobrar
+	JournalSettlement JournalKind = "settlement" // Se recibió el pago del comercializador: por cobrar -> disponible
+	JournalHold       JournalKind = "hold"       // Disponible -> retenido
+	JournalRelease    JournalKind = "release"    // Retenido -> disponible
+	JournalPayout     JournalKind = "payout"     // Retenido -> pagado al minero
+)
+
+// AccountRef identifica una cuenta por su dueño y tipo; la cuenta se crea al primer asiento.
+type AccountRef struct {
+	OwnerType AccountOwnerType
+	OwnerID   uuid.UUID
+	Kind      AccountKind
+}
+
+// AllowsNegative indica si la cuenta puede quedar en saldo negativo. Solo la
+// cuenta del comercializador (deuda con los mineros) y las del sistema pueden.
+func (r AccountRef) AllowsNegative() bool {
+	return r.OwnerType == OwnerSystem || (r.OwnerType == OwnerBuyer && r.Kind == AccountWallet)
+}
+
+// SystemAccount devuelve la referencia a una cuenta de la plataforma.
+func SystemAccount(kind AccountKind) AccountRef {
+	return AccountRef{OwnerType: OwnerSystem, OwnerID: uuid.Nil, Kind: kind}
+}
+
+// Account es una cuenta contable en pesos. No guarda saldo: se deriva del diario.
+type Account struct {
+	ID            uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt     time.Time        `json:"created_at"`
+	OwnerType     AccountOwnerType `gorm:"type:varchar(16);not null;uniqueIndex:idx_accounts_owner" json:"owner_type"`
+	OwnerID       uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_accounts_owner" json:"owner_id"`
+	Kind          AccountKind      `gorm:"type:varchar(16);not null;uniqueIndex:idx_accounts_owner" json:"kind"`
+	Currency      string           `gorm:"type:char(3);not null;default:'COP'" json:"currency"`
+	AllowNegative bool             `gorm:"not null" json:"allow_negative"`
+}
+
+// JournalTransaction agrupa asientos que suman cero. La llave de idempotencia
+// garantiza que reintentar la misma operación no la contabilice dos veces.
+type JournalTransaction struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	CreatedAt      time.Time      `gorm:"not null" json:"created_at"`
+	IdempotencyKey string         `gorm:"not null;uniqueIndex" json:"idempotency_key"`
+	Kind           JournalKind    `gorm:"type:varchar(16);not null" json:"kind"`
+	Reference      *uuid.UUID     `gorm:"type:uuid;index" json:"reference,omitempty"` // Venta o pago que origina el movimiento
+	Description    string         `json:"description"`
+	Entries        []JournalEntry `gorm:"foreignKey:TransactionID" json:"entries,omitempty"`
+}
+
+// JournalEntry es un débito o crédito a una cuenta. AmountCOP siempre es positivo.
+type JournalEntry struct {
+	ID            int64          `gorm:"primaryKey" json:"id"`
+	TransactionID uuid.UUID      `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	AccountID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"account_id"`
+	Direction     EntryDirection `gorm:"type:varchar(6);not null" json:"direction"`
+	AmountCOP     int64          `gorm:"not null;check:chk_journal_entries_amount,amount_cop > 0" json:"amount_cop"`
+	Account       AccountRef     `gorm:"-" json:"-"` // Cuenta a resolver al contabilizar
+}
+
+// StatementLine es una línea del extracto de una cuenta, con el saldo resultante.
+type StatementLine struct {
+	EntryID       int64          `json:"entry_id"`
+	TransactionID uuid.UUID      `json:"transaction_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Kind          JournalKind    `json:"kind"`
+	Reference     *uuid.UUID     `json:"reference,omitempty"`
+	Description   string         `json:"description"`
+	Direction     EntryDirection `json:"direction"`
+	AmountCOP     int64          `json:"amount_cop"`
+	BalanceCOP    int64          `json:"balance_cop"`
+}
+
+// WalletResponse es el saldo y una página del extracto de la billetera.
+type WalletResponse struct {
+	Currency     string          `json:"currency"`
+	AvailableCOP int64           `json:"available_cop"`
+	PendingCOP   int64           `json:"pending_cop"`
+	HeldCOP      int64           `json:"held_cop"`
+	Page         int             `json:"page"`
+	Limit        int             `json:"limit"`
+	TotalRows    int64           `json:"total_rows"`
+	TotalPages   int             `json:"total_pages"`
+	Statement    []StatementLine `json:"statement"`
+}
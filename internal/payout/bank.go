@@ -0,0 +1,15 @@
+package payout
+
+// NewBankTransferProvider crea el adaptador de transferencias interbancarias
+// (ACH) a cuentas de ahorros o corrientes en Colombia. BankCode es el código
+// del banco de destino en la red ACH.
+func NewBankTransferProvider(cfg HTTPConfig) PayoutProvider {
+	return newHTTPProvider("bank", []Method{MethodBankTransfer}, cfg, func(d Destination) map[string]string {
+		return map[string]string{
+			"type":           "bank_account",
+			"bank_code":      d.BankCode,
+			"account_type":   d.AccountType,
+			"account_number": d.AccountNumber,
+		}
+	})
+}
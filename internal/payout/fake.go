@@ -0,0 +1,111 @@
+package payout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider simula un proveedor dentro del proceso, para desarrollo y
+// pruebas. Con AutoComplete los pagos se aprueban al enviarlos; sin él quedan
+// en proceso hasta que se llame Complete o llegue un webhook.
+type FakeProvider struct {
+	AutoComplete bool
+
+	mu       sync.Mutex
+	payouts  map[string]*Result // por ProviderRef
+	byPayout map[string]string  // PayoutID -> ProviderRef, para respetar la idempotencia
+	failNext []error
+}
+
+func NewFakeProvider(autoComplete bool) *FakeProvider {
+	return &FakeProvider{
+		AutoComplete: autoComplete,
+		payouts:      make(map[string]*Result),
+		byPayout:     make(map[string]string),
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) Supports(Method) bool {
+	return true
+}
+
+// FailNext hace que las próximas llamadas a Initiate fallen con los errores dados, en orden.
+func (f *FakeProvider) FailNext(errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = append(f.failNext, errs...)
+}
+
+func (f *FakeProvider) Initiate(_ context.Context, req Request) (*Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.failNext) > 0 {
+		err := f.failNext[0]
+		f.failNext = f.failNext[1:]
+		return nil, err
+	}
+	if ref, ok := f.byPayout[req.PayoutID]; ok {
+		r := *f.payouts[ref]
+		return &r, nil
+	}
+
+	r := &Result{ProviderRef: "fake_" + uuid.NewString(), Status: StatusProcessing}
+	if f.AutoComplete {
+		r.Status = StatusSucceeded
+	}
+	f.payouts[r.ProviderRef] = r
+	f.byPayout[req.PayoutID] = r.ProviderRef
+	out := *r
+	return &out, nil
+}
+
+func (f *FakeProvider) Status(_ context.Context, providerRef string) (*Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.payouts[providerRef]
+	if !ok {
+		return nil, fmt.Errorf("%w: pago %s desconocido", ErrRejected, providerRef)
+	}
+	out := *r
+	return &out, nil
+}
+
+// Complete fija el resultado final de un pago en proceso.
+func (f *FakeProvider) Complete(providerRef string, status Status, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.payouts[providerRef]
+	if !ok {
+		return fmt.Errorf("pago %s desconocido", providerRef)
+	}
+	r.Status = status
+	r.Reason = reason
+	return nil
+}
+
+// HandleWebhook acepta {"id","status","reason"} sin firma: el proveedor falso
+// solo corre dentro del proceso.
+func (f *FakeProvider) HandleWebhook(_ http.Header, body []byte) (*WebhookEvent, error) {
+	var state payoutState
+	if err := json.Unmarshal(body, &state); err != nil {
+		return nil, fmt.Errorf("webhook de prueba mal formado: %w", err)
+	}
+	status, err := parseStatus(state.Status)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Complete(state.ID, status, state.Reason); err != nil {
+		return nil, err
+	}
+	return &WebhookEvent{ProviderRef: state.ID, Status: status, Reason: state.Reason}, nil
+}
@@ -0,0 +1,191 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SignatureHeader lleva el HMAC-SHA256 (hex) del cuerpo de cada webhook.
+const SignatureHeader = "X-Signature"
+
+// maxResponseSize limita lo que se lee de una respuesta del proveedor.
+const maxResponseSize = 1 << 20
+
+// HTTPConfig son los datos de conexión con el agregador de pagos.
+type HTTPConfig struct {
+	BaseURL       string
+	APIKey        string
+	WebhookSecret string
+	Timeout       time.Duration
+}
+
+// httpProvider implementa el protocolo común de los adaptadores: órdenes JSON
+// con llave de idempotencia y webhooks firmados con HMAC. Los adaptadores solo
+// cambian los medios de pago que atienden y cómo describen la cuenta de destino.
+type httpProvider struct {
+	name        string
+	methods     []Method
+	cfg         HTTPConfig
+	client      *http.Client
+	destination func(Destination) map[string]string
+}
+
+func newHTTPProvider(name string, methods []Method, cfg HTTPConfig, destination func(Destination) map[string]string) *httpProvider {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &httpProvider{
+		name:        name,
+		methods:     methods,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: timeout},
+		destination: destination,
+	}
+}
+
+func (p *httpProvider) Name() string {
+	return p.name
+}
+
+func (p *httpProvider) Supports(method Method) bool {
+	for _, m := range p.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+type payoutOrder struct {
+	Reference   string            `json:"reference"`
+	Amount      int64             `json:"amount"`
+	Currency    string            `json:"currency"`
+	Description string            `json:"description,omitempty"`
+	Beneficiary map[string]string `json:"beneficiary"`
+}
+
+type payoutState struct {
+	ID        string `json:"id"`
+	Reference string `json:"reference,omitempty"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+func (p *httpProvider) Initiate(ctx context.Context, req Request) (*Result, error) {
+	beneficiary := p.destination(req.Destination)
+	beneficiary["name"] = req.Destination.HolderName
+	beneficiary["document_type"] = req.Destination.HolderDocumentType
+	beneficiary["document_number"] = req.Destination.HolderDocument
+
+	body, err := json.Marshal(payoutOrder{
+		Reference:   req.PayoutID,
+		Amount:      req.AmountCOP,
+		Currency:    "COP",
+		Description: req.Description,
+		Beneficiary: beneficiary,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+"/payouts", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", req.PayoutID)
+	return p.do(httpReq)
+}
+
+func (p *httpProvider) Status(ctx context.Context, providerRef string) (*Result, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.BaseURL+"/payouts/"+url.PathEscape(providerRef), nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.do(httpReq)
+}
+
+// HandleWebhook verifica la firma HMAC del cuerpo antes de interpretarlo.
+func (p *httpProvider) HandleWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	got, err := hex.DecodeString(header.Get(SignatureHeader))
+	if err != nil || p.cfg.WebhookSecret == "" {
+		return nil, ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(p.cfg.WebhookSecret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var state payoutState
+	if err := json.Unmarshal(body, &state); err != nil {
+		return nil, fmt.Errorf("webhook de %s mal formado: %w", p.name, err)
+	}
+	status, err := parseStatus(state.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookEvent{ProviderRef: state.ID, PayoutID: state.Reference, Status: status, Reason: state.Reason}, nil
+}
+
+// do envía la petición y clasifica los errores: red, 429 y 5xx son temporales;
+// 409 es un conflicto de idempotencia y el resto de 4xx es un rechazo.
+func (p *httpProvider) do(req *http.Request) (*Result, error) {
+	req.Header.Set("Authorization", "Bearer "+p.cfg.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrTemporary, p.name, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrTemporary, p.name, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, fmt.Errorf("%w: %s respondió %d", ErrTemporary, p.name, resp.StatusCode)
+	case resp.StatusCode == http.StatusConflict:
+		return nil, fmt.Errorf("%w: %s respondió %d: %s", ErrConflict, p.name, resp.StatusCode, strings.TrimSpace(string(body)))
+	case resp.StatusCode >= 400:
+		return nil, fmt.Errorf("%w: %s respondió %d: %s", ErrRejected, p.name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var state payoutState
+	if err := json.Unmarshal(body, &state); err != nil {
+		return nil, fmt.Errorf("%w: respuesta de %s mal formada: %v", ErrTemporary, p.name, err)
+	}
+	status, err := parseStatus(state.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{ProviderRef: state.ID, Status: status, Reason: state.Reason}, nil
+}
+
+// parseStatus traduce los estados del proveedor a los tres que maneja el backend.
+func parseStatus(s string) (Status, error) {
+	switch strings.ToLower(s) {
+	case "pending", "processing", "accepted", "in_progress":
+		return StatusProcessing, nil
+	case "succeeded", "completed", "paid":
+		return StatusSucceeded, nil
+	case "failed", "rejected", "returned", "reversed", "cancelled":
+		return StatusFailed, nil
+	default:
+		return "", fmt.Errorf("%w: '%s'", ErrUnknownPayoutStatus, s)
+	}
+}
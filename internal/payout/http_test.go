@@ -0,0 +1,127 @@
+package payout
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRequest() Request {
+	return Request{
+		PayoutID:  "3b0c6a52-7f8e-4c0c-9d56-5b7a0a1f2e11",
+		AmountCOP: 250_000,
+		Destination: Destination{
+			Method: MethodNequi, HolderName: "Ana Mina", HolderDocumentType: "CC",
+			HolderDocument: "1032456789", Phone: "3001234567",
+		},
+	}
+}
+
+func TestHTTPProviderMapsResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    error
+		wantStatus Status
+	}{
+		{name: "aceptado", status: http.StatusCreated, body: `{"id":"po_1","status":"accepted"}`, wantStatus: StatusProcessing},
+		{name: "pagado", status: http.StatusOK, body: `{"id":"po_1","status":"paid"}`, wantStatus: StatusSucceeded},
+		{name: "devuelto", status: http.StatusOK, body: `{"id":"po_1","status":"returned","reason":"cuenta cerrada"}`, wantStatus: StatusFailed},
+		{name: "límite de peticiones", status: http.StatusTooManyRequests, wantErr: ErrTemporary},
+		{name: "error del proveedor", status: http.StatusInternalServerError, wantErr: ErrTemporary},
+		{name: "servicio no disponible", status: http.StatusServiceUnavailable, wantErr: ErrTemporary},
+		{name: "solicitud inválida", status: http.StatusBadRequest, body: `{"error":"cuenta inválida"}`, wantErr: ErrRejected},
+		{name: "no autorizado", status: http.StatusUnauthorized, wantErr: ErrRejected},
+		{name: "orden repetida", status: http.StatusConflict, body: `{"error":"idempotency key reused"}`, wantErr: ErrConflict},
+		{name: "respuesta mal formada", status: http.StatusOK, body: `<html>`, wantErr: ErrTemporary},
+		{name: "estado desconocido", status: http.StatusOK, body: `{"id":"po_1","status":"on_hold"}`, wantErr: ErrUnknownPayoutStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Idempotency-Key") != testRequest().PayoutID {
+					t.Errorf("Idempotency-Key = %q", r.Header.Get("Idempotency-Key"))
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			p := NewMobileWalletProvider(HTTPConfig{BaseURL: srv.URL, APIKey: "llave"})
+			result, err := p.Initiate(context.Background(), testRequest())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Initiate = %v, se esperaba %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Initiate: %v", err)
+			}
+			if result.Status != tt.wantStatus || result.ProviderRef != "po_1" {
+				t.Fatalf("Initiate = %+v, se esperaba %s con referencia po_1", result, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderTimeoutIsTemporary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	p := NewBankTransferProvider(HTTPConfig{BaseURL: srv.URL, Timeout: 20 * time.Millisecond})
+	if _, err := p.Status(context.Background(), "po_1"); !errors.Is(err, ErrTemporary) {
+		t.Fatalf("Status = %v, se esperaba ErrTemporary", err)
+	}
+}
+
+func TestHTTPProviderWebhookSignature(t *testing.T) {
+	const secret = "secreto-webhook"
+	body, _ := json.Marshal(payoutState{ID: "po_1", Reference: testRequest().PayoutID, Status: "completed"})
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	valid := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		wantErr   error
+	}{
+		{name: "firma válida", secret: secret, signature: valid},
+		{name: "firma de otro secreto", secret: "otro", signature: valid, wantErr: ErrInvalidSignature},
+		{name: "sin firma", secret: secret, signature: "", wantErr: ErrInvalidSignature},
+		{name: "firma no hexadecimal", secret: secret, signature: "zz", wantErr: ErrInvalidSignature},
+		{name: "proveedor sin secreto", secret: "", signature: valid, wantErr: ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewBankTransferProvider(HTTPConfig{BaseURL: "http://localhost", WebhookSecret: tt.secret})
+			header := http.Header{}
+			header.Set(SignatureHeader, tt.signature)
+			event, err := p.HandleWebhook(header, body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("HandleWebhook = %v, se esperaba %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleWebhook: %v", err)
+			}
+			if event.ProviderRef != "po_1" || event.PayoutID != testRequest().PayoutID || event.Status != StatusSucceeded {
+				t.Fatalf("HandleWebhook = %+v", event)
+			}
+		})
+	}
+}
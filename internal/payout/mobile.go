@@ -0,0 +1,13 @@
+package payout
+
+// NewMobileWalletProvider crea el adaptador de billeteras móviles. La cuenta
+// de destino es el celular con el que el minero abrió su Nequi o Daviplata.
+func NewMobileWalletProvider(cfg HTTPConfig) PayoutProvider {
+	return newHTTPProvider("wallet", []Method{MethodNequi, MethodDaviplata}, cfg, func(d Destination) map[string]string {
+		return map[string]string{
+			"type":   "mobile_wallet",
+			"wallet": string(d.Method),
+			"phone":  "+57" + d.Phone,
+		}
+	})
+}
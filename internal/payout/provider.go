@@ -0,0 +1,169 @@
+// Package payout define cómo se envía el dinero a los mineros: una interfaz
+// común para los proveedores de pago y adaptadores para transferencias
+// bancarias y billeteras móviles (Nequi, Daviplata).
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+var (
+	// ErrTemporary marca fallas que conviene reintentar (red, 5xx, límite de peticiones).
+	ErrTemporary = errors.New("falla temporal del proveedor de pagos")
+	// ErrRejected marca una petición que el proveedor rechazó (4xx). Solo
+	// significa que el pago falló si es la primera vez que se envía la orden.
+	ErrRejected = errors.New("el proveedor rechazó el pago")
+	// ErrConflict marca un 409: el proveedor ya tiene una orden con la misma
+	// llave de idempotencia, y puede que ya la haya pagado.
+	ErrConflict            = errors.New("el proveedor ya recibió una orden con la misma llave de idempotencia")
+	ErrInvalidSignature    = errors.New("la firma del webhook no es válida")
+	ErrInvalidDestination  = errors.New("los datos de la cuenta de destino no son válidos")
+	ErrUnsupportedMethod   = errors.New("ningún proveedor configurado atiende este medio de pago")
+	ErrUnknownPayoutStatus = errors.New("estado de pago desconocido")
+)
+
+// Method es el medio por el que el minero recibe el dinero.
+type Method string
+
+const (
+	MethodBankTransfer Method = "bank_transfer"
+	MethodNequi        Method = "nequi"
+	MethodDaviplata    Method = "daviplata"
+)
+
+// Status es el estado de un pago según el proveedor.
+type Status string
+
+const (
+	StatusProcessing Status = "processing"
+	StatusSucceeded  Status = "succeeded"
+	StatusFailed     Status = "failed"
+)
+
+// Destination es la cuenta que recibe el pago.
+type Destination struct {
+	Method             Method `json:"method"`
+	HolderName         string `json:"holder_name"`
+	HolderDocumentType string `json:"holder_document_type"` // CC, CE, NIT o PPT
+	HolderDocument     string `json:"holder_document"`
+
+	// Transferencia bancaria
+	BankCode      string `json:"bank_code,omitempty"`
+	AccountType   string `json:"account_type,omitempty"` // ahorros o corriente
+	AccountNumber string `json:"account_number,omitempty"`
+
+	// Billetera móvil
+	Phone string `json:"phone,omitempty"`
+}
+
+var (
+	digitsPattern      = regexp.MustCompile(`^[0-9]+$`)
+	mobilePhonePattern = regexp.MustCompile(`^3[0-9]{9}$`) // celular colombiano sin indicativo
+)
+
+// Validate revisa que la cuenta tenga los datos que exige su medio de pago.
+func (d Destination) Validate() error {
+	if d.HolderName == "" || !digitsPattern.MatchString(d.HolderDocument) {
+		return fmt.Errorf("%w: falta el titular o su documento", ErrInvalidDestination)
+	}
+	switch d.HolderDocumentType {
+	case "CC", "CE", "NIT", "PPT":
+	default:
+		return fmt.Errorf("%w: tipo de documento '%s' no soportado", ErrInvalidDestination, d.HolderDocumentType)
+	}
+
+	switch d.Method {
+	case MethodBankTransfer:
+		if d.BankCode == "" || (d.AccountType != "ahorros" && d.AccountType != "corriente") {
+			return fmt.Errorf("%w: la transferencia requiere banco y tipo de cuenta (ahorros o corriente)", ErrInvalidDestination)
+		}
+		if len(d.AccountNumber) < 5 || len(d.AccountNumber) > 20 || !digitsPattern.MatchString(d.AccountNumber) {
+			return fmt.Errorf("%w: número de cuenta inválido", ErrInvalidDestination)
+		}
+	case MethodNequi, MethodDaviplata:
+		if !mobilePhonePattern.MatchString(d.Phone) {
+			return fmt.Errorf("%w: el celular debe tener 10 dígitos y empezar por 3", ErrInvalidDestination)
+		}
+	default:
+		return fmt.Errorf("%w: medio de pago '%s' no soportado", ErrInvalidDestination, d.Method)
+	}
+	return nil
+}
+
+// Request es la orden de pago que se envía al proveedor. PayoutID sirve como
+// llave de idempotencia: reenviar la misma orden no debe pagar dos veces.
+type Request struct {
+	PayoutID    string
+	AmountCOP   int64
+	Destination Destination
+	Description string
+}
+
+// Result es la respuesta del proveedor sobre un pago.
+type Result struct {
+	ProviderRef string
+	Status      Status
+	Reason      string // motivo del rechazo, si lo hay
+}
+
+// WebhookEvent es una notificación asíncrona del proveedor sobre un pago.
+// PayoutID es la referencia de la orden, si el proveedor la incluye.
+type WebhookEvent struct {
+	ProviderRef string
+	PayoutID    string
+	Status      Status
+	Reason      string
+}
+
+// PayoutProvider es un proveedor que envía pagos. Initiate y Status devuelven
+// errores envueltos en ErrTemporary cuando tiene sentido reintentar.
+type PayoutProvider interface {
+	Name() string
+	Supports(method Method) bool
+	Initiate(ctx context.Context, req Request) (*Result, error)
+	Status(ctx context.Context, providerRef string) (*Result, error)
+	HandleWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// Registry elige el proveedor de cada medio de pago.
+type Registry struct {
+	providers []PayoutProvider
+}
+
+// NewRegistry registra los proveedores; si dos atienden el mismo medio, gana el primero.
+func NewRegistry(providers ...PayoutProvider) *Registry {
+	return &Registry{providers: providers}
+}
+
+// ForMethod devuelve el proveedor que atiende el medio de pago.
+func (r *Registry) ForMethod(method Method) (PayoutProvider, error) {
+	for _, p := range r.providers {
+		if p.Supports(method) {
+			return p, nil
+		}
+	}
+	return nil, ErrUnsupportedMethod
+}
+
+// Get busca un proveedor por su nombre (el que aparece en la ruta del webhook).
+func (r *Registry) Get(name string) (PayoutProvider, bool) {
+	for _, p := range r.providers {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Names lista los proveedores registrados.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for _, p := range r.providers {
+		names = append(names, p.Name())
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package pricing
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileSource lee los precios de un CSV local, para operar sin conexión o
+// cargar históricos. Formato, con encabezado:
+//
+//	date,series,value
+//	2026-10-16,spot_usd_ozt,2650.40
+//	2026-10-16,trm,4215.37
+//	2026-10-16,banrep_cop_g,352100
+//
+// El archivo se relee en cada consulta, así que se puede actualizar sin reiniciar.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Name() string {
+	return "file"
+}
+
+func (s *FileSource) Fetch(_ context.Context, from, to time.Time) ([]Quote, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 3
+	r.TrimLeadingSpace = true
+
+	var observations []Observation
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.path, err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue // Encabezado
+		}
+
+		date, err := ParseDay(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s línea %d: %w", s.path, line, err)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s línea %d: %w: valor '%s'", s.path, line, ErrInvalidObservation, record[2])
+		}
+		observations = append(observations, Observation{Date: date, Series: strings.TrimSpace(record[1]), Value: value})
+	}
+	return Normalize(s.Name(), observations, from, to), nil
+}
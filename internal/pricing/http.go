@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPSource consulta un servicio que entrega las observaciones en JSON:
+//
+//	GET <url>?from=2026-10-01&to=2026-10-16
+//	[{"date": "2026-10-16", "series": "spot_usd_ozt", "value": 2650.40}, ...]
+//
+// Sirve para conectar un proveedor de datos de mercado o un servicio interno
+// que replique la TRM y el precio del Banco de la República.
+type HTTPSource struct {
+	name   string
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewHTTPSource(name, endpoint, apiKey string) *HTTPSource {
+	return &HTTPSource{name: name, url: endpoint, apiKey: apiKey, client: &http.Client{Timeout: 20 * time.Second}}
+}
+
+func (s *HTTPSource) Name() string {
+	return s.name
+}
+
+type httpObservation struct {
+	Date   string  `json:"date"`
+	Series string  `json:"series"`
+	Value  float64 `json:"value"`
+}
+
+func (s *HTTPSource) Fetch(ctx context.Context, from, to time.Time) ([]Quote, error) {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("from", from.Format("2006-01-02"))
+	q.Set("to", to.Format("2006-01-02"))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("la fuente de precios %s respondió %d", s.name, resp.StatusCode)
+	}
+
+	var raw []httpObservation
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("respuesta de %s mal formada: %w", s.name, err)
+	}
+	observations := make([]Observation, 0, len(raw))
+	for _, o := range raw {
+		date, err := ParseDay(o.Date)
+		if err != nil {
+			return nil, err
+		}
+		observations = append(observations, Observation{Date: date, Series: o.Series, Value: o.Value})
+	}
+	return Normalize(s.name, observations, from, to), nil
+}
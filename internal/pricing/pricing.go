@@ -0,0 +1,140 @@
+// Package pricing obtiene los precios de referencia diarios del oro, en pesos
+// por gramo de oro fino, desde fuentes intercambiables.
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// TroyOunceGrams es la equivalencia de la onza troy en gramos.
+const TroyOunceGrams = 31.1034768
+
+var ErrInvalidObservation = errors.New("observación de precio inválida")
+
+// Kind identifica cada serie de precio de referencia.
+type Kind string
+
+const (
+	KindInternationalSpot Kind = "international_spot" // Spot internacional convertido a pesos con la TRM del día
+	KindBanRep            Kind = "banrep"             // Precio interno publicado por el Banco de la República
+)
+
+// Quote es el precio de referencia de un día.
+type Quote struct {
+	Date            time.Time // Medianoche UTC del día al que corresponde
+	Kind            Kind
+	PricePerGramCOP int64
+	Source          string
+}
+
+// Source es una fuente de precios. Fetch devuelve las cotizaciones de los días
+// entre from y to (inclusive) que tenga disponibles.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, from, to time.Time) ([]Quote, error)
+}
+
+// Series de las observaciones crudas que entregan las fuentes.
+const (
+	SeriesSpotUSDPerOunce  = "spot_usd_ozt" // Spot internacional en dólares por onza troy
+	SeriesSpotCOPPerGram   = "spot_cop_g"   // Spot internacional ya convertido a pesos por gramo
+	SeriesTRM              = "trm"          // Tasa representativa del mercado (pesos por dólar)
+	SeriesBanRepCOPPerGram = "banrep_cop_g"
+)
+
+// Observation es un dato crudo de una fuente, antes de convertirlo a pesos por gramo.
+type Observation struct {
+	Date   time.Time
+	Series string
+	Value  float64
+}
+
+// Day normaliza una fecha a la medianoche UTC del mismo día calendario.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDay lee una fecha en formato AAAA-MM-DD.
+func ParseDay(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: fecha '%s'", ErrInvalidObservation, s)
+	}
+	return t, nil
+}
+
+// Normalize convierte las observaciones en cotizaciones por día. El spot en
+// dólares por onza solo produce cotización si hay TRM para el mismo día; si la
+// fuente trae el spot ya en pesos por gramo, ese valor tiene prioridad. Una
+// observación con valor no positivo o de una serie desconocida se registra en
+// el log y se omite, sin descartar el resto de la consulta.
+func Normalize(source string, observations []Observation, from, to time.Time) []Quote {
+	from, to = Day(from), Day(to)
+	type daySeries struct {
+		spotUSD, spotCOP, trm, banrep float64
+	}
+	days := make(map[time.Time]*daySeries)
+	var order []time.Time
+
+	for _, o := range observations {
+		day := Day(o.Date)
+		if day.Before(from) || day.After(to) {
+			continue
+		}
+		if o.Value <= 0 || math.IsNaN(o.Value) || math.IsInf(o.Value, 0) {
+			log.Printf("Fuente de precios %s: se omite %s del %s con valor %v", source, o.Series, day.Format("2006-01-02"), o.Value)
+			continue
+		}
+		if !knownSeries(o.Series) {
+			log.Printf("Fuente de precios %s: se omite la serie desconocida '%s' del %s", source, o.Series, day.Format("2006-01-02"))
+			continue
+		}
+		ds, ok := days[day]
+		if !ok {
+			ds = &daySeries{}
+			days[day] = ds
+			order = append(order, day)
+		}
+		switch o.Series {
+		case SeriesSpotUSDPerOunce:
+			ds.spotUSD = o.Value
+		case SeriesSpotCOPPerGram:
+			ds.spotCOP = o.Value
+		case SeriesTRM:
+			ds.trm = o.Value
+		case SeriesBanRepCOPPerGram:
+			ds.banrep = o.Value
+		}
+	}
+
+	var quotes []Quote
+	for _, day := range order {
+		ds := days[day]
+		spot := ds.spotCOP
+		if spot == 0 && ds.spotUSD > 0 && ds.trm > 0 {
+			spot = ds.spotUSD * ds.trm / TroyOunceGrams
+		}
+		if spot > 0 {
+			quotes = append(quotes, Quote{Date: day, Kind: KindInternationalSpot, PricePerGramCOP: int64(math.Round(spot)), Source: source})
+		}
+		if ds.banrep > 0 {
+			quotes = append(quotes, Quote{Date: day, Kind: KindBanRep, PricePerGramCOP: int64(math.Round(ds.banrep)), Source: source})
+		}
+	}
+	return quotes
+}
+
+func knownSeries(series string) bool {
+	switch series {
+	case SeriesSpotUSDPerOunce, SeriesSpotCOPPerGram, SeriesTRM, SeriesBanRepCOPPerGram:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	d, err := ParseDay(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestNormalize(t *testing.T) {
+	from, to := day("2026-10-14"), day("2026-10-16")
+
+	tests := []struct {
+		name         string
+		observations []Observation
+		want         []Quote
+	}{
+		{
+			name: "spot en dólares por onza con TRM",
+			observations: []Observation{
+				{Date: day("2026-10-16"), Series: SeriesSpotUSDPerOunce, Value: 2650.40},
+				{Date: day("2026-10-16"), Series: SeriesTRM, Value: 4215.37},
+			},
+			// 2650.40 × 4215.37 ÷ 31.1034768 = 359201.54
+			want: []Quote{{Date: day("2026-10-16"), Kind: KindInternationalSpot, PricePerGramCOP: 359202}},
+		},
+		{
+			name: "spot sin TRM del mismo día no cotiza",
+			observations: []Observation{
+				{Date: day("2026-10-16"), Series: SeriesSpotUSDPerOunce, Value: 2650.40},
+				{Date: day("2026-10-15"), Series: SeriesTRM, Value: 4215.37},
+			},
+		},
+		{
+			name: "spot en pesos por gramo tiene prioridad",
+			observations: []Observation{
+				{Date: day("2026-10-16"), Series: SeriesSpotUSDPerOunce, Value: 2000},
+				{Date: day("2026-10-16"), Series: SeriesTRM, Value: 4000},
+				{Date: day("2026-10-16"), Series: SeriesSpotCOPPerGram, Value: 350000.4},
+			},
+			want: []Quote{{Date: day("2026-10-16"), Kind: KindInternationalSpot, PricePerGramCOP: 350000}},
+		},
+		{
+			name: "precio del Banco de la República",
+			observations: []Observation{
+				{Date: day("2026-10-15"), Series: SeriesBanRepCOPPerGram, Value: 352100.5},
+				{Date: day("2026-10-15"), Series: SeriesSpotCOPPerGram, Value: 351000},
+			},
+			want: []Quote{
+				{Date: day("2026-10-15"), Kind: KindInternationalSpot, PricePerGramCOP: 351000},
+				{Date: day("2026-10-15"), Kind: KindBanRep, PricePerGramCOP: 352101},
+			},
+		},
+		{
+			name: "días fuera del rango se descartan",
+			observations: []Observation{
+				{Date: day("2026-10-13"), Series: SeriesBanRepCOPPerGram, Value: 340000},
+				{Date: day("2026-10-14"), Series: SeriesBanRepCOPPerGram, Value: 341000},
+				{Date: day("2026-10-17"), Series: SeriesBanRepCOPPerGram, Value: 342000},
+			},
+			want: []Quote{{Date: day("2026-10-14"), Kind: KindBanRep, PricePerGramCOP: 341000}},
+		},
+		{
+			name: "la hora del día no importa",
+			observations: []Observation{
+				{Date: time.Date(2026, time.October, 16, 23, 59, 0, 0, time.UTC), Series: SeriesBanRepCOPPerGram, Value: 352100},
+			},
+			want: []Quote{{Date: day("2026-10-16"), Kind: KindBanRep, PricePerGramCOP: 352100}},
+		},
+		{
+			name: "observaciones inválidas se omiten sin descartar las demás",
+			observations: []Observation{
+				{Date: day("2026-10-16"), Series: SeriesBanRepCOPPerGram, Value: 352100},
+				{Date: day("2026-10-16"), Series: SeriesTRM, Value: 0},
+				{Date: day("2026-10-16"), Series: SeriesSpotUSDPerOunce, Value: 2650.40},
+				{Date: day("2026-10-15"), Series: SeriesSpotCOPPerGram, Value: -1},
+				{Date: day("2026-10-15"), Series: SeriesSpotCOPPerGram, Value: math.NaN()},
+				{Date: day("2026-10-15"), Series: "lbma_pm_usd", Value: 2640},
+				{Date: day("2026-10-14"), Series: SeriesSpotCOPPerGram, Value: math.Inf(1)},
+			},
+			want: []Quote{{Date: day("2026-10-16"), Kind: KindBanRep, PricePerGramCOP: 352100}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize("prueba", tt.observations, from, to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Normalize = %+v, se esperaba %+v", got, tt.want)
+			}
+			for i := range got {
+				want := tt.want[i]
+				want.Source = "prueba"
+				if got[i] != want {
+					t.Fatalf("cotización %d = %+v, se esperaba %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func writePriceFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "precios.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileSource(t *testing.T) {
+	from, to := day("2026-10-01"), day("2026-10-31")
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Quote
+		wantErr error
+	}{
+		{
+			name:    "con encabezado",
+			content: "date,series,value\n2026-10-16,spot_usd_ozt,2650.40\n2026-10-16, trm, 4215.37\n2026-10-16,banrep_cop_g,352100\n",
+			want: []Quote{
+				{Date: day("2026-10-16"), Kind: KindInternationalSpot, PricePerGramCOP: 359202, Source: "file"},
+				{Date: day("2026-10-16"), Kind: KindBanRep, PricePerGramCOP: 352100, Source: "file"},
+			},
+		},
+		{
+			name:    "sin encabezado",
+			content: "2026-10-15,banrep_cop_g,351000\n",
+			want:    []Quote{{Date: day("2026-10-15"), Kind: KindBanRep, PricePerGramCOP: 351000, Source: "file"}},
+		},
+		{
+			name:    "serie desconocida se omite",
+			content: "date,series,value\n2026-10-15,lbma_pm_usd,2640\n2026-10-15,banrep_cop_g,351000\n",
+			want:    []Quote{{Date: day("2026-10-15"), Kind: KindBanRep, PricePerGramCOP: 351000, Source: "file"}},
+		},
+		{name: "fecha inválida", content: "date,series,value\n16/10/2026,trm,4215.37\n", wantErr: ErrInvalidObservation},
+		{name: "valor no numérico", content: "date,series,value\n2026-10-16,trm,cuatro mil\n", wantErr: ErrInvalidObservation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFileSource(writePriceFile(t, tt.content)).Fetch(context.Background(), from, to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Fetch = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("Fetch = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFileSource(filepath.Join(t.TempDir(), "no-existe.csv")).Fetch(context.Background(), from, to); err == nil {
+		t.Fatal("Fetch de un archivo inexistente no devolvió error")
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "2026-10-10" || r.URL.Query().Get("to") != "2026-10-16" {
+			t.Errorf("consulta = %s", r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer llave" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`[{"date":"2026-10-16","series":"banrep_cop_g","value":352100},{"date":"2026-10-16","series":"trm","value":-1}]`))
+	}))
+	defer srv.Close()
+
+	got, err := NewHTTPSource("mercado", srv.URL, "llave").Fetch(context.Background(), day("2026-10-10"), day("2026-10-16"))
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	want := []Quote{{Date: day("2026-10-16"), Kind: KindBanRep, PricePerGramCOP: 352100, Source: "mercado"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Fetch = %+v, se esperaba %+v", got, want)
+	}
+}
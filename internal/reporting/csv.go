@@ -0,0 +1,52 @@
+package reporting
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+var csvColumns = []string{
+	"consecutivo", "fecha_venta", "id_venta", "numero_certificado",
+	"nit_comprador", "rucom_comprador", "razon_social_comprador",
+	"id_minero", "nombres_minero", "apellidos_minero",
+	"municipio_origen", "departamento_origen", "titulo_minero", "mineral",
+	"peso_bruto_g", "ley", "oro_fino_g", "precio_gramo_cop", "total_cop",
+}
+
+// WriteCSV escribe una compra por fila, con encabezado, coma como separador y
+// punto decimal. Los textos se dejan tal como están registrados (UTF-8).
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for i, r := range records {
+		row := []string{
+			strconv.Itoa(i + 1),
+			r.SoldAt.Format("2006-01-02"),
+			r.SaleID.String(),
+			r.CertificateNumber,
+			r.BuyerNIT,
+			r.BuyerRUCOM,
+			r.BuyerLegalName,
+			r.MinerID.String(),
+			r.MinerFirstName,
+			r.MinerLastName,
+			r.SiteMunicipality,
+			r.SiteDepartment,
+			r.SiteTitleNumber,
+			r.Mineral,
+			strconv.FormatFloat(r.WeightGrams, 'f', 3, 64),
+			strconv.FormatFloat(r.Purity, 'f', 4, 64),
+			strconv.FormatFloat(r.FineGoldGrams, 'f', 3, 64),
+			strconv.FormatInt(r.PricePerGramCOP, 10),
+			strconv.FormatInt(r.TotalCOP, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
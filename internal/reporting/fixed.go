@@ -0,0 +1,178 @@
+package reporting
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// recordLength es el largo de todas las líneas del archivo plano; el
+// encabezado y el control se completan con espacios hasta este largo.
+const recordLength = 374
+
+// WriteFixedWidth escribe el archivo plano de ancho fijo: una línea de
+// encabezado (tipo 1), una por compra (tipo 2) y una de control (tipo 3),
+// separadas por "\r\n". Los campos alfanuméricos van en mayúsculas, sin
+// tildes, alineados a la izquierda y rellenos con espacios (se recortan si
+// exceden el ancho); los numéricos van alineados a la derecha con ceros y sin
+// separador decimal. Los gramos se expresan en miligramos y la ley en
+// diezmilésimas.
+//
+// Encabezado (tipo 1):
+//
+//	tipo_registro      1  N  "1"
+//	nit_declarante    15  A
+//	nombre_declarante 60  A
+//	fecha_inicio       8  N  AAAAMMDD
+//	fecha_fin          8  N  AAAAMMDD
+//	fecha_generacion   8  N  AAAAMMDD
+//	cantidad_registros 8  N
+//
+// Detalle (tipo 2):
+//
+//	tipo_registro      1  N  "2"
+//	consecutivo        8  N
+//	fecha_venta        8  N  AAAAMMDD
+//	id_venta          36  A
+//	numero_certificado 24 A
+//	nit_comprador     15  A
+//	rucom_comprador   20  A
+//	id_minero         36  A
+//	nombre_minero     60  A  apellidos y nombres
+//	municipio_origen  40  A
+//	departamento      30  A
+//	titulo_minero     30  A
+//	mineral           10  A
+//	peso_bruto_mg     12  N
+//	ley               5   N
+//	oro_fino_mg       12  N
+//	precio_gramo_cop  12  N
+//	total_cop         15  N
+//
+// Control (tipo 3):
+//
+//	tipo_registro      1  N  "3"
+//	cantidad_registros 8  N
+//	total_peso_mg     15  N
+//	total_fino_mg     15  N
+//	total_cop         18  N
+func WriteFixedWidth(w io.Writer, h Header, records []Record) error {
+	var out strings.Builder
+
+	header := &fixedLine{}
+	header.num("tipo_registro", 1, 1)
+	header.alpha(h.ReporterNIT, 15)
+	header.alpha(h.ReporterName, 60)
+	header.date(h.PeriodStart.Format("20060102"))
+	header.date(h.PeriodEnd.Format("20060102"))
+	header.date(h.GeneratedAt.Format("20060102"))
+	header.num("cantidad_registros", int64(len(records)), 8)
+	if err := header.writeTo(&out); err != nil {
+		return err
+	}
+
+	var weightMg, fineMg, totalCOP int64
+	for i, r := range records {
+		l := &fixedLine{}
+		l.num("tipo_registro", 2, 1)
+		l.num("consecutivo", int64(i+1), 8)
+		l.date(r.SoldAt.Format("20060102"))
+		l.alpha(r.SaleID.String(), 36)
+		l.alpha(r.CertificateNumber, 24)
+		l.alpha(r.BuyerNIT, 15)
+		l.alpha(r.BuyerRUCOM, 20)
+		l.alpha(r.MinerID.String(), 36)
+		l.alpha(r.MinerLastName+" "+r.MinerFirstName, 60)
+		l.alpha(r.SiteMunicipality, 40)
+		l.alpha(r.SiteDepartment, 30)
+		l.alpha(r.SiteTitleNumber, 30)
+		l.alpha(r.Mineral, 10)
+		l.num("peso_bruto_mg", milligrams(r.WeightGrams), 12)
+		l.num("ley", int64(math.Round(r.Purity*10000)), 5)
+		l.num("oro_fino_mg", milligrams(r.FineGoldGrams), 12)
+		l.num("precio_gramo_cop", r.PricePerGramCOP, 12)
+		l.num("total_cop", r.TotalCOP, 15)
+		if err := l.writeTo(&out); err != nil {
+			return fmt.Errorf("registro %d: %w", i+1, err)
+		}
+		weightMg += milligrams(r.WeightGrams)
+		fineMg += milligrams(r.FineGoldGrams)
+		totalCOP += r.TotalCOP
+	}
+
+	trailer := &fixedLine{}
+	trailer.num("tipo_registro", 3, 1)
+	trailer.num("cantidad_registros", int64(len(records)), 8)
+	trailer.num("total_peso_mg", weightMg, 15)
+	trailer.num("total_fino_mg", fineMg, 15)
+	trailer.num("total_cop", totalCOP, 18)
+	if err := trailer.writeTo(&out); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// fixedLine arma una línea campo por campo y guarda el primer error.
+type fixedLine struct {
+	b   strings.Builder
+	err error
+}
+
+func (l *fixedLine) alpha(s string, width int) {
+	s = asciiUpper(s)
+	if len(s) > width {
+		s = s[:width]
+	}
+	l.b.WriteString(s)
+	l.b.WriteString(strings.Repeat(" ", width-len(s)))
+}
+
+func (l *fixedLine) num(name string, n int64, width int) {
+	s := strconv.FormatInt(n, 10)
+	if n < 0 || len(s) > width {
+		if l.err == nil {
+			l.err = fmt.Errorf("%w: %s=%d (ancho %d)", ErrFieldOverflow, name, n, width)
+		}
+		s = strings.Repeat("9", width)
+	}
+	l.b.WriteString(strings.Repeat("0", width-len(s)))
+	l.b.WriteString(s)
+}
+
+func (l *fixedLine) date(yyyymmdd string) {
+	l.b.WriteString(yyyymmdd)
+}
+
+func (l *fixedLine) writeTo(out *strings.Builder) error {
+	if l.err != nil {
+		return l.err
+	}
+	line := l.b.String()
+	out.WriteString(line)
+	out.WriteString(strings.Repeat(" ", recordLength-len(line)))
+	out.WriteString("\r\n")
+	return nil
+}
+
+// accentFolder quita las tildes del español antes de pasar a mayúsculas.
+var accentFolder = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+)
+
+// asciiUpper deja el texto en ASCII imprimible y mayúsculas; cualquier otro
+// carácter (incluidos saltos de línea) se reemplaza por un espacio.
+func asciiUpper(s string) string {
+	s = strings.ToUpper(accentFolder.Replace(strings.TrimSpace(s)))
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
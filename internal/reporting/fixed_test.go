@@ -0,0 +1,223 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var colombiaTime = time.FixedZone("COT", -5*60*60)
+
+func testHeader() Header {
+	return Header{
+		ReporterNIT:  "901234567",
+		ReporterName: "Batea S.A.S.",
+		PeriodStart:  time.Date(2026, time.March, 1, 0, 0, 0, 0, colombiaTime),
+		PeriodEnd:    time.Date(2026, time.March, 31, 0, 0, 0, 0, colombiaTime),
+		GeneratedAt:  time.Date(2026, time.April, 2, 9, 0, 0, 0, colombiaTime),
+	}
+}
+
+func testRecord() Record {
+	return Record{
+		SaleID:            uuid.MustParse("0b9f6a1e-2c3d-4e5f-8a9b-0c1d2e3f4a5b"),
+		SoldAt:            time.Date(2026, time.March, 14, 10, 30, 0, 0, colombiaTime),
+		CertificateNumber: "CO-2026-0B9F6A1E2C3D",
+		BuyerNIT:          "800123456",
+		BuyerRUCOM:        "RUCOM-20260101",
+		BuyerLegalName:    "Compraventa El Dorado",
+		MinerID:           uuid.MustParse("7c1a2b3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"),
+		MinerFirstName:    "José",
+		MinerLastName:     "Muñoz Peña",
+		SiteMunicipality:  "Segovia",
+		SiteDepartment:    "Antioquia",
+		Mineral:           "oro",
+		WeightGrams:       12.3456,
+		Purity:            0.875,
+		FineGoldGrams:     10.8024,
+		PricePerGramCOP:   350000,
+		TotalCOP:          4320960,
+	}
+}
+
+// fixedField describe un campo del archivo plano para leerlo por posición.
+type fixedField struct {
+	name  string
+	width int
+	want  string
+}
+
+// checkFields recorre la línea campo por campo y exige que el resto sean espacios.
+func checkFields(t *testing.T, line string, fields []fixedField) {
+	t.Helper()
+	if len(line) != recordLength {
+		t.Fatalf("largo de línea = %d, se esperaba %d", len(line), recordLength)
+	}
+	pos := 0
+	for _, f := range fields {
+		got := line[pos : pos+f.width]
+		if got != f.want {
+			t.Errorf("%s [%d:%d] = %q, se esperaba %q", f.name, pos, pos+f.width, got, f.want)
+		}
+		pos += f.width
+	}
+	if rest := line[pos:]; strings.TrimRight(rest, " ") != "" {
+		t.Errorf("relleno final = %q, se esperaban espacios", rest)
+	}
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+func TestWriteFixedWidthLayout(t *testing.T) {
+	second := testRecord()
+	second.SaleID = uuid.MustParse("1c0a7b2f-3d4e-4f60-9b0c-1d2e3f4a5b6c")
+	second.SiteTitleNumber = "HJK-08121"
+	second.WeightGrams = 1
+	second.Purity = 1
+	second.FineGoldGrams = 1
+	second.TotalCOP = 350000
+
+	var buf bytes.Buffer
+	if err := WriteFixedWidth(&buf, testHeader(), []Record{testRecord(), second}); err != nil {
+		t.Fatalf("WriteFixedWidth: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "\r\n") {
+		t.Fatal("el archivo no termina en \\r\\n")
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(lines) != 4 {
+		t.Fatalf("%d líneas, se esperaban encabezado, 2 detalles y control", len(lines))
+	}
+
+	t.Run("encabezado", func(t *testing.T) {
+		checkFields(t, lines[0], []fixedField{
+			{"tipo_registro", 1, "1"},
+			{"nit_declarante", 15, pad("901234567", 15)},
+			{"nombre_declarante", 60, pad("BATEA S.A.S.", 60)},
+			{"fecha_inicio", 8, "20260301"},
+			{"fecha_fin", 8, "20260331"},
+			{"fecha_generacion", 8, "20260402"},
+			{"cantidad_registros", 8, "00000002"},
+		})
+	})
+	t.Run("detalle", func(t *testing.T) {
+		checkFields(t, lines[1], []fixedField{
+			{"tipo_registro", 1, "2"},
+			{"consecutivo", 8, "00000001"},
+			{"fecha_venta", 8, "20260314"},
+			{"id_venta", 36, "0B9F6A1E-2C3D-4E5F-8A9B-0C1D2E3F4A5B"},
+			{"numero_certificado", 24, pad("CO-2026-0B9F6A1E2C3D", 24)},
+			{"nit_comprador", 15, pad("800123456", 15)},
+			{"rucom_comprador", 20, pad("RUCOM-20260101", 20)},
+			{"id_minero", 36, "7C1A2B3D-4E5F-4A6B-8C7D-9E0F1A2B3C4D"},
+			{"nombre_minero", 60, pad("MUNOZ PENA JOSE", 60)},
+			{"municipio_origen", 40, pad("SEGOVIA", 40)},
+			{"departamento", 30, pad("ANTIOQUIA", 30)},
+			{"titulo_minero", 30, pad("", 30)},
+			{"mineral", 10, pad("ORO", 10)},
+			{"peso_bruto_mg", 12, "000000012346"},
+			{"ley", 5, "08750"},
+			{"oro_fino_mg", 12, "000000010802"},
+			{"precio_gramo_cop", 12, "000000350000"},
+			{"total_cop", 15, "000000004320960"},
+		})
+	})
+	t.Run("control", func(t *testing.T) {
+		checkFields(t, lines[3], []fixedField{
+			{"tipo_registro", 1, "3"},
+			{"cantidad_registros", 8, "00000002"},
+			{"total_peso_mg", 15, "000000000013346"},
+			{"total_fino_mg", 15, "000000000011802"},
+			{"total_cop", 18, "000000000004670960"},
+		})
+	})
+}
+
+func TestFixedLineFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(l *fixedLine)
+		want  string
+	}{
+		{"alfanumérico se rellena con espacios", func(l *fixedLine) { l.alpha("abc", 5) }, "ABC  "},
+		{"alfanumérico se recorta", func(l *fixedLine) { l.alpha("abcdefgh", 5) }, "ABCDE"},
+		{"quita tildes y eñes", func(l *fixedLine) { l.alpha("Ñuñoa Bolívar", 13) }, "NUNOA BOLIVAR"},
+		{"espacios alrededor se ignoran", func(l *fixedLine) { l.alpha("  Cauca  ", 7) }, "CAUCA  "},
+		{"saltos de línea y otros caracteres pasan a espacio", func(l *fixedLine) { l.alpha("a\nb€c", 6) }, "A B C "},
+		{"numérico con ceros a la izquierda", func(l *fixedLine) { l.num("n", 42, 6) }, "000042"},
+		{"numérico que llena el ancho", func(l *fixedLine) { l.num("n", 123456, 6) }, "123456"},
+		{"cero", func(l *fixedLine) { l.num("n", 0, 3) }, "000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &fixedLine{}
+			tt.write(l)
+			if got := l.b.String(); got != tt.want || l.err != nil {
+				t.Fatalf("campo = %q (err %v), se esperaba %q", got, l.err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFixedWidthRejectsOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(r *Record)
+	}{
+		{"total que no cabe", func(r *Record) { r.TotalCOP = 1_000_000_000_000_000 }},
+		{"peso que no cabe", func(r *Record) { r.WeightGrams = 1_000_000_000 }},
+		{"valor negativo", func(r *Record) { r.PricePerGramCOP = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRecord()
+			tt.change(&r)
+			var buf bytes.Buffer
+			if err := WriteFixedWidth(&buf, testHeader(), []Record{r}); !errors.Is(err, ErrFieldOverflow) {
+				t.Fatalf("WriteFixedWidth = %v, se esperaba ErrFieldOverflow", err)
+			}
+			if buf.Len() != 0 {
+				t.Fatal("se escribió un archivo parcial")
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Record{testRecord()}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV inválido: %v", err)
+	}
+	if len(rows) != 2 || len(rows[0]) != len(csvColumns) || len(rows[1]) != len(csvColumns) {
+		t.Fatalf("filas = %v", rows)
+	}
+	row := make(map[string]string, len(csvColumns))
+	for i, col := range csvColumns {
+		row[col] = rows[1][i]
+	}
+	want := map[string]string{
+		"consecutivo":      "1",
+		"fecha_venta":      "2026-03-14",
+		"apellidos_minero": "Muñoz Peña",
+		"peso_bruto_g":     "12.346",
+		"ley":              "0.8750",
+		"oro_fino_g":       "10.802",
+		"total_cop":        "4320960",
+	}
+	for col, v := range want {
+		if row[col] != v {
+			t.Errorf("%s = %q, se esperaba %q", col, row[col], v)
+		}
+	}
+}
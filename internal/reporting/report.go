@@ -0,0 +1,120 @@
+// Package reporting arma el reporte periódico de compras de oro a mineros de
+// subsistencia que se entrega a la autoridad minera, en CSV y en archivo plano
+// de ancho fijo.
+package reporting
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownFormat = errors.New("formato de reporte no reconocido (use csv o fixed_width)")
+	ErrFieldOverflow = errors.New("un valor no cabe en el ancho del campo del archivo plano")
+)
+
+// Format es el formato del archivo del reporte.
+type Format string
+
+const (
+	FormatCSV        Format = "csv"
+	FormatFixedWidth Format = "fixed_width"
+)
+
+// Formats enumera los formatos en que se genera cada reporte.
+var Formats = []Format{FormatCSV, FormatFixedWidth}
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatCSV, FormatFixedWidth:
+		return Format(s), nil
+	}
+	return "", ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "text/plain; charset=us-ascii"
+}
+
+func (f Format) Extension() string {
+	if f == FormatCSV {
+		return "csv"
+	}
+	return "txt"
+}
+
+// Header identifica a quien reporta y el periodo (días inclusive).
+type Header struct {
+	ReporterNIT  string
+	ReporterName string
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	GeneratedAt  time.Time
+}
+
+// Record es una compra reportada. Las fechas deben venir en hora de Colombia.
+type Record struct {
+	SaleID            uuid.UUID
+	SoldAt            time.Time
+	CertificateNumber string
+	BuyerNIT          string `gorm:"column:buyer_nit"`
+	BuyerRUCOM        string `gorm:"column:buyer_rucom"`
+	BuyerLegalName    string
+	MinerID           uuid.UUID
+	MinerFirstName    string
+	MinerLastName     string
+	SiteMunicipality  string
+	SiteDepartment    string
+	SiteTitleNumber   string
+	Mineral           string
+	WeightGrams       float64
+	Purity            float64
+	FineGoldGrams     float64
+	PricePerGramCOP   int64
+	TotalCOP          int64
+}
+
+// Totals resume las compras de un reporte.
+type Totals struct {
+	Count         int
+	WeightGrams   float64
+	FineGoldGrams float64
+	TotalCOP      int64
+}
+
+// Summarize suma las compras. Los gramos se acumulan en miligramos para que
+// el total coincida con el de la línea de control del archivo plano.
+func Summarize(records []Record) Totals {
+	var weightMg, fineMg int64
+	t := Totals{Count: len(records)}
+	for _, r := range records {
+		weightMg += milligrams(r.WeightGrams)
+		fineMg += milligrams(r.FineGoldGrams)
+		t.TotalCOP += r.TotalCOP
+	}
+	t.WeightGrams = float64(weightMg) / 1000
+	t.FineGoldGrams = float64(fineMg) / 1000
+	return t
+}
+
+// Write genera el reporte en el formato indicado.
+func Write(w io.Writer, f Format, h Header, records []Record) error {
+	switch f {
+	case FormatCSV:
+		return WriteCSV(w, records)
+	case FormatFixedWidth:
+		return WriteFixedWidth(w, h, records)
+	}
+	return ErrUnknownFormat
+}
+
+func milligrams(grams float64) int64 {
+	return int64(math.Round(grams * 1000))
+}
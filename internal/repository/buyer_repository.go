@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBuyerNotFound         = errors.New("comercializador no encontrado")
+	ErrPurchasePointNotFound = errors.New("punto de compra no encontrado")
+	ErrBuyerStatusChanged    = errors.New("el estado del comercializador cambió mientras se procesaba la solicitud")
+	ErrBuyerDuplicate        = errors.New("ya existe un comercializador con este NIT, número RUCOM o usuario")
+	ErrPointStatusChanged    = errors.New("el estado del punto de compra cambió mientras se procesaba la solicitud")
+)
+
+type BuyerRepository interface {
+	CreateWithDocuments(buyer *models.Buyer, docs []*models.Document) error
+	FindByID(id uuid.UUID) (*models.Buyer, error)
+	FindByUserID(userID uuid.UUID) (*models.Buyer, error)
+	FindByStatusPaginated(status models.BuyerStatus, page, limit int) (*utils.Pagination, error)
+	UpdateStatus(buyer *models.Buyer, from models.BuyerStatus, entry *models.LedgerEntry) error
+	AddPurchasePoint(point *models.BuyerPurchasePoint) error
+	FindPurchasePoint(buyerID, pointID uuid.UUID) (*models.BuyerPurchasePoint, error)
+	FindPurchasePointByID(pointID uuid.UUID) (*models.BuyerPurchasePoint, error)
+	FindPurchasePointsByStatusPaginated(status models.PurchasePointStatus, page, limit int) (*utils.Pagination, error)
+	UpdatePurchasePointStatus(point *models.BuyerPurchasePoint, from models.PurchasePointStatus, entry *models.LedgerEntry) error
+	DeactivatePurchasePoint(buyerID, pointID uuid.UUID) error
+}
+
+type buyerRepository struct {
+	db *gorm.DB
+}
+
+func NewBuyerRepository(db *gorm.DB) BuyerRepository {
+	return &buyerRepository{db}
+}
+
+// CreateWithDocuments inserta el comercializador y la metadata de sus certificados en una transacción.
+// Devuelve ErrBuyerDuplicate si el NIT, el RUCOM o el usuario ya están registrados.
+func (r *buyerRepository) CreateWithDocuments(buyer *models.Buyer, docs []*models.Document) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("PurchasePoints", "Documents").Create(buyer).Error; err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		return tx.Create(&docs).Error
+	})
+	if isUniqueViolation(err) {
+		return ErrBuyerDuplicate
+	}
+	return err
+}
+
+func (r *buyerRepository) FindByID(id uuid.UUID) (*models.Buyer, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *buyerRepository) FindByUserID(userID uuid.UUID) (*models.Buyer, error) {
+	return r.findOne(r.db.Where("user_id = ?", userID))
+}
+
+// findOne carga el comercializador con sus puntos de compra y certificados.
+func (r *buyerRepository) findOne(query *gorm.DB) (*models.Buyer, error) {
+	var buyer models.Buyer
+	err := query.
+		Preload("PurchasePoints", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Documents", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).
+		First(&buyer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBuyerNotFound
+		}
+		return nil, err
+	}
+	return &buyer, nil
+}
+
+func (r *buyerRepository) FindByStatusPaginated(status models.BuyerStatus, page, limit int) (*utils.Pagination, error) {
+	var buyers []models.Buyer
+	query := r.db.Where("status = ?", status).Order("created_at ASC").Session(&gorm.Session{})
+	return utils.Paginate(query, &models.Buyer{}, page, limit, &buyers)
+}
+
+// UpdateStatus guarda la decisión del administrador solo si el estado sigue
+// siendo from, junto con su entrada del libro mayor.
+func (r *buyerRepository) UpdateStatus(buyer *models.Buyer, from models.BuyerStatus, entry *models.LedgerEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Buyer{}).
+			Where("id = ? AND status = ?", buyer.ID, from).
+			Updates(map[string]interface{}{
+				"status":           buyer.Status,
+				"reviewed_by":      buyer.ReviewedBy,
+				"reviewed_at":      buyer.ReviewedAt,
+				"rejection_reason": buyer.RejectionReason,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrBuyerStatusChanged
+		}
+		return appendLedgerEntry(tx, entry)
+	})
+}
+
+func (r *buyerRepository) AddPurchasePoint(point *models.BuyerPurchasePoint) error {
+	return r.db.Create(point).Error
+}
+
+func (r *buyerRepository) FindPurchasePoint(buyerID, pointID uuid.UUID) (*models.BuyerPurchasePoint, error) {
+	return r.findPoint(r.db.Where("id = ? AND buyer_id = ?", pointID, buyerID))
+}
+
+func (r *buyerRepository) FindPurchasePointByID(pointID uuid.UUID) (*models.BuyerPurchasePoint, error) {
+	return r.findPoint(r.db.Where("id = ?", pointID))
+}
+
+func (r *buyerRepository) findPoint(query *gorm.DB) (*models.BuyerPurchasePoint, error) {
+	var point models.BuyerPurchasePoint
+	if err := query.First(&point).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPurchasePointNotFound
+		}
+		return nil, err
+	}
+	return &point, nil
+}
+
+func (r *buyerRepository) FindPurchasePointsByStatusPaginated(status models.PurchasePointStatus, page, limit int) (*utils.Pagination, error) {
+	var points []models.BuyerPurchasePoint
+	query := r.db.Where("status = ?", status).Order("created_at ASC").Session(&gorm.Session{})
+	return utils.Paginate(query, &models.BuyerPurchasePoint{}, page, limit, &points)
+}
+
+// UpdatePurchasePointStatus guarda la decisión del administrador solo si el
+// estado del punto sigue siendo from, junto con su entrada del libro mayor.
+func (r *buyerRepository) UpdatePurchasePointStatus(point *models.BuyerPurchasePoint, from models.PurchasePointStatus, entry *models.LedgerEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.BuyerPurchasePoint{}).
+			Where("id = ? AND status = ?", point.ID, from).
+			Updates(map[string]interface{}{
+				"status":           point.Status,
+				"reviewed_by":      point.ReviewedBy,
+				"reviewed_at":      point.ReviewedAt,
+				"rejection_reason": point.RejectionReason,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPointStatusChanged
+		}
+		return appendLedgerEntry(tx, entry)
+	})
+}
+
+func (r *buyerRepository) DeactivatePurchasePoint(buyerID, pointID uuid.UUID) error {
+	res := r.db.Model(&models.BuyerPurchasePoint{}).
+		Where("id = ? AND buyer_id = ?", pointID, buyerID).
+		Update("active", false)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPurchasePointNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCertificateNotFound = errors.New("certificado de origen no encontrado")
+	ErrCertificateExists   = errors.New("la venta ya tiene un certificado de origen")
+)
+
+type CertificateRepository interface {
+	Create(cert *models.OriginCertificate) error
+	FindByID(id uuid.UUID) (*models.OriginCertificate, error)
+	FindBySale(saleID uuid.UUID) (*models.OriginCertificate, error)
+}
+
+type certificateRepository struct {
+	db *gorm.DB
+}
+
+func NewCertificateRepository(db *gorm.DB) CertificateRepository {
+	return &certificateRepository{db}
+}
+
+// Create inserta el certificado o devuelve ErrCertificateExists si otra
+// solicitud ya lo emitió para la misma venta.
+func (r *certificateRepository) Create(cert *models.OriginCertificate) error {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(cert)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCertificateExists
+	}
+	return nil
+}
+
+func (r *certificateRepository) FindByID(id uuid.UUID) (*models.OriginCertificate, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *certificateRepository) FindBySale(saleID uuid.UUID) (*models.OriginCertificate, error) {
+	return r.findOne(r.db.Where("sale_id = ?", saleID))
+}
+
+func (r *certificateRepository) findOne(q *gorm.DB) (*models.OriginCertificate, error) {
+	var cert models.OriginCertificate
+	if err := q.First(&cert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type DocumentRepository interface {
+	FindByMiner(minerID uuid.UUID) ([]models.Document, error)
+	DeleteByStorageKey(key string) error
+	ReferencedKeys(keys []string) (map[string]bool, error)
+}
+
+type documentRepository struct {
+	db *gorm.DB
+}
+
+func NewDocumentRepository(db *gorm.DB) DocumentRepository {
+	return &documentRepository{db}
+}
+
+// FindByMiner devuelve la metadata de los documentos del minero, del más reciente al más antiguo.
+func (r *documentRepository) FindByMiner(minerID uuid.UUID) ([]models.Document, error) {
+	var docs []models.Document
+	err := r.db.Where("miner_id = ?", minerID).Order("created_at DESC").Find(&docs).Error
+	return docs, err
+}
+
+// DeleteByStorageKey elimina la metadata de un archivo que ya no está en el almacenamiento.
+func (r *documentRepository) DeleteByStorageKey(key string) error {
+	return r.db.Where("storage_key = ?", key).Delete(&models.Document{}).Error
+}
+
+// ReferencedKeys indica cuáles de las llaves están referenciadas por una fila de
+// documents o por alguna columna <kind>_path de miners (incluidos los borrados
+// lógicamente, que conservan sus archivos).
+func (r *documentRepository) ReferencedKeys(keys []string) (map[string]bool, error) {
+	referenced := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return referenced, nil
+	}
+
+	var found []string
+	if err := r.db.Model(&models.Document{}).
+		Where("storage_key IN ?", keys).
+		Pluck("storage_key", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, k := range found {
+		referenced[k] = true
+	}
+
+	for _, kind := range models.AllDocumentKinds {
+		column := string(kind) + "_path"
+		found = found[:0]
+		if err := r.db.Unscoped().Model(&models.Miner{}).
+			Where(column+" IN ?", keys).
+			Pluck(column, &found).Error; err != nil {
+			return nil, err
+		}
+		for _, k := range found {
+			referenced[k] = true
+		}
+	}
+	return referenced, nil
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("saldo insuficiente en la cuenta")
+	ErrUnbalancedJournal = errors.New("la transacción contable no está balanceada")
+	ErrJournalNotFound   = errors.New("transacción contable no encontrada")
+)
+
+// journalRetries es cuántas veces se reintenta una transacción con asientos
+// que Postgres aborta por conflicto de serialización.
+const journalRetries = 3
+
+// signedAmount expresa cada asiento con el signo que tiene sobre el saldo.
+const signedAmount = "CASE WHEN direction = 'credit' THEN amount_cop ELSE -amount_cop END"
+
+// JournalRepository contabiliza en partida doble. Los saldos no se guardan:
+// siempre se suman desde el diario.
+//
+// Toda transacción que contabiliza asientos, sea Post o la de otro repositorio
+// que llama postJournal (venta, retención y cierre de un pago), corre con
+// journalTransaction: SERIALIZABLE y reintentada ante conflicto. Además
+// postJournal bloquea las cuentas que debita antes de sumar su saldo, de modo
+// que dos débitos simultáneos a la misma cuenta se esperan y el segundo ve el
+// saldo que dejó el primero.
+type JournalRepository interface {
+	Post(txn *models.JournalTransaction) (*models.JournalTransaction, error)
+	FindByKey(idempotencyKey string) (*models.JournalTransaction, error)
+	Balances(ownerType models.AccountOwnerType, ownerID uuid.UUID) (map[models.AccountKind]int64, error)
+	Statement(ref models.AccountRef, page, limit int) (*utils.Pagination, error)
+}
+
+type journalRepository struct {
+	db *gorm.DB
+}
+
+func NewJournalRepository(db *gorm.DB) JournalRepository {
+	return &journalRepository{db}
+}
+
+// Post contabiliza la transacción. Si la llave de idempotencia ya existe,
+// devuelve la transacción original sin contabilizar de nuevo.
+func (r *journalRepository) Post(txn *models.JournalTransaction) (*models.JournalTransaction, error) {
+	var posted *models.JournalTransaction
+	err := journalTransaction(r.db, func(tx *gorm.DB) error {
+		var err error
+		posted, err = postJournal(tx, txn)
+		return err
+	})
+	return posted, err
+}
+
+func (r *journalRepository) FindByKey(idempotencyKey string) (*models.JournalTransaction, error) {
+	var txn models.JournalTransaction
+	err := r.db.Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&txn, "idempotency_key = ?", idempotencyKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJournalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// journalTransaction ejecuta fn con aislamiento SERIALIZABLE y la repite si
+// Postgres la aborta por conflicto de serialización. fn debe poder repetirse:
+// todo lo que escribe se deshace con el intento abortado.
+func journalTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 1; attempt <= journalRetries; attempt++ {
+		err = db.Transaction(fn, &sql.TxOptions{Isolation: sql.LevelSerializable})
+		if !isSerializationFailure(err) {
+			break
+		}
+	}
+	return err
+}
+
+func (r *journalRepository) Balances(ownerType models.AccountOwnerType, ownerID uuid.UUID) (map[models.AccountKind]int64, error) {
+	var rows []struct {
+		Kind    models.AccountKind
+		Balance int64
+	}
+	err := r.db.Table("accounts").
+		Select("accounts.kind, COALESCE(SUM("+signedAmount+"), 0) AS balance").
+		Joins("LEFT JOIN journal_entries ON journal_entries.account_id = accounts.id").
+		Where("accounts.owner_type = ? AND accounts.owner_id = ?", ownerType, ownerID).
+		Group("accounts.kind").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[models.AccountKind]int64, len(rows))
+	for _, row := range rows {
+		balances[row.Kind] = row.Balance
+	}
+	return balances, nil
+}
+
+// Statement devuelve los asientos de la cuenta, del más reciente al más
+// antiguo, con el saldo que dejó cada uno.
+func (r *journalRepository) Statement(ref models.AccountRef, page, limit int) (*utils.Pagination, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	result := &utils.Pagination{Page: page, Limit: limit, Data: []models.StatementLine{}}
+
+	var account models.Account
+	err := r.db.Where("owner_type = ? AND owner_id = ? AND kind = ?", ref.OwnerType, ref.OwnerID, ref.Kind).
+		First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, nil // Sin movimientos todavía
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.JournalEntry{}).Where("account_id = ?", account.ID).
+		Count(&result.TotalRows).Error; err != nil {
+		return nil, err
+	}
+	result.TotalPages = int(math.Ceil(float64(result.TotalRows) / float64(limit)))
+
+	var lines []models.StatementLine
+	err = r.db.Raw(`
+		SELECT e.id AS entry_id, t.id AS transaction_id, t.created_at, t.kind, t.reference,
+			t.description, e.direction, e.amount_cop,
+			SUM(`+signedAmount+`) OVER (ORDER BY e.id) AS balance_cop
+		FROM journal_entries e
+		JOIN journal_transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = ?
+		ORDER BY e.id DESC
+		LIMIT ? OFFSET ?`, account.ID, limit, (page-1)*limit).
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	if lines != nil {
+		result.Data = lines
+	}
+	return result, nil
+}
+
+// postJournal contabiliza la transacción dentro de tx, que puede ser la
+// transacción de otro repositorio (por ejemplo, la de la venta) abierta con
+// journalTransaction. Las cuentas
+// se crean al primer uso y las que no admiten saldo negativo se bloquean
+// antes de comprobar que el débito no las deje en rojo.
+func postJournal(tx *gorm.DB, txn *models.JournalTransaction) (*models.JournalTransaction, error) {
+	if err := validateJournal(txn); err != nil {
+		return nil, err
+	}
+	// Un reintento no debe arrastrar los IDs asignados en el intento abortado
+	for i := range txn.Entries {
+		txn.Entries[i].ID = 0
+	}
+
+	res := tx.Omit("Entries").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "idempotency_key"}}, DoNothing: true}).
+		Create(txn)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		var existing models.JournalTransaction
+		err := tx.Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			First(&existing, "idempotency_key = ?", txn.IdempotencyKey).Error
+		return &existing, err
+	}
+
+	// Efecto neto de la transacción sobre cada cuenta que no admite saldo negativo
+	net := make(map[uuid.UUID]int64)
+	for i := range txn.Entries {
+		e := &txn.Entries[i]
+		account, err := ensureAccount(tx, e.Account)
+		if err != nil {
+			return nil, err
+		}
+		e.AccountID = account.ID
+		e.TransactionID = txn.ID
+		if !account.AllowNegative {
+			if e.Direction == models.Credit {
+				net[account.ID] += e.AmountCOP
+			} else {
+				net[account.ID] -= e.AmountCOP
+			}
+		}
+	}
+
+	var debited []uuid.UUID
+	for id, amount := range net {
+		if amount < 0 {
+			debited = append(debited, id)
+		}
+	}
+	// Orden fijo de bloqueo para no crear interbloqueos entre transacciones
+	sort.Slice(debited, func(i, j int) bool { return debited[i].String() < debited[j].String() })
+	for _, id := range debited {
+		var locked models.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&locked, "id = ?", id).Error; err != nil {
+			return nil, err
+		}
+		var balance int64
+		if err := tx.Model(&models.JournalEntry{}).Where("account_id = ?", id).
+			Select("COALESCE(SUM(" + signedAmount + "), 0)").Scan(&balance).Error; err != nil {
+			return nil, err
+		}
+		if balance+net[id] < 0 {
+			return nil, ErrInsufficientFunds
+		}
+	}
+
+	if err := tx.Create(&txn.Entries).Error; err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// validateJournal exige al menos dos asientos positivos cuyos débitos igualen a los créditos.
+func validateJournal(txn *models.JournalTransaction) error {
+	if txn.IdempotencyKey == "" || txn.ID == uuid.Nil || len(txn.Entries) < 2 {
+		return ErrUnbalancedJournal
+	}
+	var debits, credits int64
+	for _, e := range txn.Entries {
+		if e.AmountCOP <= 0 {
+			return ErrUnbalancedJournal
+		}
+		switch e.Direction {
+		case models.Debit:
+			debits += e.AmountCOP
+		case models.Credit:
+			credits += e.AmountCOP
+		default:
+			return ErrUnbalancedJournal
+		}
+	}
+	if debits != credits {
+		return ErrUnbalancedJournal
+	}
+	return nil
+}
+
+// ensureAccount devuelve la cuenta indicada, creándola si aún no existe.
+func ensureAccount(tx *gorm.DB, ref models.AccountRef) (*models.Account, error) {
+	create := models.Account{
+		OwnerType:     ref.OwnerType,
+		OwnerID:       ref.OwnerID,
+		Kind:          ref.Kind,
+		Currency:      "COP",
+		AllowNegative: ref.AllowsNegative(),
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&create).Error; err != nil {
+		return nil, err
+	}
+	var account models.Account
+	if err := tx.Where("owner_type = ? AND owner_id = ? AND kind = ?", ref.OwnerType, ref.OwnerID, ref.Kind).
+		First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
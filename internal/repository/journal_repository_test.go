@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+func TestValidateJournal(t *testing.T) {
+	miner := models.AccountRef{OwnerType: models.OwnerMiner, OwnerID: uuid.New(), Kind: models.AccountWallet}
+	buyer := models.AccountRef{OwnerType: models.OwnerBuyer, OwnerID: uuid.New(), Kind: models.AccountWallet}
+	entry := func(account models.AccountRef, direction models.EntryDirection, amount int64) models.JournalEntry {
+		return models.JournalEntry{Account: account, Direction: direction, AmountCOP: amount}
+	}
+
+	tests := []struct {
+		name    string
+		noKey   bool
+		noID    bool
+		entries []models.JournalEntry
+		wantErr bool
+	}{
+		{name: "débito y crédito iguales", entries: []models.JournalEntry{entry(buyer, models.Debit, 1000), entry(miner, models.Credit, 1000)}},
+		{name: "varios asientos que suman cero", entries: []models.JournalEntry{
+			entry(buyer, models.Debit, 600), entry(buyer, models.Debit, 400), entry(miner, models.Credit, 1000),
+		}},
+		{name: "descuadrada", entries: []models.JournalEntry{entry(buyer, models.Debit, 1000), entry(miner, models.Credit, 999)}, wantErr: true},
+		{name: "un solo asiento", entries: []models.JournalEntry{entry(miner, models.Credit, 1000)}, wantErr: true},
+		{name: "monto cero", entries: []models.JournalEntry{entry(buyer, models.Debit, 0), entry(miner, models.Credit, 0)}, wantErr: true},
+		{name: "monto negativo", entries: []models.JournalEntry{entry(buyer, models.Debit, -5), entry(miner, models.Credit, -5)}, wantErr: true},
+		{name: "dirección desconocida", entries: []models.JournalEntry{entry(buyer, "sideways", 1000), entry(miner, models.Credit, 1000)}, wantErr: true},
+		{name: "sin llave de idempotencia", noKey: true, entries: []models.JournalEntry{entry(buyer, models.Debit, 1000), entry(miner, models.Credit, 1000)}, wantErr: true},
+		{name: "sin ID", noID: true, entries: []models.JournalEntry{entry(buyer, models.Debit, 1000), entry(miner, models.Credit, 1000)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &models.JournalTransaction{ID: uuid.New(), IdempotencyKey: "prueba", Entries: tt.entries}
+			if tt.noKey {
+				txn.IdempotencyKey = ""
+			}
+			if tt.noID {
+				txn.ID = uuid.Nil
+			}
+			err := validateJournal(txn)
+			if tt.wantErr != errors.Is(err, ErrUnbalancedJournal) {
+				t.Fatalf("validateJournal = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
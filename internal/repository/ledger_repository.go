@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ledgerLockKey identifica el advisory lock que serializa las inserciones al libro mayor.
+const ledgerLockKey = 7_420_117
+
+// LedgerRepository lee el libro mayor. Las entradas se agregan con
+// appendLedgerEntry desde la transacción de cada evento; no hay actualización
+// ni borrado, y la base de datos rechaza ambos con un trigger.
+type LedgerRepository interface {
+	FindRange(afterSeq int64, limit int) ([]models.LedgerEntry, error)
+	FindByAggregate(aggregateType, aggregateID string) ([]models.LedgerEntry, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db}
+}
+
+// appendLedgerEntry encadena la entrada a la última y la inserta. Debe ejecutarse
+// dentro de una transacción; otros repositorios la usan para que el evento y su
+// entrada en el libro mayor se confirmen juntos.
+func appendLedgerEntry(tx *gorm.DB, entry *models.LedgerEntry) error {
+	// El lock dura hasta el fin de la transacción: dos inserciones nunca leen el mismo "último"
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", ledgerLockKey).Error; err != nil {
+		return err
+	}
+
+	var last models.LedgerEntry
+	err := tx.Order("seq DESC").Limit(1).Take(&last).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		entry.Seq = 1
+		entry.PrevHash = models.LedgerGenesisHash
+	case err != nil:
+		return err
+	default:
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+	return tx.Create(entry).Error
+}
+
+// FindRange devuelve hasta limit entradas con seq mayor a afterSeq, en orden.
+func (r *ledgerRepository) FindRange(afterSeq int64, limit int) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+	err := r.db.Where("seq > ?", afterSeq).Order("seq ASC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+func (r *ledgerRepository) FindByAggregate(aggregateType, aggregateID string) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+	err := r.db.Where("aggregate_type = ? AND aggregate_id = ?", aggregateType, aggregateID).
+		Order("seq ASC").Find(&entries).Error
+	return entries, err
+}
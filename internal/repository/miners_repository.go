@@ -2,24 +2,42 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sanchezta/batea-backend/internal/models"
 	"github.com/sanchezta/batea-backend/internal/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var ErrMinerNotFound = errors.New("minero no encontrado")
+var (
+	ErrMinerNotFound      = errors.New("minero no encontrado")
+	ErrMinerStatusChanged = errors.New("el estado del minero cambió mientras se procesaba la solicitud")
+	ErrTOTPSecretChanged  = errors.New("el secreto TOTP del minero cambió mientras se procesaba la solicitud")
+	ErrMinerDuplicate     = errors.New("ya existe un minero registrado con esta cédula o correo electrónico")
+)
 
 type MinerRepository interface {
 	Create(miner *models.Miner) error
+	CreateWithDocuments(miner *models.Miner, docs []*models.Document) error
 	FindByEmail(email string) (*models.Miner, error)
 	FindByID(id uuid.UUID) (*models.Miner, error)
 	FindByUserID(userID uuid.UUID) (*models.Miner, error)
 	FindByFirebaseUID(uid string) (*models.Miner, error)
-	Update(miner *models.Miner) error
 	Delete(id uuid.UUID) error
 	FindAllPaginated(page, limit int) (*utils.Pagination, error)
+	FindByStatusPaginated(status models.VerificationStatus, page, limit int) (*utils.Pagination, error)
+	UpdateStatus(miner *models.Miner, from models.VerificationStatus, event *models.MinerReviewEvent, entry *models.LedgerEntry) error
+	UpdateProfile(miner *models.Miner) error
+	ReplaceDocument(doc *models.Document) error
+	FindReviewEvents(minerID uuid.UUID) ([]models.MinerReviewEvent, error)
+	FindTOTPSecrets(after uuid.UUID, limit int) ([]models.Miner, error)
+	UpdateTOTPSecrets(miner *models.Miner, active, pending string) error
+	UpdateTOTPGuard(minerID uuid.UUID, fn func(guard *models.TOTPGuard) error) error
+	StartTOTPEnrollment(minerID uuid.UUID, pending string) error
+	ActivateTOTP(minerID uuid.UUID, pending string, confirmedAt time.Time, recoveryHashes []string) error
+	ConsumeRecoveryCode(minerID uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
 }
 
 type minerRepository struct {
@@ -34,6 +52,25 @@ func (r *minerRepository) Create(miner *models.Miner) error {
 	return r.db.Create(miner).Error
 }
 
+// CreateWithDocuments inserta el minero y la metadata de sus documentos en una
+// sola transacción: o quedan todos los registros o ninguno. Devuelve
+// ErrMinerDuplicate si la cédula, el correo o el usuario ya están registrados.
+func (r *minerRepository) CreateWithDocuments(miner *models.Miner, docs []*models.Document) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(miner).Error; err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		return tx.Create(&docs).Error
+	})
+	if isUniqueViolation(err) {
+		return ErrMinerDuplicate
+	}
+	return err
+}
+
 func (r *minerRepository) FindByEmail(email string) (*models.Miner, error) {
 	var miner models.Miner
 	if err := r.db.Where("email = ?", email).First(&miner).Error; err != nil {
@@ -81,10 +118,6 @@ func (r *minerRepository) FindByFirebaseUID(uid string) (*models.Miner, error) {
 	return &miner, nil
 }
 
-func (r *minerRepository) Update(miner *models.Miner) error {
-	return r.db.Save(miner).Error
-}
-
 func (r *minerRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Miner{}, id).Error
 }
@@ -93,3 +126,171 @@ func (r *minerRepository) FindAllPaginated(page, limit int) (*utils.Pagination,
 	var miners []models.Miner
 	return utils.Paginate(r.db, &models.Miner{}, page, limit, &miners)
 }
+
+func (r *minerRepository) FindByStatusPaginated(status models.VerificationStatus, page, limit int) (*utils.Pagination, error) {
+	var miners []models.Miner
+	query := r.db.Where("verification_status = ?", status).Order("created_at ASC").Session(&gorm.Session{})
+	return utils.Paginate(query, &models.Miner{}, page, limit, &miners)
+}
+
+// UpdateStatus aplica una transición de estado KYC y registra el evento y su
+// entrada del libro mayor en una sola transacción. La actualización solo procede
+// si el minero sigue en el estado from (concurrencia optimista).
+func (r *minerRepository) UpdateStatus(miner *models.Miner, from models.VerificationStatus, event *models.MinerReviewEvent, entry *models.LedgerEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Miner{}).
+			Where("id = ? AND verification_status = ?", miner.ID, from).
+			Updates(map[string]interface{}{
+				"verification_status": miner.VerificationStatus,
+				"reviewer_id":         miner.ReviewerID,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrMinerStatusChanged
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return appendLedgerEntry(tx, entry)
+	})
+}
+
+func (r *minerRepository) FindReviewEvents(minerID uuid.UUID) ([]models.MinerReviewEvent, error) {
+	var events []models.MinerReviewEvent
+	err := r.db.Preload("DocumentRejections").
+		Where("miner_id = ?", minerID).
+		Order("created_at ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// UpdateProfile actualiza solo los datos de perfil (incluida la producción declarada),
+// sin tocar el estado KYC ni los documentos.
+func (r *minerRepository) UpdateProfile(miner *models.Miner) error {
+	return r.db.Model(miner).
+		Select("full_name", "last_name", "email", "declared_annual_production_grams").
+		Updates(miner).Error
+}
+
+// ReplaceDocument registra la metadata del documento nuevo y apunta la columna
+// <kind>_path del minero a su llave, en una sola transacción.
+func (r *minerRepository) ReplaceDocument(doc *models.Document) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(doc).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Miner{}).Where("id = ?", doc.MinerID).
+			Update(string(doc.Kind)+"_path", doc.StorageKey).Error
+	})
+}
+
+// FindTOTPSecrets devuelve ID y secretos TOTP (vigente y pendiente) de hasta
+// limit mineros con ID mayor que after, incluidos los eliminados, para
+// recorrer la tabla por lotes.
+func (r *minerRepository) FindTOTPSecrets(after uuid.UUID, limit int) ([]models.Miner, error) {
+	var miners []models.Miner
+	err := r.db.Unscoped().Select("id", "totp_secret", "totp_pending_secret").
+		Where("id > ?", after).Order("id ASC").Limit(limit).
+		Find(&miners).Error
+	return miners, err
+}
+
+// UpdateTOTPSecrets reemplaza los secretos vigente y pendiente solo si siguen
+// siendo los que tiene miner, sin tocar updated_at.
+func (r *minerRepository) UpdateTOTPSecrets(miner *models.Miner, active, pending string) error {
+	result := r.db.Unscoped().Model(&models.Miner{}).
+		Where("id = ? AND totp_secret = ? AND totp_pending_secret = ?", miner.ID, miner.TOTPSecret, miner.TOTPPendingSecret).
+		UpdateColumns(map[string]interface{}{"totp_secret": active, "totp_pending_secret": pending})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPSecretChanged
+	}
+	return nil
+}
+
+// UpdateTOTPGuard bloquea el estado TOTP del minero (creándolo si no existe),
+// se lo pasa a fn y guarda lo que fn deje en él. Si fn devuelve error no se
+// guarda nada. El bloqueo serializa las validaciones simultáneas del mismo minero.
+func (r *minerRepository) UpdateTOTPGuard(minerID uuid.UUID, fn func(guard *models.TOTPGuard) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.TOTPGuard{MinerID: minerID}).Error; err != nil {
+			return err
+		}
+		var guard models.TOTPGuard
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&guard, "miner_id = ?", minerID).Error; err != nil {
+			return err
+		}
+		if err := fn(&guard); err != nil {
+			return err
+		}
+		return tx.Save(&guard).Error
+	})
+}
+
+// StartTOTPEnrollment deja pending como secreto por confirmar y revoca el
+// vigente: tras perder el teléfono el secreto anterior no debe seguir sirviendo.
+func (r *minerRepository) StartTOTPEnrollment(minerID uuid.UUID, pending string) error {
+	result := r.db.Model(&models.Miner{}).Where("id = ?", minerID).
+		Updates(map[string]interface{}{
+			"totp_secret":         "",
+			"totp_pending_secret": pending,
+			"totp_confirmed_at":   nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMinerNotFound
+	}
+	return nil
+}
+
+// ActivateTOTP convierte el secreto pendiente en el vigente, si sigue siendo
+// pending, y reemplaza los códigos de recuperación del minero en la misma transacción.
+func (r *minerRepository) ActivateTOTP(minerID uuid.UUID, pending string, confirmedAt time.Time, recoveryHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Miner{}).
+			Where("id = ? AND totp_pending_secret = ?", minerID, pending).
+			Updates(map[string]interface{}{
+				"totp_secret":         pending,
+				"totp_pending_secret": "",
+				"totp_confirmed_at":   confirmedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTOTPSecretChanged
+		}
+
+		if err := tx.Where("miner_id = ?", minerID).Delete(&models.TOTPRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.TOTPRecoveryCode, 0, len(recoveryHashes))
+		for _, hash := range recoveryHashes {
+			codes = append(codes, models.TOTPRecoveryCode{MinerID: minerID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode marca como usado el código con ese hash. Devuelve false
+// si no existe o ya se usó; el UPDATE condicional evita que se use dos veces.
+func (r *minerRepository) ConsumeRecoveryCode(minerID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.TOTPRecoveryCode{}).
+		Where("miner_id = ? AND code_hash = ? AND used_at IS NULL", minerID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
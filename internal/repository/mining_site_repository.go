@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMiningSiteNotFound = errors.New("sitio minero no encontrado")
+	ErrTitleNumberExists  = errors.New("el título minero ya está registrado")
+)
+
+type MiningSiteRepository interface {
+	Create(site *models.MiningSite) error
+	FindByID(id uuid.UUID) (*models.MiningSite, error)
+	FindByMiner(minerID uuid.UUID) ([]models.MiningSite, error)
+}
+
+type miningSiteRepository struct {
+	db *gorm.DB
+}
+
+func NewMiningSiteRepository(db *gorm.DB) MiningSiteRepository {
+	return &miningSiteRepository{db}
+}
+
+// Create inserta el sitio. El índice único de title_number decide entre dos
+// registros simultáneos del mismo título: el segundo recibe ErrTitleNumberExists.
+func (r *miningSiteRepository) Create(site *models.MiningSite) error {
+	err := r.db.Create(site).Error
+	if isUniqueViolation(err) {
+		return ErrTitleNumberExists
+	}
+	return err
+}
+
+func (r *miningSiteRepository) FindByID(id uuid.UUID) (*models.MiningSite, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *miningSiteRepository) FindByMiner(minerID uuid.UUID) ([]models.MiningSite, error) {
+	var sites []models.MiningSite
+	err := r.db.Where("miner_id = ?", minerID).Order("created_at ASC").Find(&sites).Error
+	return sites, err
+}
+
+func (r *miningSiteRepository) findOne(query *gorm.DB) (*models.MiningSite, error) {
+	var site models.MiningSite
+	if err := query.First(&site).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMiningSiteNotFound
+		}
+		return nil, err
+	}
+	return &site, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPayoutNotFound     = errors.New("pago no encontrado")
+	ErrPayoutStateChanged = errors.New("el estado del pago cambió mientras se procesaba")
+)
+
+type PayoutRepository interface {
+	CreateWithHold(p *models.Payout, hold *models.JournalTransaction) error
+	FindByID(id uuid.UUID) (*models.Payout, error)
+	FindByProviderRef(provider, ref string) (*models.Payout, error)
+	FindByMinerPaginated(minerID uuid.UUID, page, limit int) (*utils.Pagination, error)
+	FindDue(now time.Time, limit int) ([]models.Payout, error)
+	FindUnresolvedBefore(before time.Time, limit int) ([]models.Payout, error)
+	ClaimAttempt(p *models.Payout, leaseUntil time.Time) error
+	Transition(p *models.Payout, from models.PayoutStatus, journal *models.JournalTransaction) error
+}
+
+type payoutRepository struct {
+	db *gorm.DB
+}
+
+func NewPayoutRepository(db *gorm.DB) PayoutRepository {
+	return &payoutRepository{db}
+}
+
+// CreateWithHold registra el pago y retiene los fondos en la misma transacción:
+// si el saldo no alcanza no queda un pago sin respaldo, y si el insert falla no
+// quedan fondos retenidos sin pago.
+func (r *payoutRepository) CreateWithHold(p *models.Payout, hold *models.JournalTransaction) error {
+	return journalTransaction(r.db, func(tx *gorm.DB) error {
+		if _, err := postJournal(tx, hold); err != nil {
+			return err
+		}
+		return tx.Create(p).Error
+	})
+}
+
+func (r *payoutRepository) FindByID(id uuid.UUID) (*models.Payout, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *payoutRepository) FindByProviderRef(provider, ref string) (*models.Payout, error) {
+	return r.findOne(r.db.Where("provider = ? AND provider_ref = ?", provider, ref))
+}
+
+func (r *payoutRepository) FindByMinerPaginated(minerID uuid.UUID, page, limit int) (*utils.Pagination, error) {
+	var payouts []models.Payout
+	query := r.db.Where("miner_id = ?", minerID).Order("created_at DESC").Session(&gorm.Session{})
+	return utils.Paginate(query, &models.Payout{}, page, limit, &payouts)
+}
+
+// FindDue devuelve los pagos pendientes cuyo siguiente intento ya venció.
+func (r *payoutRepository) FindDue(now time.Time, limit int) ([]models.Payout, error) {
+	var payouts []models.Payout
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.PayoutPending, now).
+		Order("next_attempt_at ASC").Limit(limit).Find(&payouts).Error
+	return payouts, err
+}
+
+// FindUnresolvedBefore devuelve los pagos enviados o de resultado incierto sin
+// confirmación desde before, para consultar su estado por si el webhook nunca llegó.
+func (r *payoutRepository) FindUnresolvedBefore(before time.Time, limit int) ([]models.Payout, error) {
+	var payouts []models.Payout
+	statuses := []models.PayoutStatus{models.PayoutSubmitted, models.PayoutUnknown}
+	err := r.db.Where("status IN ? AND updated_at < ?", statuses, before).
+		Order("updated_at ASC").Limit(limit).Find(&payouts).Error
+	return payouts, err
+}
+
+// ClaimAttempt reserva el siguiente intento de envío: suma un intento y aplaza
+// next_attempt_at hasta leaseUntil para que ningún otro proceso lo tome a la vez.
+func (r *payoutRepository) ClaimAttempt(p *models.Payout, leaseUntil time.Time) error {
+	res := r.db.Model(&models.Payout{}).
+		Where("id = ? AND status = ? AND attempts = ?", p.ID, models.PayoutPending, p.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        p.Attempts + 1,
+			"next_attempt_at": leaseUntil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPayoutStateChanged
+	}
+	p.Attempts++
+	p.NextAttemptAt = &leaseUntil
+	return nil
+}
+
+// Transition guarda el nuevo estado solo si el pago sigue en from y, si se
+// indica, contabiliza en la misma transacción el asiento que lo acompaña
+// (pago o liberación de los fondos retenidos).
+func (r *payoutRepository) Transition(p *models.Payout, from models.PayoutStatus, journal *models.JournalTransaction) error {
+	return journalTransaction(r.db, func(tx *gorm.DB) error {
+		res := tx.Model(&models.Payout{}).
+			Where("id = ? AND status = ?", p.ID, from).
+			Updates(map[string]interface{}{
+				"status":          p.Status,
+				"provider_ref":    p.ProviderRef,
+				"next_attempt_at": p.NextAttemptAt,
+				"last_error":      p.LastError,
+				"completed_at":    p.CompletedAt,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPayoutStateChanged
+		}
+		if journal == nil {
+			return nil
+		}
+		_, err := postJournal(tx, journal)
+		return err
+	})
+}
+
+func (r *payoutRepository) findOne(query *gorm.DB) (*models.Payout, error) {
+	var p models.Payout
+	if err := query.First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPayoutNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
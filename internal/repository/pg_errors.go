@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Los errores de Postgres se reconocen por su SQLSTATE: el texto del mensaje
+// depende del idioma del servidor y pgx no incluye el nombre de la condición.
+
+// isUniqueViolation reconoce el error 23505 de Postgres (llave duplicada).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isSerializationFailure reconoce el error 40001 con el que Postgres aborta
+// una transacción SERIALIZABLE en conflicto; es seguro reintentarla.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "40001"
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrPhoneVerificationNotFound = errors.New("no hay un código de verificación activo")
+
+type PhoneVerificationRepository interface {
+	Create(v *models.PhoneVerification) error
+	FindLatestActive(userID uuid.UUID) (*models.PhoneVerification, error)
+	RegisterAttempt(id uuid.UUID, maxAttempts int) (bool, error)
+	MarkConsumed(id uuid.UUID) (bool, error)
+	InvalidateActive(userID uuid.UUID) error
+	CountIssuedSince(userID uuid.UUID, phoneNumber string, since time.Time) (int64, error)
+}
+
+type phoneVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewPhoneVerificationRepository(db *gorm.DB) PhoneVerificationRepository {
+	return &phoneVerificationRepository{db}
+}
+
+func (r *phoneVerificationRepository) Create(v *models.PhoneVerification) error {
+	return r.db.Create(v).Error
+}
+
+// FindLatestActive devuelve el último código no consumido del usuario (puede estar expirado).
+func (r *phoneVerificationRepository) FindLatestActive(userID uuid.UUID) (*models.PhoneVerification, error) {
+	var v models.PhoneVerification
+	err := r.db.Where("user_id = ? AND consumed_at IS NULL", userID).
+		Order("created_at DESC").
+		First(&v).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPhoneVerificationNotFound
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// RegisterAttempt suma un intento de forma atómica. Devuelve false si ya se
+// alcanzó el máximo de intentos permitidos.
+func (r *phoneVerificationRepository) RegisterAttempt(id uuid.UUID, maxAttempts int) (bool, error) {
+	res := r.db.Model(&models.PhoneVerification{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// MarkConsumed consume el código. Devuelve false si otro proceso ya lo había consumido.
+func (r *phoneVerificationRepository) MarkConsumed(id uuid.UUID) (bool, error) {
+	res := r.db.Model(&models.PhoneVerification{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// InvalidateActive marca como consumidos los códigos pendientes del usuario.
+func (r *phoneVerificationRepository) InvalidateActive(userID uuid.UUID) error {
+	return r.db.Model(&models.PhoneVerification{}).
+		Where("user_id = ? AND consumed_at IS NULL", userID).
+		Update("consumed_at", time.Now()).Error
+}
+
+// CountIssuedSince cuenta los códigos emitidos desde since para el usuario o
+// para el número, consumidos o no.
+func (r *phoneVerificationRepository) CountIssuedSince(userID uuid.UUID, phoneNumber string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PhoneVerification{}).
+		Where("(user_id = ? OR phone_number = ?) AND created_at >= ?", userID, phoneNumber, since).
+		Count(&count).Error
+	return count, err
+}
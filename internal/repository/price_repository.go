@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/pricing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrReferencePriceNotFound = errors.New("no hay precio de referencia para la fecha")
+
+type PriceRepository interface {
+	Upsert(prices []models.ReferencePrice) error
+	FindRange(from, to time.Time, kind pricing.Kind) ([]models.ReferencePrice, error)
+	FindLatest(kind pricing.Kind, onOrBefore, notBefore time.Time) (*models.ReferencePrice, error)
+}
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository(db *gorm.DB) PriceRepository {
+	return &priceRepository{db}
+}
+
+// Upsert guarda los precios; si ya hay uno para el mismo día y serie, lo reemplaza.
+func (r *priceRepository) Upsert(prices []models.ReferencePrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}, {Name: "kind"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price_per_gram_cop", "source", "updated_at"}),
+	}).Create(&prices).Error
+}
+
+// FindRange lista los precios entre from y to (inclusive); kind vacío trae todas las series.
+func (r *priceRepository) FindRange(from, to time.Time, kind pricing.Kind) ([]models.ReferencePrice, error) {
+	query := r.db.Where("date BETWEEN ? AND ?", sqlDate(from), sqlDate(to))
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var prices []models.ReferencePrice
+	err := query.Order("date ASC, kind ASC").Find(&prices).Error
+	return prices, err
+}
+
+// FindLatest devuelve el precio más reciente de la serie publicado entre notBefore y onOrBefore.
+func (r *priceRepository) FindLatest(kind pricing.Kind, onOrBefore, notBefore time.Time) (*models.ReferencePrice, error) {
+	var price models.ReferencePrice
+	err := r.db.Where("kind = ? AND date <= ? AND date >= ?", kind, sqlDate(onOrBefore), sqlDate(notBefore)).
+		Order("date DESC").First(&price).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReferencePriceNotFound
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// sqlDate pasa la fecha como texto para que Postgres no la convierta según la
+// zona horaria de la sesión al compararla con una columna date.
+func sqlDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
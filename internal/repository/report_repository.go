@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/reporting"
+	"github.com/sanchezta/batea-backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportNotFound       = errors.New("reporte no encontrado")
+	ErrSalesAlreadyReported = errors.New("una o más ventas ya fueron incluidas en otro reporte del mismo declarante")
+)
+
+// reportLinkBatch limita cuántas ventas se vinculan por INSERT.
+const reportLinkBatch = 500
+
+type ReportRepository interface {
+	FindUnreported(filter models.RegulatoryReportFilter) ([]reporting.Record, error)
+	CreateWithSales(report *models.RegulatoryReport, saleIDs []uuid.UUID) error
+	FindByID(id uuid.UUID) (*models.RegulatoryReport, error)
+	FindPaginated(reporter string, page, limit int) (*utils.Pagination, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db}
+}
+
+// FindUnreported trae las compras a mineros de subsistencia del periodo que el
+// declarante todavía no ha incluido en ningún reporte, en orden cronológico.
+func (r *reportRepository) FindUnreported(filter models.RegulatoryReportFilter) ([]reporting.Record, error) {
+	query := r.db.Table("sales s").
+		Select(`s.id AS sale_id, s.created_at AS sold_at, COALESCE(c.number, '') AS certificate_number,
+			b.nit AS buyer_nit, b.rucom_number AS buyer_rucom, b.legal_name AS buyer_legal_name,
+			m.id AS miner_id, m.full_name AS miner_first_name, m.last_name AS miner_last_name,
+			ms.municipality AS site_municipality, ms.department AS site_department,
+			COALESCE(ms.title_number, '') AS site_title_number, ms.mineral,
+			s.weight_grams, s.purity, s.fine_gold_grams, s.price_per_gram_cop, s.total_cop`).
+		Joins("JOIN miners m ON m.id = s.miner_id").
+		Joins("JOIN buyers b ON b.id = s.buyer_id").
+		Joins("JOIN mining_sites ms ON ms.id = s.origin_site_id").
+		Joins("LEFT JOIN origin_certificates c ON c.sale_id = s.id").
+		Where("m.miner_type = ?", models.SubsistenceMiner).
+		Where("s.created_at >= ? AND s.created_at < ?", filter.From, filter.To).
+		Where("NOT EXISTS (SELECT 1 FROM regulatory_report_sales rs WHERE rs.sale_id = s.id AND rs.reporter = ?)", filter.Reporter)
+	if filter.BuyerID != nil {
+		query = query.Where("s.buyer_id = ?", *filter.BuyerID)
+	}
+	if filter.Municipality != "" {
+		query = query.Where("LOWER(ms.municipality) = LOWER(?)", filter.Municipality)
+	}
+
+	var records []reporting.Record
+	err := query.Order("s.created_at ASC, s.id ASC").Scan(&records).Error
+	return records, err
+}
+
+// CreateWithSales guarda el reporte y sus ventas en una transacción. Si otra
+// presentación concurrente ya tomó alguna de las ventas, no se guarda nada.
+func (r *reportRepository) CreateWithSales(report *models.RegulatoryReport, saleIDs []uuid.UUID) error {
+	links := make([]models.RegulatoryReportSale, 0, len(saleIDs))
+	for _, id := range saleIDs {
+		links = append(links, models.RegulatoryReportSale{ReportID: report.ID, SaleID: id, Reporter: report.Reporter})
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(&links, reportLinkBatch).Error
+	})
+	if isUniqueViolation(err) {
+		return ErrSalesAlreadyReported
+	}
+	return err
+}
+
+func (r *reportRepository) FindByID(id uuid.UUID) (*models.RegulatoryReport, error) {
+	var report models.RegulatoryReport
+	if err := r.db.First(&report, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// FindPaginated lista los reportes presentados, del más reciente al más
+// antiguo; reporter vacío trae los de todos los declarantes.
+func (r *reportRepository) FindPaginated(reporter string, page, limit int) (*utils.Pagination, error) {
+	var reports []models.RegulatoryReport
+	query := r.db.Order("created_at DESC")
+	if reporter != "" {
+		query = query.Where("reporter = ?", reporter)
+	}
+	return utils.Paginate(query.Session(&gorm.Session{}), &models.RegulatoryReport{}, page, limit, &reports)
+}
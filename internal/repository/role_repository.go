@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRoleNotFound = errors.New("rol no encontrado")
+	ErrLastAdmin    = errors.New("no se puede quitar el rol al último administrador")
+)
+
+type RoleRepository interface {
+	FindAll() ([]models.Role, error)
+	FindByName(name string) (*models.Role, error)
+	AssignToUser(userID uuid.UUID, roleName string) error
+	RemoveFromUser(userID uuid.UUID, roleName string) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) FindAll() ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.db.Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// AssignToUser agrega el rol al usuario. Es idempotente.
+func (r *roleRepository) AssignToUser(userID uuid.UUID, roleName string) error {
+	role, err := r.FindByName(roleName)
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&models.User{ID: userID}).Association("Roles").Append(role)
+}
+
+// RemoveFromUser quita el rol al usuario. El rol de administrador no se quita
+// si nadie más lo tiene: el rol se bloquea para que dos revocaciones
+// simultáneas no dejen la plataforma sin administradores.
+func (r *roleRepository) RemoveFromUser(userID uuid.UUID, roleName string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", roleName).First(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+
+		if role.Name == models.RoleAdmin {
+			var others int64
+			if err := tx.Table("user_roles").
+				Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+				Where("user_roles.role_id = ? AND user_roles.user_id <> ?", role.ID, userID).
+				Count(&others).Error; err != nil {
+				return err
+			}
+			if others == 0 {
+				return ErrLastAdmin
+			}
+		}
+		return tx.Model(&models.User{ID: userID}).Association("Roles").Delete(&role)
+	})
+}
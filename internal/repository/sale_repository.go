@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSaleNotFound = errors.New("venta no encontrada")
+
+// SaleRepository solo crea y consulta: las ventas son inmutables. La única
+// escritura es CreateWithinQuota, que guarda la venta junto con su entrada del
+// libro mayor y su asiento contable.
+type SaleRepository interface {
+	CreateWithinQuota(sale *models.Sale, monthStart, yearStart time.Time, check func(models.SaleUsage) error, entry *models.LedgerEntry, journal *models.JournalTransaction) error
+	Usage(minerID uuid.UUID, monthStart, yearStart time.Time) (models.SaleUsage, error)
+	FindByID(id uuid.UUID) (*models.Sale, error)
+	FindByMinerPaginated(minerID uuid.UUID, page, limit int) (*utils.Pagination, error)
+}
+
+type saleRepository struct {
+	db *gorm.DB
+}
+
+func NewSaleRepository(db *gorm.DB) SaleRepository {
+	return &saleRepository{db}
+}
+
+// CreateWithinQuota bloquea la fila del minero, calcula lo vendido en el periodo
+// y solo inserta la venta si check lo permite. El bloqueo serializa las ventas
+// concurrentes del mismo minero, así dos compras simultáneas no superan el tope.
+// La entrada del libro mayor y el asiento contable de la venta se insertan en
+// la misma transacción, que por llevar asientos es SERIALIZABLE.
+func (r *saleRepository) CreateWithinQuota(sale *models.Sale, monthStart, yearStart time.Time, check func(models.SaleUsage) error, entry *models.LedgerEntry, journal *models.JournalTransaction) error {
+	return journalTransaction(r.db, func(tx *gorm.DB) error {
+		var locked models.Miner
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&locked, "id = ?", sale.MinerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMinerNotFound
+			}
+			return err
+		}
+
+		usage, err := usage(tx, sale.MinerID, monthStart, yearStart)
+		if err != nil {
+			return err
+		}
+		if err := check(usage); err != nil {
+			return err
+		}
+		if err := tx.Omit("Miner").Create(sale).Error; err != nil {
+			return err
+		}
+		if err := appendLedgerEntry(tx, entry); err != nil {
+			return err
+		}
+		_, err = postJournal(tx, journal)
+		return err
+	})
+}
+
+func (r *saleRepository) Usage(minerID uuid.UUID, monthStart, yearStart time.Time) (models.SaleUsage, error) {
+	return usage(r.db, minerID, monthStart, yearStart)
+}
+
+// usage suma los gramos brutos vendidos desde monthStart y desde yearStart.
+func usage(db *gorm.DB, minerID uuid.UUID, monthStart, yearStart time.Time) (models.SaleUsage, error) {
+	var u models.SaleUsage
+	err := db.Model(&models.Sale{}).
+		Select(`COALESCE(SUM(weight_grams) FILTER (WHERE created_at >= ?), 0) AS month_grams,
+			COALESCE(SUM(weight_grams), 0) AS year_grams`, monthStart).
+		Where("miner_id = ? AND created_at >= ?", minerID, yearStart).
+		Scan(&u).Error
+	return u, err
+}
+
+// FindByID carga la venta con su minero, necesario para verificar quién puede verla.
+func (r *saleRepository) FindByID(id uuid.UUID) (*models.Sale, error) {
+	var sale models.Sale
+	if err := r.db.Preload("Miner").First(&sale, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSaleNotFound
+		}
+		return nil, err
+	}
+	return &sale, nil
+}
+
+func (r *saleRepository) FindByMinerPaginated(minerID uuid.UUID, page, limit int) (*utils.Pagination, error) {
+	var sales []models.Sale
+	query := r.db.Where("miner_id = ?", minerID).Order("created_at DESC").Session(&gorm.Session{})
+	return utils.Paginate(query, &models.Sale{}, page, limit, &sales)
+}
@@ -13,6 +13,7 @@ type UserRepository interface {
 	Create(user *models.User) error
 	FindByPhone(phone string) (*models.User, error)
 	FindByID(id string) (*models.User, error)
+	FindByIDWithRoles(id string) (*models.User, error)
 	FindByFirebaseUID(uid string) (*models.User, error)
 	UpdateVerificationStatus(id string, verified bool) error
 }
@@ -51,6 +52,18 @@ func (r *userRepository) FindByID(id string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByIDWithRoles carga el usuario junto con sus roles y permisos.
+func (r *userRepository) FindByIDWithRoles(id string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("Roles.Permissions").First(&user, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) FindByFirebaseUID(uid string) (*models.User, error) {
 	var user models.User
 	if err := r.db.Where("firebase_uid = ?", uid).First(&user).Error; err != nil {
@@ -0,0 +1,164 @@
+// Package secrets cifra secretos pequeños, como la semilla TOTP de cada minero,
+// con cifrado de sobre: cada valor se cifra con AES-256-GCM bajo una llave de
+// datos aleatoria, y esa llave se envuelve con una llave maestra. El ID de la
+// llave maestra queda como prefijo del texto cifrado, de modo que se puede
+// rotar sin perder la capacidad de abrir lo cifrado antes.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marca un valor cifrado. Formato completo:
+//
+//	enc:v1:<id de llave>:<llave de datos envuelta>:<nonce||texto cifrado>
+//
+// con las dos últimas partes en base64 URL sin relleno.
+const sealedPrefix = "enc:v1:"
+
+var (
+	ErrUnknownKey = errors.New("llave maestra de cifrado desconocida")
+	ErrMalformed  = errors.New("el secreto cifrado no tiene un formato válido")
+	ErrDecrypt    = errors.New("no se pudo descifrar el secreto: la llave o los datos no corresponden")
+)
+
+// KeyProvider guarda las llaves maestras. Solo envuelve y desenvuelve llaves
+// de datos, así que puede implementarse sobre un KMS sin exponer la maestra.
+type KeyProvider interface {
+	CurrentKeyID() (string, error)
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Envelope cifra y descifra valores con las llaves de un KeyProvider.
+type Envelope struct {
+	keys KeyProvider
+}
+
+func NewEnvelope(keys KeyProvider) *Envelope {
+	return &Envelope{keys: keys}
+}
+
+// Seal cifra plaintext con la llave maestra vigente. aad (por ejemplo, el ID
+// del dueño) se autentica pero no se guarda: Open debe recibir el mismo valor,
+// así un secreto copiado a otra fila no se puede abrir.
+func (e *Envelope) Seal(plaintext, aad []byte) (string, error) {
+	keyID, err := e.keys.CurrentKeyID()
+	if err != nil {
+		return "", err
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := e.keys.WrapKey(keyID, dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := gcmSeal(dataKey, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return sealedPrefix + keyID + ":" + enc.EncodeToString(wrapped) + ":" + enc.EncodeToString(ciphertext), nil
+}
+
+// Open descifra un valor producido por Seal.
+func (e *Envelope) Open(sealed string, aad []byte) ([]byte, error) {
+	keyID, wrapped, ciphertext, err := parse(sealed)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := e.keys.UnwrapKey(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcmOpen(dataKey, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// NeedsRekey indica si el valor está en claro o cifrado con una llave que ya
+// no es la vigente.
+func (e *Envelope) NeedsRekey(value string) (bool, error) {
+	keyID, ok := KeyIDOf(value)
+	if !ok {
+		return true, nil
+	}
+	current, err := e.keys.CurrentKeyID()
+	if err != nil {
+		return false, err
+	}
+	return keyID != current, nil
+}
+
+// IsSealed indica si el valor fue producido por Seal (los anteriores a la
+// introducción del cifrado se guardaron en claro).
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// KeyIDOf devuelve el ID de la llave maestra con que se cifró el valor.
+func KeyIDOf(value string) (string, bool) {
+	keyID, _, _, err := parse(value)
+	return keyID, err == nil
+}
+
+func parse(sealed string) (keyID string, wrapped, ciphertext []byte, err error) {
+	if !IsSealed(sealed) {
+		return "", nil, nil, ErrMalformed
+	}
+	parts := strings.Split(strings.TrimPrefix(sealed, sealedPrefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrMalformed
+	}
+	enc := base64.RawURLEncoding
+	if wrapped, err = enc.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if ciphertext, err = enc.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return parts[0], wrapped, ciphertext, nil
+}
+
+// gcmSeal cifra con AES-GCM y antepone el nonce aleatorio al resultado.
+func gcmSeal(key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func gcmOpen(key, sealed, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeyFile(t *testing.T) *KeyFile {
+	t.Helper()
+	f, created, err := OpenOrCreateKeyFile(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil || !created {
+		t.Fatalf("OpenOrCreateKeyFile = %v, creado %v", err, created)
+	}
+	return f
+}
+
+func TestEnvelopeRoundTripAcrossRotation(t *testing.T) {
+	keys := newTestKeyFile(t)
+	env := NewEnvelope(keys)
+	aad := []byte("miner-1")
+
+	oldKey, _ := keys.CurrentKeyID()
+	before, err := env.Seal([]byte("JBSWY3DPEHPK3PXP"), aad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	newKey, err := keys.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if newKey == oldKey {
+		t.Fatal("Rotate no cambió la llave vigente")
+	}
+	after, err := env.Seal([]byte("KRSXG5CTMVRXEZLU"), aad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		sealed    string
+		wantKey   string
+		wantPlain string
+		wantRekey bool
+	}{
+		{name: "cifrado antes de rotar", sealed: before, wantKey: oldKey, wantPlain: "JBSWY3DPEHPK3PXP", wantRekey: true},
+		{name: "cifrado con la llave vigente", sealed: after, wantKey: newKey, wantPlain: "KRSXG5CTMVRXEZLU"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if id, ok := KeyIDOf(tt.sealed); !ok || id != tt.wantKey {
+				t.Fatalf("KeyIDOf = %q, %v; se esperaba %q", id, ok, tt.wantKey)
+			}
+			plain, err := env.Open(tt.sealed, aad)
+			if err != nil || string(plain) != tt.wantPlain {
+				t.Fatalf("Open = %q, %v; se esperaba %q", plain, err, tt.wantPlain)
+			}
+			if rekey, err := env.NeedsRekey(tt.sealed); err != nil || rekey != tt.wantRekey {
+				t.Fatalf("NeedsRekey = %v, %v; se esperaba %v", rekey, err, tt.wantRekey)
+			}
+		})
+	}
+
+	// Re-cifrar con la llave vigente y podar deja ilegible solo lo que no se re-cifró
+	plain, _ := env.Open(before, aad)
+	rekeyed, err := env.Seal(plain, aad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if removed, err := keys.Prune(); err != nil || removed != 1 {
+		t.Fatalf("Prune = %d, %v; se esperaba 1 llave borrada", removed, err)
+	}
+	if got, err := env.Open(rekeyed, aad); err != nil || string(got) != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Open tras podar = %q, %v", got, err)
+	}
+	if _, err := env.Open(before, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Open con llave podada = %v, se esperaba ErrUnknownKey", err)
+	}
+}
+
+func TestEnvelopeRejectsTampering(t *testing.T) {
+	env := NewEnvelope(newTestKeyFile(t))
+	sealed, err := env.Seal([]byte("JBSWY3DPEHPK3PXP"), []byte("miner-1"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	parts := strings.Split(sealed, ":")
+
+	// flip cambia un carácter base64 de la parte indicada sin romper la codificación
+	flip := func(part int) string {
+		p := append([]string(nil), parts...)
+		b := []byte(p[part])
+		if b[len(b)/2] == 'A' {
+			b[len(b)/2] = 'B'
+		} else {
+			b[len(b)/2] = 'A'
+		}
+		p[part] = string(b)
+		return strings.Join(p, ":")
+	}
+
+	tests := []struct {
+		name    string
+		sealed  string
+		aad     string
+		wantErr error
+	}{
+		{name: "otro dueño", sealed: sealed, aad: "miner-2", wantErr: ErrDecrypt},
+		{name: "texto cifrado alterado", sealed: flip(4), aad: "miner-1", wantErr: ErrDecrypt},
+		{name: "llave de datos alterada", sealed: flip(3), aad: "miner-1", wantErr: ErrDecrypt},
+		{name: "llave maestra desconocida", sealed: strings.Replace(sealed, parts[2], "k19990101-000000", 1), aad: "miner-1", wantErr: ErrUnknownKey},
+		{name: "valor en claro", sealed: "JBSWY3DPEHPK3PXP", aad: "miner-1", wantErr: ErrMalformed},
+		{name: "partes faltantes", sealed: "enc:v1:" + parts[2], aad: "miner-1", wantErr: ErrMalformed},
+		{name: "base64 inválido", sealed: "enc:v1:" + parts[2] + ":!!!:" + parts[4], aad: "miner-1", wantErr: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.Open(tt.sealed, []byte(tt.aad)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Open = %v, se esperaba %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNeedsRekeyPlaintext(t *testing.T) {
+	env := NewEnvelope(newTestKeyFile(t))
+	// Los secretos anteriores al cifrado están en claro y deben cifrarse
+	if rekey, err := env.NeedsRekey("JBSWY3DPEHPK3PXP"); err != nil || !rekey {
+		t.Fatalf("NeedsRekey = %v, %v; se esperaba true", rekey, err)
+	}
+	if IsSealed("JBSWY3DPEHPK3PXP") {
+		t.Fatal("IsSealed reconoce un valor en claro como cifrado")
+	}
+}
@@ -0,0 +1,229 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyFile es un KeyProvider con las llaves maestras en un archivo JSON local:
+//
+//	{"current": "k20261017-3fa9c1", "keys": {"k20261017-3fa9c1": "<32 bytes en base64>"}}
+//
+// El archivo se relee cuando cambia su fecha de modificación, así una rotación
+// hecha con el comando totprekey llega al servidor sin reiniciarlo.
+type KeyFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	current string
+	keys    map[string][]byte
+}
+
+type keyFileContents struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// OpenKeyFile carga un archivo de llaves existente.
+func OpenKeyFile(path string) (*KeyFile, error) {
+	f := &KeyFile{path: path}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// OpenOrCreateKeyFile carga el archivo o, si no existe, lo crea con una llave
+// nueva (permisos 0600). created indica si se creó.
+func OpenOrCreateKeyFile(path string) (f *KeyFile, created bool, err error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		f = &KeyFile{path: path, keys: map[string][]byte{}}
+		if _, err := f.Rotate(); err != nil {
+			return nil, false, err
+		}
+		return f, true, nil
+	}
+	f, err = OpenKeyFile(path)
+	return f, false, err
+}
+
+func (f *KeyFile) CurrentKeyID() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.refresh(); err != nil {
+		return "", err
+	}
+	return f.current, nil
+}
+
+func (f *KeyFile) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	master, err := f.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return gcmSeal(master, dataKey, []byte(keyID))
+}
+
+func (f *KeyFile) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	master, err := f.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := gcmOpen(master, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return dataKey, nil
+}
+
+// Rotate agrega una llave nueva, la deja vigente y devuelve su ID. Las
+// anteriores se conservan para abrir lo que se cifró con ellas.
+func (f *KeyFile) Rotate() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.current != "" {
+		if err := f.refresh(); err != nil {
+			return "", err
+		}
+	}
+
+	// El ID lleva la fecha para distinguir las llaves a simple vista
+	suffix := make([]byte, 3)
+	key := make([]byte, 32)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("k%s-%x", time.Now().UTC().Format("20060102"), suffix)
+
+	keys := make(map[string][]byte, len(f.keys)+1)
+	for k, v := range f.keys {
+		keys[k] = v
+	}
+	keys[id] = key
+	if err := f.write(id, keys); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Prune borra del archivo todas las llaves salvo la vigente. Solo debe usarse
+// cuando ningún secreto guardado depende de ellas.
+func (f *KeyFile) Prune() (removed int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.refresh(); err != nil {
+		return 0, err
+	}
+	removed = len(f.keys) - 1
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, f.write(f.current, map[string][]byte{f.current: f.keys[f.current]})
+}
+
+func (f *KeyFile) key(keyID string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.refresh(); err != nil {
+		return nil, err
+	}
+	key, ok := f.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	return key, nil
+}
+
+// refresh relee el archivo si cambió desde la última lectura.
+func (f *KeyFile) refresh() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("archivo de llaves %s: %w", f.path, err)
+	}
+	if info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+	return f.reload()
+}
+
+func (f *KeyFile) reload() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("archivo de llaves %s: %w", f.path, err)
+	}
+	raw, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("archivo de llaves %s: %w", f.path, err)
+	}
+	var contents keyFileContents
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return fmt.Errorf("archivo de llaves %s: %w", f.path, err)
+	}
+
+	keys := make(map[string][]byte, len(contents.Keys))
+	for id, encoded := range contents.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return fmt.Errorf("archivo de llaves %s: ID de llave inválido %q", f.path, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("archivo de llaves %s: la llave %s debe tener 32 bytes en base64", f.path, id)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[contents.Current]; !ok {
+		return fmt.Errorf("archivo de llaves %s: la llave vigente %q no está en el archivo", f.path, contents.Current)
+	}
+
+	f.current, f.keys, f.modTime = contents.Current, keys, info.ModTime()
+	return nil
+}
+
+// write guarda el archivo de forma atómica (archivo temporal y rename).
+func (f *KeyFile) write(current string, keys map[string][]byte) error {
+	contents := keyFileContents{Current: current, Keys: make(map[string]string, len(keys))}
+	for id, key := range keys {
+		contents.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	}
+	raw, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".keys-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+	return f.reload()
+}
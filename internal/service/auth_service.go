@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/sanchezta/batea-backend/internal/identity"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("número de teléfono o contraseña incorrectos")
+	ErrInvalidRefreshToken = errors.New("refresh token inválido, expirado o revocado")
+)
+
+// AuthService define la lógica de autenticación y emisión de tokens.
+type AuthService interface {
+	Login(req *models.LoginRequest) (*models.TokenResponse, error)
+	LoginWithIDToken(idToken string) (*models.TokenResponse, error)
+	Refresh(refreshToken string) (*models.TokenResponse, error)
+	Logout(refreshToken string) error
+	ValidateAccessToken(accessToken string) (uuid.UUID, error)
+}
+
+type authService struct {
+	userRepo    repository.UserRepository
+	minerRepo   repository.MinerRepository
+	refreshRepo repository.RefreshTokenRepository
+	verifier    identity.Verifier // nil si Identity Platform no está configurado
+	cfg         *config.Config
+}
+
+// NewAuthService crea una nueva instancia del servicio de autenticación.
+func NewAuthService(
+	userRepo repository.UserRepository,
+	minerRepo repository.MinerRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	verifier identity.Verifier,
+	cfg *config.Config,
+) AuthService {
+	return &authService{
+		userRepo:    userRepo,
+		minerRepo:   minerRepo,
+		refreshRepo: refreshRepo,
+		verifier:    verifier,
+		cfg:         cfg,
+	}
+}
+
+// Login verifica teléfono y contraseña (bcrypt) y emite un par de tokens.
+func (s *authService) Login(req *models.LoginRequest) (*models.TokenResponse, error) {
+	user, err := s.userRepo.FindByPhone(req.PhoneNumber)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Los usuarios creados vía Identity Platform no tienen contraseña local
+	if user.PasswordHash == "" || !utils.CheckPassword(user.PasswordHash, req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokens(user.ID, s.minerIDForUser(user.ID))
+}
+
+// LoginWithIDToken verifica un ID token de Identity Platform y emite un par de tokens
+// para el usuario vinculado a ese UID.
+func (s *authService) LoginWithIDToken(idToken string) (*models.TokenResponse, error) {
+	if s.verifier == nil {
+		return nil, ErrIdentityDisabled
+	}
+	claims, err := s.verifier.Verify(idToken)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidIDToken) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByFirebaseUID(claims.UID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Si Identity Platform ya verificó el teléfono, se refleja en el usuario
+	if claims.PhoneVerified && !user.IsVerified && claims.PhoneNumber == user.PhoneNumber {
+		if err := s.userRepo.UpdateVerificationStatus(user.ID.String(), true); err != nil {
+			log.Printf("Error al actualizar la verificación del usuario %s: %v", user.ID, err)
+		}
+	}
+
+	var minerID *uuid.UUID
+	if miner, err := s.minerRepo.FindByFirebaseUID(claims.UID); err == nil {
+		minerID = &miner.ID
+	} else if !errors.Is(err, repository.ErrMinerNotFound) {
+		return nil, err
+	}
+
+	return s.issueTokens(user.ID, minerID)
+}
+
+// Refresh rota el refresh token: revoca el presentado y emite uno nuevo.
+// Si se presenta un token ya revocado se asume robo y se revocan todas las sesiones del usuario.
+func (s *authService) Refresh(refreshToken string) (*models.TokenResponse, error) {
+	stored, err := s.refreshRepo.FindByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		s.revokeFamily(stored.UserID)
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	raw, next, err := s.newRefreshToken(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Rotate(stored.ID, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenRevoked) {
+			s.revokeFamily(stored.UserID)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return s.buildResponse(stored.UserID, raw, s.minerIDForUser(stored.UserID))
+}
+
+// Logout revoca el refresh token presentado. Es idempotente.
+func (s *authService) Logout(refreshToken string) error {
+	stored, err := s.refreshRepo.FindByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.refreshRepo.Revoke(stored.ID)
+}
+
+// ValidateAccessToken verifica un token de acceso y devuelve el ID del usuario.
+func (s *authService) ValidateAccessToken(accessToken string) (uuid.UUID, error) {
+	return utils.ParseAccessToken(s.cfg.JWTSecret, accessToken)
+}
+
+func (s *authService) issueTokens(userID uuid.UUID, minerID *uuid.UUID) (*models.TokenResponse, error) {
+	raw, token, err := s.newRefreshToken(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Create(token); err != nil {
+		return nil, err
+	}
+	return s.buildResponse(userID, raw, minerID)
+}
+
+// minerIDForUser devuelve el ID del minero del usuario, o nil si no tiene.
+func (s *authService) minerIDForUser(userID uuid.UUID) *uuid.UUID {
+	miner, err := s.minerRepo.FindByUserID(userID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrMinerNotFound) {
+			log.Printf("Error al buscar el minero del usuario %s: %v", userID, err)
+		}
+		return nil
+	}
+	return &miner.ID
+}
+
+func (s *authService) newRefreshToken(userID uuid.UUID) (string, *models.RefreshToken, error) {
+	raw, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	}, nil
+}
+
+func (s *authService) buildResponse(userID uuid.UUID, refreshToken string, minerID *uuid.UUID) (*models.TokenResponse, error) {
+	access, err := utils.GenerateAccessToken(s.cfg.JWTSecret, userID, s.cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.cfg.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		UserID:       userID,
+		MinerID:      minerID,
+	}, nil
+}
+
+func (s *authService) revokeFamily(userID uuid.UUID) {
+	if err := s.refreshRepo.RevokeAllForUser(userID); err != nil {
+		log.Printf("Error al revocar las sesiones del usuario %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/storage"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+var (
+	ErrBuyerExists      = errors.New("el usuario ya tiene un registro de comercializador")
+	ErrBuyerNotApproved = errors.New("el comercializador no ha sido aprobado y no puede registrar compras")
+	ErrBuyerTaken       = errors.New("ya existe un comercializador con este NIT o número RUCOM")
+	ErrNotBuyerOwner    = errors.New("solo el comercializador dueño del registro puede modificarlo")
+	ErrBuyerNotPending  = errors.New("el comercializador ya fue revisado")
+	ErrPointInactive    = errors.New("el punto de compra está desactivado")
+	ErrPointNotApproved = errors.New("el punto de compra no ha sido aprobado por un administrador")
+	ErrPointNotPending  = errors.New("el punto de compra ya fue revisado")
+)
+
+// BuyerService gestiona el registro de comercializadores, sus puntos de compra
+// y la aprobación de ambos por parte de un administrador.
+type BuyerService interface {
+	RegisterBuyer(userID uuid.UUID, req *models.CreateBuyerRequest, files map[string]*multipart.FileHeader) (*models.Buyer, error)
+	GetBuyer(id uuid.UUID) (*models.Buyer, error)
+	GetBuyerByUserID(userID uuid.UUID) (*models.Buyer, error)
+	OpenDocument(buyer *models.Buyer, kind models.DocumentKind) (io.ReadCloser, string, error)
+	AddPurchasePoint(buyerID, userID uuid.UUID, req *models.CreatePurchasePointRequest) (*models.BuyerPurchasePoint, error)
+	DeactivatePurchasePoint(buyerID, userID, pointID uuid.UUID) error
+	ListBuyers(status models.BuyerStatus, page, limit int) (*utils.Pagination, error)
+	ApproveBuyer(buyerID, adminID uuid.UUID) (*models.Buyer, error)
+	RejectBuyer(buyerID, adminID uuid.UUID, reason string) (*models.Buyer, error)
+	ListPurchasePoints(status models.PurchasePointStatus, page, limit int) (*utils.Pagination, error)
+	ApprovePurchasePoint(pointID, adminID uuid.UUID) (*models.BuyerPurchasePoint, error)
+	RejectPurchasePoint(pointID, adminID uuid.UUID, reason string) (*models.BuyerPurchasePoint, error)
+
+	// AuthorizePurchase verifica que el usuario sea un comercializador aprobado
+	// y que el punto de compra le pertenezca, esté aprobado y esté activo.
+	AuthorizePurchase(userID, pointID uuid.UUID) (*models.Buyer, *models.BuyerPurchasePoint, error)
+}
+
+type buyerService struct {
+	repo     repository.BuyerRepository
+	roleRepo repository.RoleRepository
+	store    storage.Storage
+}
+
+func NewBuyerService(repo repository.BuyerRepository, roleRepo repository.RoleRepository, store storage.Storage) BuyerService {
+	return &buyerService{repo: repo, roleRepo: roleRepo, store: store}
+}
+
+func (s *buyerService) RegisterBuyer(userID uuid.UUID, req *models.CreateBuyerRequest, files map[string]*multipart.FileHeader) (*models.Buyer, error) {
+	if _, err := s.repo.FindByUserID(userID); err == nil {
+		return nil, ErrBuyerExists
+	} else if !errors.Is(err, repository.ErrBuyerNotFound) {
+		return nil, err
+	}
+
+	for _, kind := range models.BuyerDocumentKinds {
+		if err := utils.ValidateFile(buyerDocumentField(files[string(kind)])); err != nil {
+			return nil, fmt.Errorf("error de validación en '%s': %w", kind, err)
+		}
+	}
+
+	buyer := &models.Buyer{
+		ID:          uuid.New(),
+		UserID:      userID,
+		LegalName:   strings.TrimSpace(req.LegalName),
+		NIT:         strings.TrimSpace(req.NIT),
+		RUCOMNumber: strings.TrimSpace(req.RUCOMNumber),
+		Status:      models.BuyerPending,
+	}
+
+	var docs []*models.Document
+	for _, kind := range models.BuyerDocumentKinds {
+		doc, err := uploadDocument(s.store, "buyers/"+buyer.ID.String(), kind, files[string(kind)])
+		if err != nil {
+			discardDocuments(s.store, docs)
+			return nil, fmt.Errorf("fallo al guardar archivo %s: %w", kind, err)
+		}
+		doc.BuyerID = &buyer.ID
+		docs = append(docs, doc)
+	}
+
+	if err := s.repo.CreateWithDocuments(buyer, docs); err != nil {
+		discardDocuments(s.store, docs)
+		if errors.Is(err, repository.ErrBuyerDuplicate) {
+			return nil, ErrBuyerTaken
+		}
+		return nil, fmt.Errorf("fallo al guardar el comercializador: %w", err)
+	}
+	return s.repo.FindByID(buyer.ID)
+}
+
+func (s *buyerService) GetBuyer(id uuid.UUID) (*models.Buyer, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *buyerService) GetBuyerByUserID(userID uuid.UUID) (*models.Buyer, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+// OpenDocument abre el certificado más reciente del tipo indicado.
+func (s *buyerService) OpenDocument(buyer *models.Buyer, kind models.DocumentKind) (io.ReadCloser, string, error) {
+	for _, doc := range buyer.Documents { // ordenados del más reciente al más antiguo
+		if doc.Kind != kind {
+			continue
+		}
+		r, err := s.store.Get(context.Background(), doc.StorageKey)
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotFound) {
+				return nil, "", ErrDocumentNotFound
+			}
+			return nil, "", fmt.Errorf("error al abrir el documento: %w", err)
+		}
+		contentType := doc.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(doc.StorageKey))
+		}
+		return r, contentType, nil
+	}
+	return nil, "", ErrDocumentNotFound
+}
+
+func (s *buyerService) AddPurchasePoint(buyerID, userID uuid.UUID, req *models.CreatePurchasePointRequest) (*models.BuyerPurchasePoint, error) {
+	buyer, err := s.ownedBuyer(buyerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	point := &models.BuyerPurchasePoint{
+		BuyerID:      buyer.ID,
+		Name:         strings.TrimSpace(req.Name),
+		Address:      strings.TrimSpace(req.Address),
+		Municipality: strings.TrimSpace(req.Municipality),
+		Department:   strings.TrimSpace(req.Department),
+		Active:       true,
+		Status:       models.PointPending, // Queda en la cola de revisión
+	}
+	if err := s.repo.AddPurchasePoint(point); err != nil {
+		return nil, fmt.Errorf("fallo al guardar el punto de compra: %w", err)
+	}
+	return point, nil
+}
+
+func (s *buyerService) DeactivatePurchasePoint(buyerID, userID, pointID uuid.UUID) error {
+	buyer, err := s.ownedBuyer(buyerID, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeactivatePurchasePoint(buyer.ID, pointID)
+}
+
+func (s *buyerService) ListBuyers(status models.BuyerStatus, page, limit int) (*utils.Pagination, error) {
+	return s.repo.FindByStatusPaginated(status, page, limit)
+}
+
+// ApproveBuyer aprueba al comercializador y le otorga el rol buyer. El rol se
+// asigna primero: si luego falla la actualización, el usuario tiene el rol pero
+// AuthorizePurchase lo sigue bloqueando y la aprobación puede reintentarse.
+func (s *buyerService) ApproveBuyer(buyerID, adminID uuid.UUID) (*models.Buyer, error) {
+	buyer, err := s.repo.FindByID(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if buyer.Status != models.BuyerPending {
+		return nil, ErrBuyerNotPending
+	}
+	if err := s.roleRepo.AssignToUser(buyer.UserID, models.RoleBuyer); err != nil {
+		return nil, fmt.Errorf("fallo al asignar el rol de comercializador: %w", err)
+	}
+	return s.review(buyer, models.BuyerApproved, adminID, "")
+}
+
+func (s *buyerService) RejectBuyer(buyerID, adminID uuid.UUID, reason string) (*models.Buyer, error) {
+	buyer, err := s.repo.FindByID(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if buyer.Status != models.BuyerPending {
+		return nil, ErrBuyerNotPending
+	}
+	return s.review(buyer, models.BuyerRejected, adminID, reason)
+}
+
+// review guarda la decisión del administrador sobre una solicitud pendiente.
+func (s *buyerService) review(buyer *models.Buyer, to models.BuyerStatus, adminID uuid.UUID, reason string) (*models.Buyer, error) {
+	now := time.Now()
+	buyer.Status = to
+	buyer.ReviewedBy = &adminID
+	buyer.ReviewedAt = &now
+	buyer.RejectionReason = reason
+
+	entry, err := newLedgerEntry(models.LedgerBuyerReviewed, models.AggregateBuyer, buyer.ID, map[string]any{
+		"buyer_id":     buyer.ID,
+		"nit":          buyer.NIT,
+		"rucom_number": buyer.RUCOMNumber,
+		"from_status":  models.BuyerPending,
+		"to_status":    to,
+		"reviewed_by":  adminID,
+		"reason":       reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateStatus(buyer, models.BuyerPending, entry); err != nil {
+		if errors.Is(err, repository.ErrBuyerStatusChanged) {
+			return nil, ErrBuyerNotPending
+		}
+		return nil, err
+	}
+	return buyer, nil
+}
+
+func (s *buyerService) ListPurchasePoints(status models.PurchasePointStatus, page, limit int) (*utils.Pagination, error) {
+	return s.repo.FindPurchasePointsByStatusPaginated(status, page, limit)
+}
+
+func (s *buyerService) ApprovePurchasePoint(pointID, adminID uuid.UUID) (*models.BuyerPurchasePoint, error) {
+	return s.reviewPoint(pointID, models.PointApproved, adminID, "")
+}
+
+func (s *buyerService) RejectPurchasePoint(pointID, adminID uuid.UUID, reason string) (*models.BuyerPurchasePoint, error) {
+	return s.reviewPoint(pointID, models.PointRejected, adminID, reason)
+}
+
+// reviewPoint guarda la decisión del administrador sobre un punto pendiente.
+// La dirección revisada queda en el libro mayor.
+func (s *buyerService) reviewPoint(pointID uuid.UUID, to models.PurchasePointStatus, adminID uuid.UUID, reason string) (*models.BuyerPurchasePoint, error) {
+	point, err := s.repo.FindPurchasePointByID(pointID)
+	if err != nil {
+		return nil, err
+	}
+	if point.Status != models.PointPending {
+		return nil, ErrPointNotPending
+	}
+
+	now := time.Now()
+	point.Status = to
+	point.ReviewedBy = &adminID
+	point.ReviewedAt = &now
+	point.RejectionReason = reason
+
+	entry, err := newLedgerEntry(models.LedgerPointReviewed, models.AggregatePoint, point.ID, map[string]any{
+		"point_id":     point.ID,
+		"buyer_id":     point.BuyerID,
+		"name":         point.Name,
+		"address":      point.Address,
+		"municipality": point.Municipality,
+		"department":   point.Department,
+		"from_status":  models.PointPending,
+		"to_status":    to,
+		"reviewed_by":  adminID,
+		"reason":       reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdatePurchasePointStatus(point, models.PointPending, entry); err != nil {
+		if errors.Is(err, repository.ErrPointStatusChanged) {
+			return nil, ErrPointNotPending
+		}
+		return nil, err
+	}
+	return point, nil
+}
+
+func (s *buyerService) AuthorizePurchase(userID, pointID uuid.UUID) (*models.Buyer, *models.BuyerPurchasePoint, error) {
+	buyer, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBuyerNotFound) {
+			return nil, nil, ErrBuyerNotApproved
+		}
+		return nil, nil, err
+	}
+	if buyer.Status != models.BuyerApproved {
+		return nil, nil, ErrBuyerNotApproved
+	}
+	point, err := s.repo.FindPurchasePoint(buyer.ID, pointID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if point.Status != models.PointApproved {
+		return nil, nil, ErrPointNotApproved
+	}
+	if !point.Active {
+		return nil, nil, ErrPointInactive
+	}
+	return buyer, point, nil
+}
+
+// ownedBuyer carga el comercializador y verifica que pertenezca al usuario.
+func (s *buyerService) ownedBuyer(buyerID, userID uuid.UUID) (*models.Buyer, error) {
+	buyer, err := s.repo.FindByID(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if buyer.UserID != userID {
+		return nil, ErrNotBuyerOwner
+	}
+	return buyer, nil
+}
+
+// buyerDocumentField arma las reglas de validación de un certificado (PDF obligatorio).
+func buyerDocumentField(file *multipart.FileHeader) models.DocumentField {
+	return models.DocumentField{
+		FileHeader:       file,
+		Required:         true,
+		MaxSizeBytes:     models.BuyerCertificateMaxSize,
+		AllowedMimeTypes: []string{"application/pdf"},
+		MaxPages:         models.BuyerCertificateMaxPages,
+	}
+}
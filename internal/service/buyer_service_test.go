@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+// pointRepo es un BuyerRepository en memoria con un comercializador y sus
+// puntos de compra; recuerda las entradas del libro mayor.
+type pointRepo struct {
+	repository.BuyerRepository
+	buyer  models.Buyer
+	points map[uuid.UUID]models.BuyerPurchasePoint
+	ledger []*models.LedgerEntry
+}
+
+func newPointRepo() *pointRepo {
+	return &pointRepo{
+		buyer:  models.Buyer{ID: uuid.New(), UserID: uuid.New(), Status: models.BuyerApproved},
+		points: make(map[uuid.UUID]models.BuyerPurchasePoint),
+	}
+}
+
+func (r *pointRepo) FindByID(id uuid.UUID) (*models.Buyer, error) {
+	if id != r.buyer.ID {
+		return nil, repository.ErrBuyerNotFound
+	}
+	b := r.buyer
+	return &b, nil
+}
+
+func (r *pointRepo) FindByUserID(userID uuid.UUID) (*models.Buyer, error) {
+	if userID != r.buyer.UserID {
+		return nil, repository.ErrBuyerNotFound
+	}
+	b := r.buyer
+	return &b, nil
+}
+
+func (r *pointRepo) AddPurchasePoint(point *models.BuyerPurchasePoint) error {
+	point.ID = uuid.New()
+	r.points[point.ID] = *point
+	return nil
+}
+
+func (r *pointRepo) FindPurchasePoint(buyerID, pointID uuid.UUID) (*models.BuyerPurchasePoint, error) {
+	p, err := r.FindPurchasePointByID(pointID)
+	if err == nil && p.BuyerID != buyerID {
+		return nil, repository.ErrPurchasePointNotFound
+	}
+	return p, err
+}
+
+func (r *pointRepo) FindPurchasePointByID(pointID uuid.UUID) (*models.BuyerPurchasePoint, error) {
+	p, ok := r.points[pointID]
+	if !ok {
+		return nil, repository.ErrPurchasePointNotFound
+	}
+	return &p, nil
+}
+
+func (r *pointRepo) UpdatePurchasePointStatus(point *models.BuyerPurchasePoint, from models.PurchasePointStatus, entry *models.LedgerEntry) error {
+	if r.points[point.ID].Status != from {
+		return repository.ErrPointStatusChanged
+	}
+	r.points[point.ID] = *point
+	r.ledger = append(r.ledger, entry)
+	return nil
+}
+
+func (r *pointRepo) DeactivatePurchasePoint(buyerID, pointID uuid.UUID) error {
+	p := r.points[pointID]
+	p.Active = false
+	r.points[pointID] = p
+	return nil
+}
+
+func TestPurchasePointNeedsApproval(t *testing.T) {
+	repo := newPointRepo()
+	s := NewBuyerService(repo, nil, nil)
+	adminID := uuid.New()
+	req := &models.CreatePurchasePointRequest{Name: "Compraventa El Bagre", Address: "Cra 50 # 20-15", Municipality: "El Bagre", Department: "Antioquia"}
+
+	newPoint := func() uuid.UUID {
+		point, err := s.AddPurchasePoint(repo.buyer.ID, repo.buyer.UserID, req)
+		if err != nil {
+			t.Fatalf("AddPurchasePoint: %v", err)
+		}
+		if point.Status != models.PointPending {
+			t.Fatalf("estado inicial = %s, se esperaba pending", point.Status)
+		}
+		return point.ID
+	}
+
+	tests := []struct {
+		name    string
+		review  func(pointID uuid.UUID) error
+		wantErr error
+	}{
+		{name: "pendiente", review: func(uuid.UUID) error { return nil }, wantErr: ErrPointNotApproved},
+		{name: "aprobado", review: func(id uuid.UUID) error {
+			_, err := s.ApprovePurchasePoint(id, adminID)
+			return err
+		}},
+		{name: "rechazado", review: func(id uuid.UUID) error {
+			_, err := s.RejectPurchasePoint(id, adminID, "la dirección no existe")
+			return err
+		}, wantErr: ErrPointNotApproved},
+		{name: "aprobado y desactivado", review: func(id uuid.UUID) error {
+			if _, err := s.ApprovePurchasePoint(id, adminID); err != nil {
+				return err
+			}
+			return s.DeactivatePurchasePoint(repo.buyer.ID, repo.buyer.UserID, id)
+		}, wantErr: ErrPointInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointID := newPoint()
+			if err := tt.review(pointID); err != nil {
+				t.Fatalf("revisión: %v", err)
+			}
+			if _, _, err := s.AuthorizePurchase(repo.buyer.UserID, pointID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthorizePurchase = %v, se esperaba %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReviewPurchasePointRecordsLedger(t *testing.T) {
+	repo := newPointRepo()
+	s := NewBuyerService(repo, nil, nil)
+	adminID := uuid.New()
+	point, _ := s.AddPurchasePoint(repo.buyer.ID, repo.buyer.UserID, &models.CreatePurchasePointRequest{
+		Name: "Compraventa Segovia", Address: "Calle 10 # 5-20", Municipality: "Segovia", Department: "Antioquia",
+	})
+
+	approved, err := s.ApprovePurchasePoint(point.ID, adminID)
+	if err != nil {
+		t.Fatalf("ApprovePurchasePoint: %v", err)
+	}
+	if approved.ReviewedBy == nil || *approved.ReviewedBy != adminID || approved.ReviewedAt == nil {
+		t.Fatalf("punto aprobado = %+v, falta quién y cuándo lo revisó", approved)
+	}
+	if len(repo.ledger) != 1 {
+		t.Fatalf("entradas del libro mayor = %d, se esperaba 1", len(repo.ledger))
+	}
+	entry := repo.ledger[0]
+	if entry.EventType != models.LedgerPointReviewed || entry.AggregateType != models.AggregatePoint || entry.AggregateID != point.ID ||
+		!strings.Contains(entry.Payload, "Calle 10 # 5-20") {
+		t.Fatalf("entrada del libro mayor = %+v", entry)
+	}
+
+	// Un punto ya revisado no vuelve a la cola
+	if _, err := s.RejectPurchasePoint(point.ID, adminID, "revisión repetida"); !errors.Is(err, ErrPointNotPending) {
+		t.Fatalf("RejectPurchasePoint = %v, se esperaba ErrPointNotPending", err)
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/certificate"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/storage"
+)
+
+// maxCertificateSize limita lo que se lee al verificar una copia subida del PDF.
+const maxCertificateSize = 5 * models.Megabyte
+
+var (
+	ErrCertificateTooLarge = errors.New("el archivo excede el tamaño de un certificado de origen")
+	ErrSaleWithoutParties  = errors.New("la venta es anterior al registro de comercializadores y sitios mineros y no admite certificado de origen")
+)
+
+// CertificateService emite el certificado de origen en PDF de cada venta,
+// lo guarda en el almacenamiento de documentos y verifica su autenticidad.
+type CertificateService interface {
+	Issue(sale *models.Sale) (*models.OriginCertificate, error)
+	Open(sale *models.Sale) (io.ReadCloser, *models.OriginCertificate, error)
+	Verify(certID uuid.UUID) (*models.CertificateVerification, error)
+	VerifyCopy(certID uuid.UUID, file io.Reader) (*models.CertificateVerification, error)
+	PublicKey() (keyID string, key ed25519.PublicKey)
+}
+
+type certificateService struct {
+	repo          repository.CertificateRepository
+	saleRepo      repository.SaleRepository
+	buyerService  BuyerService
+	siteService   MiningSiteService
+	store         storage.Storage
+	signer        *certificate.Signer
+	verifyBaseURL string
+}
+
+// NewCertificateService recibe la URL pública del API; el QR de cada
+// certificado apunta a <publicBaseURL>/api/v1/certificates/<id>.
+func NewCertificateService(
+	repo repository.CertificateRepository,
+	saleRepo repository.SaleRepository,
+	buyerService BuyerService,
+	siteService MiningSiteService,
+	store storage.Storage,
+	signer *certificate.Signer,
+	publicBaseURL string,
+) CertificateService {
+	return &certificateService{
+		repo:          repo,
+		saleRepo:      saleRepo,
+		buyerService:  buyerService,
+		siteService:   siteService,
+		store:         store,
+		signer:        signer,
+		verifyBaseURL: strings.TrimRight(publicBaseURL, "/") + "/api/v1/certificates/",
+	}
+}
+
+// Issue devuelve el certificado de la venta, emitiéndolo si aún no existe.
+func (s *certificateService) Issue(sale *models.Sale) (*models.OriginCertificate, error) {
+	existing, err := s.repo.FindBySale(sale.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, repository.ErrCertificateNotFound) {
+		return nil, err
+	}
+
+	buyer, site, err := s.saleParties(sale)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &models.OriginCertificate{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		SaleID:    sale.ID,
+		Number:    certificateNumber(sale),
+		KeyID:     s.signer.KeyID(),
+	}
+	cert.StorageKey = fmt.Sprintf("sales/%s/certificate/%s.pdf", sale.ID, cert.ID)
+
+	pdf, err := certificate.Render(s.certificateData(cert, sale, buyer, site))
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(pdf)
+	cert.SHA256 = hex.EncodeToString(digest[:])
+	cert.Signature = base64.StdEncoding.EncodeToString(s.signer.Sign(digest[:]))
+
+	ctx := context.Background()
+	if err := s.store.Put(ctx, cert.StorageKey, bytes.NewReader(pdf), "application/pdf"); err != nil {
+		return nil, fmt.Errorf("fallo al guardar el certificado: %w", err)
+	}
+	if err := s.repo.Create(cert); err != nil {
+		if delErr := s.store.Delete(ctx, cert.StorageKey); delErr != nil {
+			log.Printf("No se pudo borrar el certificado huérfano %s: %v", cert.StorageKey, delErr)
+		}
+		// Otra solicitud lo emitió primero: se entrega ese
+		if errors.Is(err, repository.ErrCertificateExists) {
+			return s.repo.FindBySale(sale.ID)
+		}
+		return nil, fmt.Errorf("fallo al registrar el certificado: %w", err)
+	}
+	return cert, nil
+}
+
+func (s *certificateService) Open(sale *models.Sale) (io.ReadCloser, *models.OriginCertificate, error) {
+	cert, err := s.Issue(sale)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := s.store.Get(context.Background(), cert.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al abrir el certificado: %w", err)
+	}
+	return r, cert, nil
+}
+
+// Verify comprueba que el PDF guardado siga siendo el que se firmó al emitirlo.
+func (s *certificateService) Verify(certID uuid.UUID) (*models.CertificateVerification, error) {
+	cert, err := s.repo.FindByID(certID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.store.Get(context.Background(), cert.StorageKey)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, fmt.Errorf("error al abrir el certificado: %w", err)
+	}
+	if r == nil {
+		return s.verification(cert, nil, "el archivo del certificado no está en el almacenamiento")
+	}
+	defer r.Close()
+	return s.verifyReader(cert, r)
+}
+
+// VerifyCopy comprueba una copia del PDF que presenta un tercero (por ejemplo,
+// la autoridad minera) contra la huella y la firma registradas.
+func (s *certificateService) VerifyCopy(certID uuid.UUID, file io.Reader) (*models.CertificateVerification, error) {
+	cert, err := s.repo.FindByID(certID)
+	if err != nil {
+		return nil, err
+	}
+	return s.verifyReader(cert, file)
+}
+
+func (s *certificateService) PublicKey() (string, ed25519.PublicKey) {
+	return s.signer.KeyID(), s.signer.PublicKey()
+}
+
+func (s *certificateService) verifyReader(cert *models.OriginCertificate, r io.Reader) (*models.CertificateVerification, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, io.LimitReader(r, maxCertificateSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el certificado: %w", err)
+	}
+	if n > maxCertificateSize {
+		return nil, ErrCertificateTooLarge
+	}
+	return s.verification(cert, h.Sum(nil), "")
+}
+
+// verification arma la respuesta pública. digest es la huella del archivo
+// revisado; si es nil, reason explica por qué no se pudo revisar.
+func (s *certificateService) verification(cert *models.OriginCertificate, digest []byte, reason string) (*models.CertificateVerification, error) {
+	sale, err := s.saleRepo.FindByID(cert.SaleID)
+	if err != nil {
+		return nil, err
+	}
+	buyer, site, err := s.saleParties(sale)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, sigErr := base64.StdEncoding.DecodeString(cert.Signature)
+	switch {
+	case digest == nil:
+	case hex.EncodeToString(digest) != cert.SHA256:
+		reason = "el archivo no coincide con el certificado emitido: fue modificado"
+	case !s.signer.Knows(cert.KeyID):
+		reason = "el certificado fue firmado con una llave desconocida"
+	case sigErr != nil || !s.signer.Verify(cert.KeyID, digest, signature):
+		reason = "la firma digital del certificado no es válida"
+	}
+
+	v := &models.CertificateVerification{
+		Valid:         reason == "",
+		Reason:        reason,
+		Number:        cert.Number,
+		IssuedAt:      cert.CreatedAt,
+		SaleDate:      sale.CreatedAt,
+		MinerName:     sale.Miner.FullName + " " + sale.Miner.LastName,
+		Municipality:  site.Municipality,
+		Department:    site.Department,
+		WeightGrams:   sale.WeightGrams,
+		FineGoldGrams: sale.FineGoldGrams,
+		BuyerName:     buyer.LegalName,
+		SHA256:        cert.SHA256,
+		Signature:     cert.Signature,
+		KeyID:         cert.KeyID,
+	}
+	if site.TitleNumber != nil {
+		v.TitleNumber = *site.TitleNumber
+	}
+	return v, nil
+}
+
+// saleParties carga el comprador y el sitio de origen de la venta.
+func (s *certificateService) saleParties(sale *models.Sale) (*models.Buyer, *models.MiningSite, error) {
+	if sale.Miner == nil {
+		return nil, nil, repository.ErrMinerNotFound
+	}
+	if sale.BuyerID == nil || sale.OriginSiteID == nil {
+		return nil, nil, ErrSaleWithoutParties
+	}
+	buyer, err := s.buyerService.GetBuyer(*sale.BuyerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	site, err := s.siteService.OriginSite(sale.Miner, *sale.OriginSiteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return buyer, site, nil
+}
+
+func (s *certificateService) certificateData(cert *models.OriginCertificate, sale *models.Sale, buyer *models.Buyer, site *models.MiningSite) certificate.Data {
+	d := certificate.Data{
+		Number:        cert.Number,
+		IssuedAt:      cert.CreatedAt.In(colombiaTime),
+		SaleID:        sale.ID.String(),
+		SaleDate:      sale.CreatedAt.In(colombiaTime),
+		MinerName:     sale.Miner.FullName + " " + sale.Miner.LastName,
+		MinerType:     string(sale.Miner.MinerType),
+		SiteKind:      string(site.Kind),
+		SiteName:      site.Name,
+		Municipality:  site.Municipality,
+		Department:    site.Department,
+		Mineral:       site.Mineral,
+		Latitude:      site.Latitude,
+		Longitude:     site.Longitude,
+		BuyerName:     buyer.LegalName,
+		BuyerNIT:      buyer.NIT,
+		BuyerRUCOM:    buyer.RUCOMNumber,
+		PointOfSale:   sale.PointOfSale,
+		WeightGrams:   sale.WeightGrams,
+		Purity:        sale.Purity,
+		FineGoldGrams: sale.FineGoldGrams,
+		VerifyURL:     s.verifyBaseURL + cert.ID.String(),
+	}
+	if site.TitleNumber != nil {
+		d.TitleNumber = *site.TitleNumber
+	}
+	return d
+}
+
+// certificateNumber deriva un número legible y único a partir del año y del ID de la venta.
+func certificateNumber(sale *models.Sale) string {
+	id := strings.ToUpper(strings.ReplaceAll(sale.ID.String(), "-", ""))
+	return fmt.Sprintf("CO-%d-%s", sale.CreatedAt.In(colombiaTime).Year(), id[:12])
+}
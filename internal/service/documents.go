@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/storage"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+// uploadDocument sube el archivo bajo <owner>/<kind>/<id generado> (owner es por
+// ejemplo "miners/<id>" o "buyers/<id>") y devuelve su metadata sin persistir ni
+// dueño asignado. El nombre que envía el cliente nunca forma parte de la llave,
+// así dos usuarios con "cedula.jpg" no se pisan.
+func uploadDocument(store storage.Storage, owner string, kind models.DocumentKind, file *multipart.FileHeader) (*models.Document, error) {
+	if file == nil {
+		return nil, fmt.Errorf("el archivo no puede ser nulo")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el archivo de subida: %w", err)
+	}
+	defer src.Close()
+
+	// Se usa el tipo detectado por contenido, no el que declara el cliente
+	contentType, err := utils.DetectContentType(file)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el archivo de subida: %w", err)
+	}
+	doc := &models.Document{
+		ID:           uuid.New(),
+		Kind:         kind,
+		OriginalName: utils.SanitizeFileName(file.Filename),
+		ContentType:  contentType,
+	}
+	doc.StorageKey = fmt.Sprintf("%s/%s/%s%s", owner, kind, doc.ID, documentExtensions[contentType])
+
+	// El checksum se calcula mientras se sube, sin leer el archivo dos veces
+	hash := sha256.New()
+	counter := &countingReader{r: io.TeeReader(src, hash)}
+	if err := store.Put(context.Background(), doc.StorageKey, counter, contentType); err != nil {
+		return nil, err
+	}
+	doc.Size = counter.n
+	doc.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return doc, nil
+}
+
+// discardDocuments elimina del almacenamiento archivos cuyo registro no llegó a
+// guardarse. Si alguno falla, lo recoge después el StorageSweeper.
+func discardDocuments(store storage.Storage, docs []*models.Document) {
+	for _, doc := range docs {
+		if err := store.Delete(context.Background(), doc.StorageKey); err != nil {
+			log.Printf("No se pudo eliminar el archivo %s tras el fallo: %v", doc.StorageKey, err)
+		}
+	}
+}
+
+// documentExtensions da la extensión de la llave según el tipo de contenido ya
+// validado; permite deducir el tipo MIME al servir el archivo.
+var documentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// countingReader cuenta los bytes leídos.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+// ledgerVerifyBatch es cuántas entradas se leen por consulta al recorrer la cadena.
+const ledgerVerifyBatch = 1000
+
+// LedgerReport es el resultado de recorrer la cadena completa.
+type LedgerReport struct {
+	Entries  int64  `json:"entries"`
+	Valid    bool   `json:"valid"`
+	BrokenAt int64  `json:"broken_at,omitempty"` // seq del primer eslabón roto
+	Reason   string `json:"reason,omitempty"`
+	LastHash string `json:"last_hash,omitempty"`
+}
+
+var ErrUnknownAggregate = errors.New("tipo de agregado desconocido: use sale, miner o buyer")
+
+// LedgerService lee y verifica el libro mayor. Las entradas no se escriben
+// aquí: cada repositorio las agrega con appendLedgerEntry en la misma
+// transacción que el evento, y la base de datos rechaza UPDATE y DELETE.
+type LedgerService interface {
+	Verify() (*LedgerReport, error)
+	History(aggregateType string, aggregateID uuid.UUID) ([]models.LedgerEntry, error)
+}
+
+type ledgerService struct {
+	repo repository.LedgerRepository
+}
+
+func NewLedgerService(repo repository.LedgerRepository) LedgerService {
+	return &ledgerService{repo: repo}
+}
+
+// Verify recorre la cadena desde el inicio y se detiene en el primer eslabón
+// roto: un seq faltante, un PrevHash que no coincide o un hash recalculado distinto.
+func (s *ledgerService) Verify() (*LedgerReport, error) {
+	report := &LedgerReport{Valid: true}
+	prevHash := models.LedgerGenesisHash
+	var lastSeq int64
+
+	for {
+		entries, err := s.repo.FindRange(lastSeq, ledgerVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			e := &entries[i]
+			switch {
+			case e.Seq != lastSeq+1:
+				return report.broken(lastSeq+1, fmt.Sprintf("falta la entrada %d (siguiente encontrada: %d)", lastSeq+1, e.Seq)), nil
+			case e.PrevHash != prevHash:
+				return report.broken(e.Seq, "prev_hash no coincide con el hash de la entrada anterior"), nil
+			case e.ComputeHash() != e.Hash:
+				return report.broken(e.Seq, "el contenido de la entrada no coincide con su hash"), nil
+			}
+			prevHash = e.Hash
+			lastSeq = e.Seq
+			report.Entries++
+		}
+		if len(entries) < ledgerVerifyBatch {
+			break
+		}
+	}
+	report.LastHash = prevHash
+	return report, nil
+}
+
+func (r *LedgerReport) broken(seq int64, reason string) *LedgerReport {
+	r.Valid = false
+	r.BrokenAt = seq
+	r.Reason = reason
+	return r
+}
+
+// History devuelve en orden las entradas de una venta, un minero, un
+// comercializador o un punto de compra.
+func (s *ledgerService) History(aggregateType string, aggregateID uuid.UUID) ([]models.LedgerEntry, error) {
+	switch aggregateType {
+	case models.AggregateSale, models.AggregateMiner, models.AggregateBuyer, models.AggregatePoint:
+	default:
+		return nil, ErrUnknownAggregate
+	}
+	return s.repo.FindByAggregate(aggregateType, aggregateID.String())
+}
+
+// newLedgerEntry arma una entrada sin encadenar; el repositorio asigna seq y
+// hashes al insertarla. Los servicios la pasan a los métodos transaccionales de
+// sus repositorios para que el evento y la entrada se confirmen juntos.
+func newLedgerEntry(eventType, aggregateType string, aggregateID uuid.UUID, payload any) (*models.LedgerEntry, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("payload inválido para el libro mayor: %w", err)
+	}
+	return &models.LedgerEntry{
+		CreatedAt:     time.Now(),
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+// memoryLedger es un LedgerRepository en memoria para recorrer la cadena sin base de datos.
+type memoryLedger struct {
+	entries []models.LedgerEntry
+}
+
+func (m *memoryLedger) FindRange(afterSeq int64, limit int) ([]models.LedgerEntry, error) {
+	var out []models.LedgerEntry
+	for _, e := range m.entries {
+		if e.Seq > afterSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryLedger) FindByAggregate(aggregateType, aggregateID string) ([]models.LedgerEntry, error) {
+	var out []models.LedgerEntry
+	for _, e := range m.entries {
+		if e.AggregateType == aggregateType && e.AggregateID.String() == aggregateID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// chainLedger encadena n entradas como lo hace appendLedgerEntry.
+func chainLedger(t *testing.T, n int) []models.LedgerEntry {
+	t.Helper()
+	entries := make([]models.LedgerEntry, 0, n)
+	prev := models.LedgerGenesisHash
+	start := time.Date(2026, time.January, 10, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		e, err := newLedgerEntry(models.LedgerSaleCreated, models.AggregateSale, uuid.New(), map[string]int{"n": i})
+		if err != nil {
+			t.Fatalf("newLedgerEntry: %v", err)
+		}
+		e.Seq = int64(i)
+		e.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		e.PrevHash = prev
+		e.Hash = e.ComputeHash()
+		prev = e.Hash
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+func TestLedgerVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func(entries []models.LedgerEntry) []models.LedgerEntry
+		wantValid  bool
+		wantBroken int64
+	}{
+		{name: "cadena intacta", tamper: func(e []models.LedgerEntry) []models.LedgerEntry { return e }, wantValid: true},
+		{name: "libro vacío", tamper: func([]models.LedgerEntry) []models.LedgerEntry { return nil }, wantValid: true},
+		{
+			name: "payload editado",
+			tamper: func(e []models.LedgerEntry) []models.LedgerEntry {
+				e[2].Payload = `{"n":300}`
+				return e
+			},
+			wantBroken: 3,
+		},
+		{
+			// Quien edita y recalcula el hash de la fila rompe el eslabón siguiente
+			name: "fila editada con hash recalculado",
+			tamper: func(e []models.LedgerEntry) []models.LedgerEntry {
+				e[1].Payload = `{"n":200}`
+				e[1].Hash = e[1].ComputeHash()
+				return e
+			},
+			wantBroken: 3,
+		},
+		{
+			name: "entrada borrada",
+			tamper: func(e []models.LedgerEntry) []models.LedgerEntry {
+				return append(e[:3], e[4:]...)
+			},
+			wantBroken: 4,
+		},
+		{
+			name: "última entrada reemplazada",
+			tamper: func(e []models.LedgerEntry) []models.LedgerEntry {
+				e[4].PrevHash = models.LedgerGenesisHash
+				e[4].Hash = e[4].ComputeHash()
+				return e
+			},
+			wantBroken: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.tamper(chainLedger(t, 5))
+			report, err := NewLedgerService(&memoryLedger{entries: entries}).Verify()
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if report.Valid != tt.wantValid || report.BrokenAt != tt.wantBroken {
+				t.Fatalf("Verify = %+v, se esperaba válido=%v roto en %d", report, tt.wantValid, tt.wantBroken)
+			}
+			if tt.wantValid && len(entries) > 0 && report.LastHash != entries[len(entries)-1].Hash {
+				t.Fatalf("LastHash = %s, se esperaba el hash de la última entrada", report.LastHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+var (
+	ErrInvalidTransition = errors.New("transición de estado KYC no permitida")
+	ErrReviewNotClaimed  = errors.New("el caso está asignado a otro revisor")
+	ErrInvalidDocument   = errors.New("el documento indicado no aplica a este tipo de minero")
+	ErrSelfReview        = errors.New("un revisor no puede revisar su propio registro")
+)
+
+// kycTransitions define las transiciones válidas del estado KYC de un minero.
+var kycTransitions = map[models.VerificationStatus][]models.VerificationStatus{
+	models.VerificationPending:           {models.VerificationInReview},
+	models.VerificationInReview:          {models.VerificationApproved, models.VerificationRejected, models.VerificationNeedsResubmission},
+	models.VerificationNeedsResubmission: {models.VerificationPending},
+	models.VerificationApproved:          {models.VerificationPending},
+	models.VerificationRejected:          {},
+}
+
+func canTransition(from, to models.VerificationStatus) bool {
+	for _, allowed := range kycTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Listar mineros por estado KYC (cola de revisión)
+func (s *minerService) ListMinersForReview(status models.VerificationStatus, page, limit int) (*utils.Pagination, error) {
+	if _, ok := kycTransitions[status]; !ok {
+		return nil, fmt.Errorf("estado KYC '%s' no reconocido", status)
+	}
+	result, err := s.repo.FindByStatusPaginated(status, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toMinerResponses(result, true), nil
+}
+
+// ClaimReview asigna el caso al revisor y lo pasa a in_review.
+func (s *minerService) ClaimReview(minerID, reviewerID uuid.UUID) (*models.Miner, error) {
+	miner, err := s.repo.FindByID(minerID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.UserID == reviewerID {
+		return nil, ErrSelfReview
+	}
+	miner.ReviewerID = &reviewerID
+	if err := s.transition(miner, models.VerificationInReview, reviewerID, "", nil); err != nil {
+		return nil, err
+	}
+	return miner, nil
+}
+
+// ApproveMiner aprueba el registro. Solo el revisor que tomó el caso puede aprobarlo.
+func (s *minerService) ApproveMiner(minerID, reviewerID uuid.UUID, notes string) (*models.Miner, error) {
+	miner, err := s.claimedMiner(minerID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(miner, models.VerificationApproved, reviewerID, notes, nil); err != nil {
+		return nil, err
+	}
+	return miner, nil
+}
+
+// RejectMiner rechaza el registro. Con documentos indicados el minero pasa a
+// needs_resubmission para corregirlos; sin ellos el rechazo es definitivo.
+func (s *minerService) RejectMiner(minerID, reviewerID uuid.UUID, req *models.RejectMinerRequest) (*models.Miner, error) {
+	miner, err := s.claimedMiner(minerID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := map[models.DocumentKind]bool{}
+	for _, kind := range models.DocumentKindsFor(miner.MinerType) {
+		valid[kind] = true
+	}
+	rejections := make([]models.DocumentRejection, 0, len(req.Documents))
+	for _, doc := range req.Documents {
+		if !valid[doc.Kind] {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, doc.Kind)
+		}
+		rejections = append(rejections, models.DocumentRejection{
+			MinerID:      miner.ID,
+			DocumentKind: doc.Kind,
+			Reason:       doc.Reason,
+		})
+	}
+
+	to := models.VerificationRejected
+	if len(rejections) > 0 {
+		to = models.VerificationNeedsResubmission
+	}
+	if err := s.transition(miner, to, reviewerID, req.Reason, rejections); err != nil {
+		return nil, err
+	}
+	return miner, nil
+}
+
+// Historial de transiciones KYC
+func (s *minerService) GetReviewHistory(minerID uuid.UUID) ([]models.MinerReviewEvent, error) {
+	if _, err := s.repo.FindByID(minerID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindReviewEvents(minerID)
+}
+
+// claimedMiner carga el minero y verifica que el caso esté en revisión por el revisor indicado.
+func (s *minerService) claimedMiner(minerID, reviewerID uuid.UUID) (*models.Miner, error) {
+	miner, err := s.repo.FindByID(minerID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.VerificationStatus != models.VerificationInReview {
+		return nil, fmt.Errorf("%w: el caso está en estado '%s'", ErrInvalidTransition, miner.VerificationStatus)
+	}
+	if miner.ReviewerID == nil || *miner.ReviewerID != reviewerID {
+		return nil, ErrReviewNotClaimed
+	}
+	return miner, nil
+}
+
+// transition valida y aplica un cambio de estado KYC, registrando actor y motivo.
+func (s *minerService) transition(
+	miner *models.Miner,
+	to models.VerificationStatus,
+	actorID uuid.UUID,
+	reason string,
+	rejections []models.DocumentRejection,
+) error {
+	from := miner.VerificationStatus
+	if !canTransition(from, to) {
+		return fmt.Errorf("%w: de '%s' a '%s'", ErrInvalidTransition, from, to)
+	}
+
+	miner.VerificationStatus = to
+	event := &models.MinerReviewEvent{
+		ID:                 uuid.New(),
+		CreatedAt:          time.Now().UTC().Truncate(time.Microsecond),
+		MinerID:            miner.ID,
+		ActorID:            actorID,
+		FromStatus:         from,
+		ToStatus:           to,
+		Reason:             reason,
+		DocumentRejections: rejections,
+	}
+	entry, err := newLedgerEntry(models.LedgerKYCTransition, models.AggregateMiner, miner.ID, event)
+	if err != nil {
+		miner.VerificationStatus = from
+		return err
+	}
+	if err := s.repo.UpdateStatus(miner, from, event, entry); err != nil {
+		miner.VerificationStatus = from
+		if errors.Is(err, repository.ErrMinerStatusChanged) {
+			return fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -1,20 +1,23 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"mime/multipart"
-	"strings"
+	"path"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
 
 	"github.com/sanchezta/batea-backend/internal/config"
 	"github.com/sanchezta/batea-backend/internal/models"
 	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/secrets"
+	"github.com/sanchezta/batea-backend/internal/storage"
 	"github.com/sanchezta/batea-backend/internal/utils"
 )
 
@@ -25,305 +28,460 @@ type MinerService interface {
 		userID uuid.UUID,
 		req *models.CreateMinerRequest,
 		files map[string]*multipart.FileHeader,
-	) (*models.Miner, string, string, error)
+	) (*models.Miner, *models.MinerTOTPResponse, error)
 
+	GetMinerByID(id uuid.UUID) (*models.Miner, error)
 	GetAllMiners(page, limit int) (*utils.Pagination, error)
-	GenerateTOTP(minerID uuid.UUID) (string, error)
-	ValidateTOTP(minerID uuid.UUID, code string) (bool, error)
+	OpenDocument(miner *models.Miner, kind models.DocumentKind) (io.ReadCloser, string, error)
+	DocumentURL(miner *models.Miner, kind models.DocumentKind) (string, error)
+	UpdateProfile(minerID, userID uuid.UUID, req *models.UpdateMinerRequest) (*models.Miner, error)
+	ReplaceDocument(minerID, userID uuid.UUID, kind models.DocumentKind, file *multipart.FileHeader) (*models.Miner, error)
+	ValidateTOTP(minerID, callerID uuid.UUID, code string) (bool, error)
+	ConfirmTOTP(minerID, userID uuid.UUID, code string) ([]string, error)
+	ReenrollTOTP(minerID, userID uuid.UUID, req *models.ReenrollTOTPRequest) (*models.MinerTOTPResponse, error)
+
+	// Revisión KYC
+	ListMinersForReview(status models.VerificationStatus, page, limit int) (*utils.Pagination, error)
+	ClaimReview(minerID, reviewerID uuid.UUID) (*models.Miner, error)
+	ApproveMiner(minerID, reviewerID uuid.UUID, notes string) (*models.Miner, error)
+	RejectMiner(minerID, reviewerID uuid.UUID, req *models.RejectMinerRequest) (*models.Miner, error)
+	GetReviewHistory(minerID uuid.UUID) ([]models.MinerReviewEvent, error)
 }
 
+var (
+	ErrDocumentNotFound  = errors.New("el minero no tiene cargado este documento")
+	ErrNotMinerOwner     = errors.New("solo el dueño del registro puede modificarlo")
+	ErrEmailTaken        = errors.New("ya existe un minero registrado con este correo electrónico")
+	ErrMinerLocked       = errors.New("el registro no admite cambios en su estado actual")
+	ErrTOTPNotConfigured = errors.New("el minero no tiene configurado un secreto TOTP")
+	ErrInvalidTOTP       = errors.New("código inválido o expirado")
+	ErrInvalidFile       = errors.New("error de validación del archivo")
+)
+
+// Vigencia de las URLs firmadas de descarga de documentos
+const documentURLTTL = 5 * time.Minute
+
 type minerService struct {
 	repo     repository.MinerRepository
 	userRepo repository.UserRepository // para verificar existencia del usuario
+	docRepo  repository.DocumentRepository
+	store    storage.Storage
 	cfg      *config.Config
+	totpKeys *secrets.Envelope // cifra el secreto TOTP guardado en la base de datos
+	// confirma por SMS la identidad del minero antes de registrar otro teléfono
+	phoneVerifier PhoneVerificationService
 }
 
 // NewMinerService crea una nueva instancia del servicio de mineros.
 // Si no quieres validar usuario, puedes pasar nil en userRepo y saltar esa verificación.
-func NewMinerService(repo repository.MinerRepository, userRepo repository.UserRepository, cfg *config.Config) MinerService {
+func NewMinerService(repo repository.MinerRepository, userRepo repository.UserRepository, docRepo repository.DocumentRepository, store storage.Storage, cfg *config.Config, totpKeys *secrets.Envelope, phoneVerifier PhoneVerificationService) MinerService {
 	return &minerService{
-		repo:     repo,
-		userRepo: userRepo,
-		cfg:      cfg,
+		repo:          repo,
+		userRepo:      userRepo,
+		docRepo:       docRepo,
+		store:         store,
+		cfg:           cfg,
+		totpKeys:      totpKeys,
+		phoneVerifier: phoneVerifier,
 	}
 }
 
 // CreateMiner: ahora recibe userID (no depende de req.UserID).
+// Devuelve el secreto TOTP para que el minero lo agregue a su aplicación; no
+// sirve para validar ventas hasta que se confirme con ConfirmTOTP.
 func (s *minerService) CreateMiner(
 	userID uuid.UUID,
 	req *models.CreateMinerRequest,
 	files map[string]*multipart.FileHeader,
-) (*models.Miner, string, string, error) {
+) (*models.Miner, *models.MinerTOTPResponse, error) {
 
 	// Validar userID
 	if userID == uuid.Nil {
-		return nil, "", "", errors.New("falta el ID del usuario asociado al minero")
+		return nil, nil, errors.New("falta el ID del usuario asociado al minero")
 	}
 
 	// (Opcional) Verificar que el usuario exista si userRepo no es nil
 	if s.userRepo != nil {
 		user, err := s.userRepo.FindByID(userID.String())
 		if err != nil {
-			return nil, "", "", fmt.Errorf("error al buscar usuario asociado: %w", err)
+			return nil, nil, fmt.Errorf("error al buscar usuario asociado: %w", err)
 		}
 		if user == nil {
-			return nil, "", "", errors.New("el usuario asociado no existe o fue eliminado")
+			return nil, nil, errors.New("el usuario asociado no existe o fue eliminado")
 		}
 	}
 
 	// Validar archivos antes de persistencia
 	if err := s.validateMinerFiles(req.MinerType, files); err != nil {
-		return nil, "", "", err
+		return nil, nil, err
+	}
+
+	// El titular declara su producción anual, que es su tope de venta
+	if req.MinerType == models.TitularMiner && req.DeclaredAnnualProductionGrams == nil {
+		return nil, nil, ErrProductionNotDeclared
 	}
 
 	// Crear el objeto Miner vinculado al usuario
+	// El ID se genera aquí para ubicar los documentos bajo miners/<id>/ antes de persistir
 	miner := &models.Miner{
+		ID:          uuid.New(),
 		UserID:      userID, // vínculo real con User
 		FullName:    req.FullName,
 		LastName:    req.LastName,
 		// PhoneNumber: req.PhoneNumber,
 		Email:       req.Email,
 		MinerType:   req.MinerType,
+		VerificationStatus: models.VerificationPending,
+	}
+	if req.MinerType == models.TitularMiner {
+		miner.DeclaredAnnualProductionGrams = req.DeclaredAnnualProductionGrams
 	}
 
-	// Map de rutas a guardar
-	filesToSave := map[string]string{
-		"id_photo_front":        "",
-		"id_photo_back":         "",
-		"facial_photo":          "",
-		"rucon":                 "",
-		"other_doc":             "",
-		"exploitation_contract": "",
-		"environmental_tool":    "",
-		"technical_tool":        "",
-	}
-
-	// Comunes
-	commonFiles := map[string]string{
-		"id_photo_front": "cedulas",
-		"id_photo_back":  "cedulas",
-		"facial_photo":   "facial",
-	}
-	for field, subdir := range commonFiles {
-		if file, ok := files[field]; ok {
-			path, err := utils.SaveFile(s.cfg, file, subdir)
-			if err != nil {
-				return nil, "", "", fmt.Errorf("fallo al guardar archivo %s: %w", field, err)
-			}
-			filesToSave[field] = path
+	// Guardar los documentos que aplican al tipo de minero
+	var docs []*models.Document
+	for _, kind := range models.DocumentKindsFor(req.MinerType) {
+		file := files[string(kind)]
+		if file == nil {
+			continue
+		}
+		doc, err := s.saveDocument(miner.ID, kind, file)
+		if err != nil {
+			discardDocuments(s.store, docs)
+			return nil, nil, fmt.Errorf("fallo al guardar archivo %s: %w", kind, err)
 		}
+		miner.SetDocumentPath(kind, doc.StorageKey)
+		docs = append(docs, doc)
 	}
 
-	// Específicos por tipo
-	switch req.MinerType {
-	case models.SubsistenceMiner:
-		if file, ok := files["rucon"]; ok {
-			path, err := utils.SaveFile(s.cfg, file, "subsistencia/rucon")
-			if err != nil {
-				return nil, "", "", fmt.Errorf("fallo al guardar RUCON: %w", err)
-			}
-			filesToSave["rucon"] = path
+	// TOTP: el secreto queda pendiente hasta que el minero confirme el primer código
+	key, sealed, err := s.issueTOTPKey(miner)
+	if err != nil {
+		discardDocuments(s.store, docs)
+		return nil, nil, err
+	}
+	miner.TOTPPendingSecret = sealed
+
+	// Persistir minero y documentos juntos; si falla, los archivos subidos se eliminan
+	if err := s.repo.CreateWithDocuments(miner, docs); err != nil {
+		log.Printf("Error de DB al registrar el minero, eliminando %d archivos: %v", len(docs), err)
+		discardDocuments(s.store, docs)
+		if errors.Is(err, repository.ErrMinerDuplicate) {
+			return nil, nil, err
 		}
-		
-		if file, ok := files["other_doc"]; ok && file != nil {
-    path, err := utils.SaveFile(s.cfg, file, "subsistencia/otros")
-    if err != nil {
-        return nil, "", "", fmt.Errorf("fallo al guardar otro documento: %w", err)
-    }
-    filesToSave["other_doc"] = path
+		return nil, nil, fmt.Errorf("fallo al guardar el minero en la base de datos: %w", err)
+	}
+
+	return miner, totpEnrollment(miner, key), nil
 }
-	case models.TitularMiner:
-		spec := map[string]string{
-			"exploitation_contract": "titular/contrato",
-			"environmental_tool":    "titular/ambiental",
-			"technical_tool":        "titular/tecnica",
+
+// Obtener minero por ID
+func (s *minerService) GetMinerByID(id uuid.UUID) (*models.Miner, error) {
+	return s.repo.FindByID(id)
+}
+
+// Paginación (devuelve DTOs, sin secretos ni rutas de documentos)
+func (s *minerService) GetAllMiners(page, limit int) (*utils.Pagination, error) {
+	result, err := s.repo.FindAllPaginated(page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toMinerResponses(result, false), nil
+}
+
+// toMinerResponses reemplaza los modelos de una página por sus DTOs de salida.
+func toMinerResponses(result *utils.Pagination, includeDocuments bool) *utils.Pagination {
+	if miners, ok := result.Data.(*[]models.Miner); ok {
+		out := make([]*models.MinerResponse, 0, len(*miners))
+		for i := range *miners {
+			out = append(out, models.NewMinerResponse(&(*miners)[i], includeDocuments))
+		}
+		result.Data = out
+	}
+	return result
+}
+
+// OpenDocument abre un documento del minero para descargarlo. Devuelve el contenido y su tipo MIME.
+func (s *minerService) OpenDocument(miner *models.Miner, kind models.DocumentKind) (io.ReadCloser, string, error) {
+	key := miner.DocumentPath(kind)
+	if key == "" {
+		return nil, "", ErrDocumentNotFound
+	}
+	r, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, "", ErrDocumentNotFound
+		}
+		return nil, "", fmt.Errorf("error al abrir el documento: %w", err)
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return r, contentType, nil
+}
+
+// DocumentURL genera una URL firmada de corta duración para descargar el documento.
+func (s *minerService) DocumentURL(miner *models.Miner, kind models.DocumentKind) (string, error) {
+	key := miner.DocumentPath(kind)
+	if key == "" {
+		return "", ErrDocumentNotFound
+	}
+	return s.store.SignedURL(context.Background(), key, documentURLTTL)
+}
+
+// saveDocument sube un documento del minero bajo miners/<minerID>/.
+func (s *minerService) saveDocument(minerID uuid.UUID, kind models.DocumentKind, file *multipart.FileHeader) (*models.Document, error) {
+	doc, err := uploadDocument(s.store, "miners/"+minerID.String(), kind, file)
+	if err != nil {
+		return nil, err
+	}
+	doc.MinerID = &minerID
+	return doc, nil
+}
+
+// UpdateProfile actualiza nombre, apellido o correo del minero. Solo lo puede hacer el dueño.
+func (s *minerService) UpdateProfile(minerID, userID uuid.UUID, req *models.UpdateMinerRequest) (*models.Miner, error) {
+	miner, err := s.ownedMiner(minerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Email != nil && *req.Email != miner.Email {
+		existing, err := s.repo.FindByEmail(*req.Email)
+		if err != nil && !errors.Is(err, repository.ErrMinerNotFound) {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrEmailTaken
+		}
+		miner.Email = *req.Email
+	}
+	if req.FullName != nil {
+		miner.FullName = *req.FullName
+	}
+	if req.LastName != nil {
+		miner.LastName = *req.LastName
+	}
+
+	// Cambiar la producción declarada cambia el tope de venta: exige nueva revisión
+	productionChanged := false
+	if req.DeclaredAnnualProductionGrams != nil {
+		if miner.MinerType != models.TitularMiner {
+			return nil, ErrProductionNotApplicable
 		}
-		for field, subdir := range spec {
-			if file, ok := files[field]; ok {
-				path, err := utils.SaveFile(s.cfg, file, subdir)
-				if err != nil {
-					return nil, "", "", fmt.Errorf("fallo al guardar archivo titular %s: %w", field, err)
-				}
-				filesToSave[field] = path
+		current := miner.DeclaredAnnualProductionGrams
+		if current == nil || *current != *req.DeclaredAnnualProductionGrams {
+			switch miner.VerificationStatus {
+			case models.VerificationInReview, models.VerificationRejected:
+				return nil, fmt.Errorf("%w: %s", ErrMinerLocked, miner.VerificationStatus)
 			}
+			miner.DeclaredAnnualProductionGrams = req.DeclaredAnnualProductionGrams
+			productionChanged = true
 		}
-	default:
-		return nil, "", "", fmt.Errorf("tipo de minero '%s' no reconocido", req.MinerType)
-	}
-
-	// Asignar rutas
-	miner.IDPhotoFrontPath = filesToSave["id_photo_front"]
-	miner.IDPhotoBackPath = filesToSave["id_photo_back"]
-	miner.FacialPhotoPath = filesToSave["facial_photo"]
-	miner.RuconPath = filesToSave["rucon"]
-	miner.OtherDocPath = filesToSave["other_doc"]
-	miner.ExploitationContractPath = filesToSave["exploitation_contract"]
-	miner.EnvironmentalToolPath = filesToSave["environmental_tool"]
-	miner.TechnicalToolPath = filesToSave["technical_tool"]
-
-	// TOTP
-	key, err := totp.Generate(totp.GenerateOpts{
-		Issuer:      "Batea Fintech",
-		AccountName: req.Email,
-		Period:      30,
-		Digits:      otp.DigitsSix,
-	})
+	}
+
+	if err := s.repo.UpdateProfile(miner); err != nil {
+		return nil, fmt.Errorf("fallo al actualizar el minero: %w", err)
+	}
+
+	if productionChanged && miner.VerificationStatus != models.VerificationPending {
+		reason := "Producción anual declarada modificada por el minero"
+		if err := s.transition(miner, models.VerificationPending, userID, reason, nil); err != nil {
+			return nil, err
+		}
+	}
+	return miner, nil
+}
+
+// ReplaceDocument reemplaza un documento del minero. El archivo se valida con las
+// mismas reglas del registro y, si el registro ya había sido revisado, vuelve a pending.
+func (s *minerService) ReplaceDocument(minerID, userID uuid.UUID, kind models.DocumentKind, file *multipart.FileHeader) (*models.Miner, error) {
+	miner, err := s.ownedMiner(minerID, userID)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("error generando TOTP: %w", err)
+		return nil, err
+	}
+
+	// Mientras un revisor tiene el caso, o si fue rechazado definitivamente, no se admiten cambios
+	switch miner.VerificationStatus {
+	case models.VerificationInReview, models.VerificationRejected:
+		return nil, fmt.Errorf("%w: %s", ErrMinerLocked, miner.VerificationStatus)
 	}
-	miner.TOTPSecret = key.Secret()
-	qrURL := key.URL()
 
-	code, err := totp.GenerateCodeCustom(miner.TOTPSecret, time.Now(), totp.ValidateOpts{
-		Period:    30,
-		Digits:    otp.DigitsSix,
-		Algorithm: otp.AlgorithmSHA1,
-	})
+	if err := validateDocument(miner.MinerType, kind, file); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.saveDocument(miner.ID, kind, file)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("error generando código TOTP: %w", err)
+		return nil, fmt.Errorf("fallo al guardar archivo %s: %w", kind, err)
 	}
+	previous := miner.DocumentPath(kind)
+	if err := s.repo.ReplaceDocument(doc); err != nil {
+		discardDocuments(s.store, []*models.Document{doc})
+		return nil, fmt.Errorf("fallo al actualizar el documento: %w", err)
+	}
+	miner.SetDocumentPath(kind, doc.StorageKey)
 
-	// Persistir
-	if err := s.repo.Create(miner); err != nil {
-		log.Printf("Error de DB. Limpieza de archivos omitida: %v", err)
-		if strings.Contains(err.Error(), "unique_violation") {
-			return nil, "", "", fmt.Errorf("ya existe un minero registrado con esta cédula o correo electrónico")
+	if previous != "" && previous != doc.StorageKey {
+		if err := s.store.Delete(context.Background(), previous); err != nil {
+			log.Printf("No se pudo eliminar el documento anterior %s: %v", previous, err)
+		} else if err := s.docRepo.DeleteByStorageKey(previous); err != nil {
+			log.Printf("No se pudo eliminar la metadata del documento %s: %v", previous, err)
 		}
-		return nil, "", "", fmt.Errorf("fallo al guardar el minero en la base de datos: %w", err)
 	}
 
-	return miner, code, qrURL, nil
+	// Un documento nuevo requiere una nueva revisión
+	if miner.VerificationStatus != models.VerificationPending {
+		reason := fmt.Sprintf("Documento '%s' reemplazado por el minero", kind)
+		if err := s.transition(miner, models.VerificationPending, userID, reason, nil); err != nil {
+			return nil, err
+		}
+	}
+	return miner, nil
 }
 
-// Paginación
-func (s *minerService) GetAllMiners(page, limit int) (*utils.Pagination, error) {
-	return s.repo.FindAllPaginated(page, limit)
+// ownedMiner carga el minero y verifica que pertenezca al usuario.
+func (s *minerService) ownedMiner(minerID, userID uuid.UUID) (*models.Miner, error) {
+	miner, err := s.repo.FindByID(minerID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.UserID != userID {
+		return nil, ErrNotMinerOwner
+	}
+	return miner, nil
 }
 
-// Validación de archivos
-func (s *minerService) validateMinerFiles(minerType models.MinerType, files map[string]*multipart.FileHeader) error {
-	baseFields := map[string]models.DocumentField{
-		"id_photo_front": {
-			FileHeader:       files["id_photo_front"],
+// minerDocumentFields devuelve las reglas de validación de cada documento según el tipo de minero.
+func minerDocumentFields(minerType models.MinerType, files map[string]*multipart.FileHeader) (map[models.DocumentKind]models.DocumentField, error) {
+	photoMimes := []string{"image/jpeg", "image/png"}
+	pdfMimes := []string{"application/pdf"}
+
+	fields := map[models.DocumentKind]models.DocumentField{
+		models.DocIDPhotoFront: {
+			FileHeader:       files[string(models.DocIDPhotoFront)],
 			Required:         true,
 			MaxSizeBytes:     models.PhotoMaxSize,
-			AllowedMimeTypes: []string{"image/jpeg", "image/png"},
+			AllowedMimeTypes: photoMimes,
+			MinImageSide:     models.PhotoMinSide,
+			MaxImageSide:     models.PhotoMaxSide,
 		},
-		"id_photo_back": {
-			FileHeader:       files["id_photo_back"],
+		models.DocIDPhotoBack: {
+			FileHeader:       files[string(models.DocIDPhotoBack)],
 			Required:         true,
 			MaxSizeBytes:     models.PhotoMaxSize,
-			AllowedMimeTypes: []string{"image/jpeg", "image/png"},
+			AllowedMimeTypes: photoMimes,
+			MinImageSide:     models.PhotoMinSide,
+			MaxImageSide:     models.PhotoMaxSide,
 		},
-		"facial_photo": {
-			FileHeader:       files["facial_photo"],
+		models.DocFacialPhoto: {
+			FileHeader:       files[string(models.DocFacialPhoto)],
 			Required:         true,
 			MaxSizeBytes:     models.PhotoMaxSize,
-			AllowedMimeTypes: []string{"image/jpeg", "image/png"},
+			AllowedMimeTypes: photoMimes,
+			MinImageSide:     models.PhotoMinSide,
+			MaxImageSide:     models.PhotoMaxSide,
 		},
 	}
 
-	for fieldName, field := range baseFields {
-		if err := utils.ValidateFile(field); err != nil {
-			return fmt.Errorf("error de validación en '%s': %w", fieldName, err)
-		}
-	}
-
-	pdfMimes := []string{"application/pdf", "pdf"}
-	var specificFields map[string]models.DocumentField
-
 	switch minerType {
 	case models.SubsistenceMiner:
-		specificFields = map[string]models.DocumentField{
-			"rucon": {
-				FileHeader:       files["rucon"],
-				Required:         true,
-				MaxSizeBytes:     models.RuconMaxSize,
-				AllowedMimeTypes: pdfMimes,
-			},
-			"other_doc": {
-				FileHeader:       files["other_doc"],
-				Required:         false,
-				MaxSizeBytes:     models.SubsistenceOtherMaxSize,
-				AllowedMimeTypes: pdfMimes,
-			},
+		fields[models.DocRucon] = models.DocumentField{
+			FileHeader:       files[string(models.DocRucon)],
+			Required:         true,
+			MaxSizeBytes:     models.RuconMaxSize,
+			AllowedMimeTypes: pdfMimes,
+			MaxPages:         models.RuconMaxPages,
+		}
+		fields[models.DocOther] = models.DocumentField{
+			FileHeader:       files[string(models.DocOther)],
+			Required:         false,
+			MaxSizeBytes:     models.SubsistenceOtherMaxSize,
+			AllowedMimeTypes: pdfMimes,
+			MaxPages:         models.SubsistenceOtherMaxPages,
 		}
 	case models.TitularMiner:
-		specificFields = map[string]models.DocumentField{
-			"exploitation_contract": {
-				FileHeader:       files["exploitation_contract"],
-				Required:         true,
-				MaxSizeBytes:     models.ExploitationContractMaxSize,
-				AllowedMimeTypes: pdfMimes,
-			},
-			"environmental_tool": {
-				FileHeader:       files["environmental_tool"],
-				Required:         true,
-				MaxSizeBytes:     models.EnvironmentalToolMaxSize,
-				AllowedMimeTypes: pdfMimes,
-			},
-			"technical_tool": {
-				FileHeader:       files["technical_tool"],
-				Required:         true,
-				MaxSizeBytes:     models.TechnicalToolMaxSize,
-				AllowedMimeTypes: pdfMimes,
-			},
+		fields[models.DocExploitationContract] = models.DocumentField{
+			FileHeader:       files[string(models.DocExploitationContract)],
+			Required:         true,
+			MaxSizeBytes:     models.ExploitationContractMaxSize,
+			AllowedMimeTypes: pdfMimes,
+			MaxPages:         models.ExploitationContractMaxPages,
+		}
+		fields[models.DocEnvironmentalTool] = models.DocumentField{
+			FileHeader:       files[string(models.DocEnvironmentalTool)],
+			Required:         true,
+			MaxSizeBytes:     models.EnvironmentalToolMaxSize,
+			AllowedMimeTypes: pdfMimes,
+			MaxPages:         models.EnvironmentalToolMaxPages,
+		}
+		fields[models.DocTechnicalTool] = models.DocumentField{
+			FileHeader:       files[string(models.DocTechnicalTool)],
+			Required:         true,
+			MaxSizeBytes:     models.TechnicalToolMaxSize,
+			AllowedMimeTypes: pdfMimes,
+			MaxPages:         models.TechnicalToolMaxPages,
 		}
 	default:
-		return fmt.Errorf("tipo de minero no válido")
+		return nil, fmt.Errorf("tipo de minero no válido")
 	}
 
-	for fieldName, field := range specificFields {
-		if err := utils.ValidateFile(field); err != nil {
-			return fmt.Errorf("error en archivo '%s': %w", fieldName, err)
+	return fields, nil
+}
+
+// Validación de archivos
+func (s *minerService) validateMinerFiles(minerType models.MinerType, files map[string]*multipart.FileHeader) error {
+	fields, err := minerDocumentFields(minerType, files)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range models.DocumentKindsFor(minerType) {
+		if err := utils.ValidateFile(fields[kind]); err != nil {
+			return fmt.Errorf("%w '%s': %w", ErrInvalidFile, kind, err)
 		}
 	}
 
 	return nil
 }
 
-// Generar TOTP
-func (s *minerService) GenerateTOTP(minerID uuid.UUID) (string, error) {
-	miner, err := s.repo.FindByID(minerID)
+// validateDocument valida un único documento con las mismas reglas del registro.
+// Al reemplazar un documento el archivo siempre es obligatorio.
+func validateDocument(minerType models.MinerType, kind models.DocumentKind, file *multipart.FileHeader) error {
+	fields, err := minerDocumentFields(minerType, map[string]*multipart.FileHeader{string(kind): file})
 	if err != nil {
-		return "", err
+		return err
 	}
-	if miner.TOTPSecret == "" {
-		return "", errors.New("el minero no tiene configurado un secreto TOTP")
+	field, ok := fields[kind]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidDocument, kind)
 	}
-
-	code, err := totp.GenerateCodeCustom(miner.TOTPSecret, time.Now(), totp.ValidateOpts{
-		Period:    30,
-		Digits:    otp.DigitsSix,
-		Algorithm: otp.AlgorithmSHA1,
-	})
-	if err != nil {
-		return "", err
+	field.Required = true
+	if err := utils.ValidateFile(field); err != nil {
+		return fmt.Errorf("%w '%s': %w", ErrInvalidFile, kind, err)
 	}
-	return code, nil
+	return nil
 }
 
-// Validar TOTP
-func (s *minerService) ValidateTOTP(minerID uuid.UUID, code string) (bool, error) {
+// ValidateTOTP acepta cada código una sola vez y, tras varios códigos errados
+// del mismo usuario (callerID), le bloquea el TOTP temporalmente (TOTPLockedError).
+func (s *minerService) ValidateTOTP(minerID, callerID uuid.UUID, code string) (bool, error) {
 	miner, err := s.repo.FindByID(minerID)
 	if err != nil {
 		return false, err
 	}
-	if miner.TOTPSecret == "" {
-		return false, errors.New("el minero no tiene configurado un secreto TOTP")
+	secret, err := s.totpSecret(miner)
+	if err != nil {
+		return false, err
 	}
 
-	valid, err := totp.ValidateCustom(code, miner.TOTPSecret, time.Now(), totp.ValidateOpts{
-		Period:    30,
-		Skew:      1,
-		Digits:    otp.DigitsSix,
-		Algorithm: otp.AlgorithmSHA1,
-	})
+	now := time.Now()
+	step, matched, err := matchTOTPStep(secret, code, now)
 	if err != nil {
 		return false, fmt.Errorf("error al validar código TOTP: %w", err)
 	}
-	if !valid {
-		return false, errors.New("código inválido o expirado")
+	if err := s.guardAttempt(miner.ID, callerID, now, acceptTOTPStep(step, matched)); err != nil {
+		return false, err
 	}
 	return true, nil
 }
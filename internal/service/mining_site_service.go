@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/geo"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+var (
+	ErrSiteLocationRequired = errors.New("el sitio debe tener coordenadas o un polígono")
+	ErrTitleNumberRequired  = errors.New("los mineros titulares deben indicar el número de título minero")
+	ErrTitleNumberTaken     = errors.New("el título minero ya está registrado")
+	ErrSiteNotOwned         = errors.New("el sitio de origen no pertenece al minero de la venta")
+)
+
+// MiningSiteService registra los sitios de extracción de los mineros.
+type MiningSiteService interface {
+	CreateSite(minerID, userID uuid.UUID, req *models.CreateMiningSiteRequest) (*models.MiningSite, error)
+	ListSites(minerID uuid.UUID) ([]models.MiningSite, error)
+
+	// OriginSite devuelve el sitio si pertenece al minero, para atar una venta a su origen.
+	OriginSite(miner *models.Miner, siteID uuid.UUID) (*models.MiningSite, error)
+}
+
+type miningSiteService struct {
+	repo         repository.MiningSiteRepository
+	minerService MinerService
+	gazetteer    geo.Gazetteer // nil solo en desarrollo: sin catálogo de municipios, solo se valida el país
+}
+
+func NewMiningSiteService(repo repository.MiningSiteRepository, minerService MinerService, gazetteer geo.Gazetteer) MiningSiteService {
+	return &miningSiteService{repo: repo, minerService: minerService, gazetteer: gazetteer}
+}
+
+// CreateSite registra un sitio del minero. Los titulares registran su título
+// (con número obligatorio); los de subsistencia, su zona de extracción.
+func (s *miningSiteService) CreateSite(minerID, userID uuid.UUID, req *models.CreateMiningSiteRequest) (*models.MiningSite, error) {
+	miner, err := s.minerService.GetMinerByID(minerID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.UserID != userID {
+		return nil, ErrNotMinerOwner
+	}
+
+	site := &models.MiningSite{
+		MinerID:      miner.ID,
+		Name:         strings.TrimSpace(req.Name),
+		Municipality: strings.TrimSpace(req.Municipality),
+		Department:   strings.TrimSpace(req.Department),
+		Mineral:      strings.ToLower(strings.TrimSpace(req.Mineral)),
+		Polygon:      req.Polygon,
+	}
+	if site.Mineral == "" {
+		site.Mineral = "oro"
+	}
+
+	switch miner.MinerType {
+	case models.TitularMiner:
+		title := strings.ToUpper(strings.TrimSpace(req.TitleNumber))
+		if title == "" {
+			return nil, ErrTitleNumberRequired
+		}
+		site.Kind = models.SiteTitled
+		site.TitleNumber = &title
+	default:
+		site.Kind = models.SiteSubsistenceZone
+	}
+
+	point, err := s.validateLocation(site, req)
+	if err != nil {
+		return nil, err
+	}
+	site.Latitude, site.Longitude = point.Lat, point.Lon
+
+	if err := s.repo.Create(site); err != nil {
+		if errors.Is(err, repository.ErrTitleNumberExists) {
+			return nil, ErrTitleNumberTaken
+		}
+		return nil, fmt.Errorf("fallo al guardar el sitio minero: %w", err)
+	}
+	return site, nil
+}
+
+// validateLocation verifica el punto y cada vértice del polígono contra los
+// límites de Colombia y, si hay catálogo, contra el municipio declarado.
+// Devuelve el punto de referencia del sitio.
+func (s *miningSiteService) validateLocation(site *models.MiningSite, req *models.CreateMiningSiteRequest) (geo.Point, error) {
+	var point geo.Point
+	switch {
+	case req.Latitude != nil && req.Longitude != nil:
+		point = geo.Point{Lat: *req.Latitude, Lon: *req.Longitude}
+	case len(req.Polygon) > 0:
+		point = geo.Centroid(req.Polygon)
+	default:
+		return point, ErrSiteLocationRequired
+	}
+
+	for _, p := range append([]geo.Point{point}, req.Polygon...) {
+		if !geo.InColombia(p) {
+			return point, fmt.Errorf("%w: (%.6f, %.6f)", geo.ErrOutsideColombia, p.Lat, p.Lon)
+		}
+		if s.gazetteer != nil {
+			if err := s.gazetteer.CheckMunicipality(site.Department, site.Municipality, p); err != nil {
+				return point, err
+			}
+		}
+	}
+	return point, nil
+}
+
+func (s *miningSiteService) ListSites(minerID uuid.UUID) ([]models.MiningSite, error) {
+	return s.repo.FindByMiner(minerID)
+}
+
+func (s *miningSiteService) OriginSite(miner *models.Miner, siteID uuid.UUID) (*models.MiningSite, error) {
+	site, err := s.repo.FindByID(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.MinerID != miner.ID {
+		return nil, ErrSiteNotOwned
+	}
+	return site, nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/payout"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+const (
+	// payoutLease es cuánto se reserva un pago mientras se envía al proveedor.
+	payoutLease = 2 * time.Minute
+	// payoutRetryBase es la espera tras el primer fallo temporal; se duplica en cada intento.
+	payoutRetryBase = time.Minute
+	payoutRetryMax  = time.Hour
+	// payoutPollAfter es cuánto se espera un webhook antes de consultar el estado al proveedor.
+	payoutPollAfter = 10 * time.Minute
+	payoutBatchSize = 50
+)
+
+var ErrUnknownPayoutProvider = errors.New("proveedor de pagos desconocido")
+
+// PayoutService envía el saldo de los mineros a sus cuentas. Al solicitar el
+// pago los fondos pasan a la cuenta retenida; se dan por pagados cuando el
+// proveedor confirma y se liberan solo si el proveedor lo rechaza o informa
+// que falló. Mientras el resultado sea incierto los fondos siguen retenidos.
+type PayoutService interface {
+	RequestPayout(minerID, userID uuid.UUID, req *models.CreatePayoutRequest) (*models.Payout, error)
+	GetPayout(id uuid.UUID) (*models.Payout, error)
+	ListMinerPayouts(minerID uuid.UUID, page, limit int) (*utils.Pagination, error)
+	HandleWebhook(providerName string, header http.Header, body []byte) error
+	ProcessPending(ctx context.Context) (int, error)
+}
+
+type payoutService struct {
+	repo         repository.PayoutRepository
+	minerService MinerService
+	providers    *payout.Registry
+	maxAttempts  int
+}
+
+func NewPayoutService(repo repository.PayoutRepository, minerService MinerService, providers *payout.Registry, maxAttempts int) PayoutService {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &payoutService{repo: repo, minerService: minerService, providers: providers, maxAttempts: maxAttempts}
+}
+
+func (s *payoutService) RequestPayout(minerID, userID uuid.UUID, req *models.CreatePayoutRequest) (*models.Payout, error) {
+	miner, err := s.minerService.GetMinerByID(minerID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.UserID != userID {
+		return nil, ErrNotMinerOwner
+	}
+	if miner.VerificationStatus != models.VerificationApproved {
+		return nil, ErrMinerNotApproved
+	}
+
+	dest := req.Destination()
+	if err := dest.Validate(); err != nil {
+		return nil, err
+	}
+	provider, err := s.providers.ForMethod(dest.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	// El minero confirma el retiro con su código vigente
+	if _, err := s.minerService.ValidateTOTP(miner.ID, userID, req.TOTPCode); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	p := &models.Payout{
+		ID:            uuid.New(),
+		MinerID:       miner.ID,
+		AmountCOP:     req.AmountCOP,
+		Method:        dest.Method,
+		Provider:      provider.Name(),
+		Destination:   dest,
+		Status:        models.PayoutPending,
+		NextAttemptAt: &now,
+	}
+	hold := holdJournal(miner.ID, p.AmountCOP, p.ID, payoutJournalKey(p, "hold"))
+	if err := s.repo.CreateWithHold(p, hold); err != nil {
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("fallo al registrar el pago: %w", err)
+	}
+
+	// El envío al proveedor queda para el proceso periódico (NextAttemptAt = ahora):
+	// un proveedor lento no debe retener la petición del minero
+	return s.repo.FindByID(p.ID)
+}
+
+func (s *payoutService) GetPayout(id uuid.UUID) (*models.Payout, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *payoutService) ListMinerPayouts(minerID uuid.UUID, page, limit int) (*utils.Pagination, error) {
+	return s.repo.FindByMinerPaginated(minerID, page, limit)
+}
+
+// HandleWebhook aplica la notificación del proveedor. Las notificaciones
+// repetidas o sobre pagos ya finalizados se ignoran.
+func (s *payoutService) HandleWebhook(providerName string, header http.Header, body []byte) error {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return ErrUnknownPayoutProvider
+	}
+	event, err := provider.HandleWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.repo.FindByProviderRef(provider.Name(), event.ProviderRef)
+	// Un pago incierto puede no tener aún la referencia del proveedor: se busca por la orden
+	if errors.Is(err, repository.ErrPayoutNotFound) && event.PayoutID != "" {
+		if id, parseErr := uuid.Parse(event.PayoutID); parseErr == nil {
+			p, err = s.repo.FindByID(id)
+			if err == nil && p.Provider != provider.Name() {
+				err = repository.ErrPayoutNotFound
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return s.apply(p, &payout.Result{ProviderRef: event.ProviderRef, Status: event.Status, Reason: event.Reason})
+}
+
+// ProcessPending envía los pagos pendientes vencidos, nuevos o por reintentar,
+// y consulta el estado de los enviados o inciertos que no han recibido
+// confirmación. Devuelve cuántos revisó.
+func (s *payoutService) ProcessPending(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	due, err := s.repo.FindDue(now, payoutBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i := range due {
+		if err := s.attempt(ctx, &due[i]); err != nil {
+			log.Printf("Error al reintentar el pago %s: %v", due[i].ID, err)
+		}
+	}
+
+	stale, err := s.repo.FindUnresolvedBefore(now.Add(-payoutPollAfter), payoutBatchSize)
+	if err != nil {
+		return len(due), err
+	}
+	for i := range stale {
+		if err := s.poll(ctx, &stale[i]); err != nil {
+			log.Printf("Error al consultar el pago %s: %v", stale[i].ID, err)
+		}
+	}
+	return len(due) + len(stale), nil
+}
+
+// attempt envía el pago al proveedor. Un fallo temporal agenda otro intento
+// con espera exponencial y solo el rechazo del primer envío hace fallar el
+// pago. Al agotar los intentos, ante una respuesta que no se entiende o ante un
+// rechazo o conflicto en un reintento, el proveedor pudo haber recibido la
+// orden: el pago queda incierto hasta consultarlo.
+func (s *payoutService) attempt(ctx context.Context, p *models.Payout) error {
+	provider, ok := s.providers.Get(p.Provider)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownPayoutProvider, p.Provider)
+	}
+	if err := s.repo.ClaimAttempt(p, time.Now().UTC().Add(payoutLease)); err != nil {
+		if errors.Is(err, repository.ErrPayoutStateChanged) {
+			return nil // Otro proceso lo está enviando
+		}
+		return err
+	}
+
+	result, err := provider.Initiate(ctx, payoutRequest(p))
+	if err == nil {
+		return s.apply(p, result)
+	}
+
+	switch {
+	case errors.Is(err, payout.ErrRejected) && p.Attempts == 1:
+		return s.fail(p, err.Error())
+	case errors.Is(err, payout.ErrTemporary) && p.Attempts < s.maxAttempts:
+		next := time.Now().UTC().Add(payoutRetryDelay(p.Attempts))
+		p.NextAttemptAt = &next
+		p.LastError = err.Error()
+		return s.repo.Transition(p, models.PayoutPending, nil)
+	default:
+		p.Status = models.PayoutUnknown
+		p.NextAttemptAt = nil
+		p.LastError = err.Error()
+		return s.repo.Transition(p, models.PayoutPending, nil)
+	}
+}
+
+// poll consulta al proveedor un pago enviado o incierto del que no llegó el
+// webhook. Si nunca se supo su referencia, se reenvía la orden con la misma
+// llave de idempotencia: el proveedor devuelve la existente sin pagar dos veces.
+// Solo un estado fallido informado por el proveedor libera los fondos; un error
+// al consultar, incluido un 4xx o un 409 al reenviar, deja el pago como está.
+func (s *payoutService) poll(ctx context.Context, p *models.Payout) error {
+	provider, ok := s.providers.Get(p.Provider)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownPayoutProvider, p.Provider)
+	}
+	if p.ProviderRef == nil {
+		result, err := provider.Initiate(ctx, payoutRequest(p))
+		if err != nil {
+			return err
+		}
+		return s.apply(p, result)
+	}
+	result, err := provider.Status(ctx, *p.ProviderRef)
+	if err != nil {
+		return err
+	}
+	return s.apply(p, result)
+}
+
+// apply lleva el pago al estado que informa el proveedor.
+func (s *payoutService) apply(p *models.Payout, result *payout.Result) error {
+	if p.IsFinal() {
+		return nil
+	}
+	if result.ProviderRef != "" {
+		ref := result.ProviderRef
+		p.ProviderRef = &ref
+	}
+
+	switch result.Status {
+	case payout.StatusSucceeded:
+		return s.succeed(p)
+	case payout.StatusFailed:
+		return s.fail(p, result.Reason)
+	default:
+		if p.Status == models.PayoutSubmitted {
+			return nil
+		}
+		from := p.Status
+		p.Status = models.PayoutSubmitted
+		p.NextAttemptAt = nil
+		p.LastError = ""
+		return s.repo.Transition(p, from, nil)
+	}
+}
+
+func (s *payoutService) succeed(p *models.Payout) error {
+	from := p.Status
+	now := time.Now().UTC()
+	p.Status = models.PayoutSucceeded
+	p.NextAttemptAt = nil
+	p.CompletedAt = &now
+	return s.repo.Transition(p, from, payoutJournal(p.MinerID, p.AmountCOP, p.ID, payoutJournalKey(p, "settle")))
+}
+
+func (s *payoutService) fail(p *models.Payout, reason string) error {
+	from := p.Status
+	now := time.Now().UTC()
+	p.Status = models.PayoutFailed
+	p.NextAttemptAt = nil
+	p.CompletedAt = &now
+	if reason != "" {
+		p.LastError = reason
+	}
+	return s.repo.Transition(p, from, releaseJournal(p.MinerID, p.AmountCOP, p.ID, payoutJournalKey(p, "release")))
+}
+
+// payoutRequest arma la orden del pago; su ID es la llave de idempotencia.
+func payoutRequest(p *models.Payout) payout.Request {
+	return payout.Request{
+		PayoutID:    p.ID.String(),
+		AmountCOP:   p.AmountCOP,
+		Destination: p.Destination,
+		Description: "Pago Batea " + p.ID.String()[:8],
+	}
+}
+
+// payoutRetryDelay duplica la espera en cada intento hasta payoutRetryMax.
+func payoutRetryDelay(attempt int) time.Duration {
+	delay := payoutRetryBase
+	for i := 1; i < attempt && delay < payoutRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > payoutRetryMax {
+		delay = payoutRetryMax
+	}
+	return delay
+}
+
+// payoutJournalKey da a cada asiento del pago una llave de idempotencia propia.
+func payoutJournalKey(p *models.Payout, step string) string {
+	return "payout:" + p.ID.String() + ":" + step
+}
+
+// PayoutWorker procesa periódicamente los envíos, los reintentos y las consultas de estado.
+type PayoutWorker struct {
+	payouts PayoutService
+}
+
+func NewPayoutWorker(payouts PayoutService) *PayoutWorker {
+	return &PayoutWorker{payouts: payouts}
+}
+
+// Start ejecuta ProcessPending cada interval hasta que se cancele ctx.
+func (w *PayoutWorker) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := w.payouts.ProcessPending(ctx); err != nil {
+					log.Printf("Error al procesar pagos pendientes: %v", err)
+				}
+			}
+		}
+	}()
+}
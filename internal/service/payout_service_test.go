@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/payout"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+// memoryPayouts es un PayoutRepository en memoria que recuerda los asientos contabilizados.
+type memoryPayouts struct {
+	payouts  map[uuid.UUID]models.Payout
+	journals []models.JournalKind
+}
+
+func newMemoryPayouts(p models.Payout) *memoryPayouts {
+	return &memoryPayouts{payouts: map[uuid.UUID]models.Payout{p.ID: p}}
+}
+
+func (m *memoryPayouts) CreateWithHold(p *models.Payout, hold *models.JournalTransaction) error {
+	m.payouts[p.ID] = *p
+	m.journals = append(m.journals, hold.Kind)
+	return nil
+}
+
+func (m *memoryPayouts) FindByID(id uuid.UUID) (*models.Payout, error) {
+	p, ok := m.payouts[id]
+	if !ok {
+		return nil, repository.ErrPayoutNotFound
+	}
+	return &p, nil
+}
+
+func (m *memoryPayouts) FindByProviderRef(provider, ref string) (*models.Payout, error) {
+	for _, p := range m.payouts {
+		if p.Provider == provider && p.ProviderRef != nil && *p.ProviderRef == ref {
+			return &p, nil
+		}
+	}
+	return nil, repository.ErrPayoutNotFound
+}
+
+func (m *memoryPayouts) FindByMinerPaginated(uuid.UUID, int, int) (*utils.Pagination, error) {
+	return nil, nil
+}
+
+func (m *memoryPayouts) FindDue(time.Time, int) ([]models.Payout, error) {
+	return nil, nil
+}
+
+func (m *memoryPayouts) FindUnresolvedBefore(time.Time, int) ([]models.Payout, error) {
+	return nil, nil
+}
+
+func (m *memoryPayouts) ClaimAttempt(p *models.Payout, leaseUntil time.Time) error {
+	stored := m.payouts[p.ID]
+	if stored.Status != models.PayoutPending || stored.Attempts != p.Attempts {
+		return repository.ErrPayoutStateChanged
+	}
+	p.Attempts++
+	p.NextAttemptAt = &leaseUntil
+	m.payouts[p.ID] = *p
+	return nil
+}
+
+func (m *memoryPayouts) Transition(p *models.Payout, from models.PayoutStatus, journal *models.JournalTransaction) error {
+	if m.payouts[p.ID].Status != from {
+		return repository.ErrPayoutStateChanged
+	}
+	m.payouts[p.ID] = *p
+	if journal != nil {
+		m.journals = append(m.journals, journal.Kind)
+	}
+	return nil
+}
+
+func testPayout(attempts int) models.Payout {
+	now := time.Now().UTC()
+	return models.Payout{
+		ID:            uuid.New(),
+		MinerID:       uuid.New(),
+		AmountCOP:     250_000,
+		Method:        payout.MethodNequi,
+		Provider:      "fake",
+		Status:        models.PayoutPending,
+		Attempts:      attempts,
+		NextAttemptAt: &now,
+	}
+}
+
+func TestPayoutAttemptOutcomes(t *testing.T) {
+	const maxAttempts = 3
+
+	tests := []struct {
+		name         string
+		autoComplete bool
+		attempts     int // intentos previos
+		fail         error
+		wantStatus   models.PayoutStatus
+		wantJournals []models.JournalKind
+	}{
+		{name: "aceptado queda enviado", wantStatus: models.PayoutSubmitted},
+		{name: "pagado de inmediato", autoComplete: true, wantStatus: models.PayoutSucceeded, wantJournals: []models.JournalKind{models.JournalPayout}},
+		{name: "rechazo definitivo libera los fondos", fail: fmt.Errorf("%w: cuenta inválida", payout.ErrRejected), wantStatus: models.PayoutFailed, wantJournals: []models.JournalKind{models.JournalRelease}},
+		// Un envío anterior pudo llegar al proveedor: el rechazo del reintento no prueba que no pagó
+		{name: "rechazo en un reintento queda incierto", attempts: 1, fail: fmt.Errorf("%w: 422", payout.ErrRejected), wantStatus: models.PayoutUnknown},
+		{name: "conflicto de idempotencia queda incierto", fail: fmt.Errorf("%w: 409", payout.ErrConflict), wantStatus: models.PayoutUnknown},
+		{name: "falla temporal se reintenta", fail: fmt.Errorf("%w: 503", payout.ErrTemporary), wantStatus: models.PayoutPending},
+		{name: "falla temporal sin más intentos queda incierta", attempts: maxAttempts - 1, fail: fmt.Errorf("%w: timeout", payout.ErrTemporary), wantStatus: models.PayoutUnknown},
+		{name: "respuesta ininteligible queda incierta", fail: fmt.Errorf("%w: 'on_hold'", payout.ErrUnknownPayoutStatus), wantStatus: models.PayoutUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPayout(tt.attempts)
+			repo := newMemoryPayouts(p)
+			provider := payout.NewFakeProvider(tt.autoComplete)
+			if tt.fail != nil {
+				provider.FailNext(tt.fail)
+			}
+			s := NewPayoutService(repo, nil, payout.NewRegistry(provider), maxAttempts).(*payoutService)
+
+			if err := s.attempt(context.Background(), &p); err != nil {
+				t.Fatalf("attempt: %v", err)
+			}
+			got := repo.payouts[p.ID]
+			if got.Status != tt.wantStatus {
+				t.Fatalf("estado = %s, se esperaba %s", got.Status, tt.wantStatus)
+			}
+			if fmt.Sprint(repo.journals) != fmt.Sprint(tt.wantJournals) {
+				t.Fatalf("asientos = %v, se esperaba %v", repo.journals, tt.wantJournals)
+			}
+			if tt.wantStatus == models.PayoutPending && (got.NextAttemptAt == nil || !got.NextAttemptAt.After(time.Now())) {
+				t.Fatalf("el reintento no quedó agendado: %v", got.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestPayoutPollResolvesUnknown(t *testing.T) {
+	tests := []struct {
+		name         string
+		autoComplete bool
+		reported     payout.Status // estado final que el proveedor ya tiene para la orden
+		fail         error
+		wantErr      bool
+		wantStatus   models.PayoutStatus
+		wantJournals []models.JournalKind
+	}{
+		{name: "el proveedor lo había recibido", wantStatus: models.PayoutSubmitted},
+		{name: "el proveedor lo había pagado", autoComplete: true, wantStatus: models.PayoutSucceeded, wantJournals: []models.JournalKind{models.JournalPayout}},
+		{name: "el proveedor informa que falló", reported: payout.StatusFailed, wantStatus: models.PayoutFailed, wantJournals: []models.JournalKind{models.JournalRelease}},
+		// El reenvío de una orden que el proveedor ya tiene puede responder 409 o
+		// 4xx aunque ya la haya pagado: liberar los fondos pagaría dos veces
+		{name: "conflicto de idempotencia", fail: fmt.Errorf("%w: 409", payout.ErrConflict), wantErr: true, wantStatus: models.PayoutUnknown},
+		{name: "rechazo al reenviar", fail: fmt.Errorf("%w: 400", payout.ErrRejected), wantErr: true, wantStatus: models.PayoutUnknown},
+		{name: "el proveedor sigue sin responder", fail: payout.ErrTemporary, wantErr: true, wantStatus: models.PayoutUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPayout(3)
+			p.Status = models.PayoutUnknown
+			p.NextAttemptAt = nil
+			repo := newMemoryPayouts(p)
+			provider := payout.NewFakeProvider(tt.autoComplete)
+			if tt.reported != "" {
+				sent, _ := provider.Initiate(context.Background(), payoutRequest(&p))
+				provider.Complete(sent.ProviderRef, tt.reported, "cuenta cerrada")
+			}
+			if tt.fail != nil {
+				provider.FailNext(tt.fail)
+			}
+			s := NewPayoutService(repo, nil, payout.NewRegistry(provider), 3).(*payoutService)
+
+			// Sin referencia del proveedor se reenvía la orden con la misma llave de idempotencia
+			if err := s.poll(context.Background(), &p); (err != nil) != tt.wantErr {
+				t.Fatalf("poll = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+			got := repo.payouts[p.ID]
+			if got.Status != tt.wantStatus {
+				t.Fatalf("estado = %s, se esperaba %s", got.Status, tt.wantStatus)
+			}
+			if fmt.Sprint(repo.journals) != fmt.Sprint(tt.wantJournals) {
+				t.Fatalf("asientos = %v, se esperaba %v", repo.journals, tt.wantJournals)
+			}
+		})
+	}
+}
+
+func TestPayoutRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+		{30, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := payoutRetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("payoutRetryDelay(%d) = %s, se esperaba %s", tt.attempt, got, tt.want)
+		}
+	}
+}
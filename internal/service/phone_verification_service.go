@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+var (
+	ErrPhoneCodeInvalid     = errors.New("código de verificación inválido")
+	ErrPhoneCodeExpired     = errors.New("el código de verificación expiró, solicite uno nuevo")
+	ErrPhoneCodeMaxAttempts = errors.New("se superó el número de intentos, solicite un código nuevo")
+	ErrPhoneCodeCooldown    = errors.New("debe esperar antes de solicitar otro código")
+	ErrPhoneCodeLimit       = errors.New("se alcanzó el máximo de códigos por hora, intente más tarde")
+)
+
+// PhoneVerificationService maneja el envío y la validación de códigos por SMS.
+type PhoneVerificationService interface {
+	RequestChallenge(phoneNumber string) error
+	Verify(phoneNumber, code string) error
+	ConfirmIdentity(userID uuid.UUID, code string) error
+}
+
+type phoneVerificationService struct {
+	repo     repository.PhoneVerificationRepository
+	userRepo repository.UserRepository
+	sender   SMSSender
+}
+
+// NewPhoneVerificationService crea una nueva instancia del servicio de verificación por SMS.
+func NewPhoneVerificationService(
+	repo repository.PhoneVerificationRepository,
+	userRepo repository.UserRepository,
+	sender SMSSender,
+) PhoneVerificationService {
+	return &phoneVerificationService{
+		repo:     repo,
+		userRepo: userRepo,
+		sender:   sender,
+	}
+}
+
+// RequestChallenge genera un código nuevo y lo envía por SMS.
+// Para no revelar qué números están registrados, un número desconocido no produce error.
+func (s *phoneVerificationService) RequestChallenge(phoneNumber string) error {
+	user, err := s.userRepo.FindByPhone(phoneNumber)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	latest, err := s.repo.FindLatestActive(user.ID)
+	if err != nil && !errors.Is(err, repository.ErrPhoneVerificationNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < models.PhoneCodeResendDelay {
+		return ErrPhoneCodeCooldown
+	}
+	issued, err := s.repo.CountIssuedSince(user.ID, user.PhoneNumber, time.Now().Add(-models.PhoneCodeWindow))
+	if err != nil {
+		return err
+	}
+	if issued >= models.PhoneCodeMaxPerWindow {
+		return ErrPhoneCodeLimit
+	}
+
+	code, err := utils.GenerateNumericCode(models.PhoneCodeLength)
+	if err != nil {
+		return fmt.Errorf("error generando código: %w", err)
+	}
+	hash, err := utils.HashPassword(code)
+	if err != nil {
+		return ErrPasswordHash
+	}
+
+	// Solo un código activo por usuario
+	if err := s.repo.InvalidateActive(user.ID); err != nil {
+		return err
+	}
+	challenge := &models.PhoneVerification{
+		UserID:      user.ID,
+		PhoneNumber: user.PhoneNumber,
+		CodeHash:    hash,
+		ExpiresAt:   time.Now().Add(models.PhoneCodeTTL),
+	}
+	if err := s.repo.Create(challenge); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Batea: tu código de verificación es %s. Vence en %d minutos.", code, int(models.PhoneCodeTTL.Minutes()))
+	if err := s.sender.Send(user.PhoneNumber, msg); err != nil {
+		if invErr := s.repo.InvalidateActive(user.ID); invErr != nil {
+			log.Printf("Error al invalidar código no enviado: %v", invErr)
+		}
+		return fmt.Errorf("no se pudo enviar el SMS: %w", err)
+	}
+	return nil
+}
+
+// Verify comprueba el código y, si es correcto, marca el teléfono del usuario como verificado.
+func (s *phoneVerificationService) Verify(phoneNumber, code string) error {
+	user, err := s.userRepo.FindByPhone(phoneNumber)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrPhoneCodeInvalid
+		}
+		return err
+	}
+
+	if err := s.consumeCode(user, code); err != nil {
+		return err
+	}
+	return s.userRepo.UpdateVerificationStatus(user.ID.String(), true)
+}
+
+// ConfirmIdentity consume el código SMS vigente del usuario autenticado para
+// probar que aún controla su teléfono, sin cambiar su estado de verificación.
+func (s *phoneVerificationService) ConfirmIdentity(userID uuid.UUID, code string) error {
+	user, err := s.userRepo.FindByID(userID.String())
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrPhoneCodeInvalid
+		}
+		return err
+	}
+	return s.consumeCode(user, code)
+}
+
+// consumeCode valida el último código activo del usuario y lo consume.
+func (s *phoneVerificationService) consumeCode(user *models.User, code string) error {
+	challenge, err := s.repo.FindLatestActive(user.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPhoneVerificationNotFound) {
+			return ErrPhoneCodeInvalid
+		}
+		return err
+	}
+	if challenge.PhoneNumber != user.PhoneNumber {
+		return ErrPhoneCodeInvalid
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return ErrPhoneCodeExpired
+	}
+
+	// El intento se cuenta antes de comparar para que el límite aplique también a peticiones concurrentes
+	ok, err := s.repo.RegisterAttempt(challenge.ID, models.PhoneCodeMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPhoneCodeMaxAttempts
+	}
+	if !utils.CheckPassword(challenge.CodeHash, code) {
+		return ErrPhoneCodeInvalid
+	}
+
+	consumed, err := s.repo.MarkConsumed(challenge.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrPhoneCodeInvalid
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/pricing"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+const (
+	// priceLookback es cuántos días hacia atrás se piden a las fuentes en cada
+	// actualización, para recoger correcciones y días sin publicación.
+	priceLookback = 7 * 24 * time.Hour
+	// referenceMaxAge es la antigüedad máxima del precio con el que se compara una
+	// venta (cubre fines de semana y festivos sin publicación).
+	referenceMaxAge = 5 * 24 * time.Hour
+	// maxPriceRange limita el rango de fechas de una consulta.
+	maxPriceRange = 366 * 24 * time.Hour
+)
+
+var ErrInvalidPriceRange = errors.New("rango de fechas inválido: 'from' debe ser anterior a 'to' y abarcar máximo un año")
+
+// referencePreference es el orden en que se busca el precio para comparar una
+// venta: primero el precio interno, luego el spot internacional.
+var referencePreference = []pricing.Kind{pricing.KindBanRep, pricing.KindInternationalSpot}
+
+// PriceService guarda el histórico de precios de referencia y marca las ventas
+// pagadas muy por debajo de ellos.
+type PriceService interface {
+	Refresh(ctx context.Context, from, to time.Time) (int, error)
+	ListPrices(from, to time.Time, kind pricing.Kind) ([]models.ReferencePrice, error)
+	AssessSale(sale *models.Sale) error
+}
+
+type priceService struct {
+	repo             repository.PriceRepository
+	sources          []pricing.Source
+	flagBelowPercent float64
+}
+
+// NewPriceService recibe las fuentes en orden: si dos traen el mismo día y
+// serie, queda el valor de la última.
+func NewPriceService(repo repository.PriceRepository, sources []pricing.Source, flagBelowPercent float64) PriceService {
+	return &priceService{repo: repo, sources: sources, flagBelowPercent: flagBelowPercent}
+}
+
+// Refresh consulta todas las fuentes y guarda lo que entreguen. Una fuente que
+// falla no impide guardar las demás; se devuelve el primer error.
+func (s *priceService) Refresh(ctx context.Context, from, to time.Time) (int, error) {
+	var firstErr error
+	stored := 0
+	for _, source := range s.sources {
+		quotes, err := source.Fetch(ctx, from, to)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("fuente de precios %s: %w", source.Name(), err)
+			}
+			continue
+		}
+		prices := make([]models.ReferencePrice, 0, len(quotes))
+		for _, q := range quotes {
+			prices = append(prices, models.ReferencePrice{
+				Date:            q.Date,
+				Kind:            q.Kind,
+				PricePerGramCOP: q.PricePerGramCOP,
+				Source:          q.Source,
+			})
+		}
+		if err := s.repo.Upsert(prices); err != nil {
+			return stored, err
+		}
+		stored += len(prices)
+	}
+	return stored, firstErr
+}
+
+func (s *priceService) ListPrices(from, to time.Time, kind pricing.Kind) ([]models.ReferencePrice, error) {
+	from, to = pricing.Day(from), pricing.Day(to)
+	if to.Before(from) || to.Sub(from) > maxPriceRange {
+		return nil, ErrInvalidPriceRange
+	}
+	return s.repo.FindRange(from, to, kind)
+}
+
+// AssessSale compara lo que se pagó por gramo de oro fino con el precio de
+// referencia del día de la venta y la marca si quedó por debajo del umbral.
+// Sin precio de referencia reciente la venta queda sin comparar.
+func (s *priceService) AssessSale(sale *models.Sale) error {
+	if sale.FineGoldGrams <= 0 {
+		return nil
+	}
+	day := pricing.Day(sale.CreatedAt.In(colombiaTime))
+
+	for _, kind := range referencePreference {
+		ref, err := s.repo.FindLatest(kind, day, day.Add(-referenceMaxAge))
+		if errors.Is(err, repository.ErrReferencePriceNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		refKind := string(ref.Kind)
+		refDate := ref.Date
+		sale.ReferencePricePerGramCOP = &ref.PricePerGramCOP
+		sale.ReferencePriceKind = &refKind
+		sale.ReferencePriceDate = &refDate
+
+		paidPerFineGram := float64(sale.TotalCOP) / sale.FineGoldGrams
+		floor := float64(ref.PricePerGramCOP) * (1 - s.flagBelowPercent/100)
+		sale.BelowReference = paidPerFineGram < floor
+		return nil
+	}
+	return nil
+}
+
+// PriceRefresher actualiza periódicamente los precios de referencia.
+type PriceRefresher struct {
+	prices PriceService
+}
+
+func NewPriceRefresher(prices PriceService) *PriceRefresher {
+	return &PriceRefresher{prices: prices}
+}
+
+// Start actualiza de inmediato y luego cada interval hasta que se cancele ctx.
+func (r *PriceRefresher) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			now := time.Now().In(colombiaTime)
+			if n, err := r.prices.Refresh(ctx, now.Add(-priceLookback), now); err != nil {
+				log.Printf("Error al actualizar los precios de referencia: %v", err)
+			} else if n > 0 {
+				log.Printf("Precios de referencia actualizados: %d cotizaciones", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
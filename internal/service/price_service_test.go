@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/pricing"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+// memoryPrices es un PriceRepository en memoria.
+type memoryPrices struct {
+	prices []models.ReferencePrice
+}
+
+func (m *memoryPrices) Upsert(prices []models.ReferencePrice) error {
+	m.prices = append(m.prices, prices...)
+	return nil
+}
+
+func (m *memoryPrices) FindRange(from, to time.Time, kind pricing.Kind) ([]models.ReferencePrice, error) {
+	return nil, nil
+}
+
+func (m *memoryPrices) FindLatest(kind pricing.Kind, onOrBefore, notBefore time.Time) (*models.ReferencePrice, error) {
+	var latest *models.ReferencePrice
+	for i, p := range m.prices {
+		if p.Kind != kind || p.Date.After(onOrBefore) || p.Date.Before(notBefore) {
+			continue
+		}
+		if latest == nil || p.Date.After(latest.Date) {
+			latest = &m.prices[i]
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrReferencePriceNotFound
+	}
+	return latest, nil
+}
+
+func refPrice(date string, kind pricing.Kind, cop int64) models.ReferencePrice {
+	d, _ := pricing.ParseDay(date)
+	return models.ReferencePrice{Date: d, Kind: kind, PricePerGramCOP: cop, Source: "prueba"}
+}
+
+func TestAssessSale(t *testing.T) {
+	// 15:00 del 16 de octubre en Colombia
+	soldAt := time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		prices    []models.ReferencePrice
+		soldAt    time.Time
+		totalCOP  int64 // por 10 g de oro fino
+		wantKind  pricing.Kind
+		wantDate  string
+		wantBelow bool
+	}{
+		{
+			name:     "pagado al precio del Banco de la República",
+			prices:   []models.ReferencePrice{refPrice("2026-10-16", pricing.KindBanRep, 350000)},
+			totalCOP: 3_500_000, wantKind: pricing.KindBanRep, wantDate: "2026-10-16",
+		},
+		{
+			name:     "justo en el umbral no se marca",
+			prices:   []models.ReferencePrice{refPrice("2026-10-16", pricing.KindBanRep, 350000)},
+			totalCOP: 3_150_000, wantKind: pricing.KindBanRep, wantDate: "2026-10-16",
+		},
+		{
+			name:     "por debajo del umbral se marca",
+			prices:   []models.ReferencePrice{refPrice("2026-10-16", pricing.KindBanRep, 350000)},
+			totalCOP: 3_149_999, wantKind: pricing.KindBanRep, wantDate: "2026-10-16", wantBelow: true,
+		},
+		{
+			name: "prefiere el precio interno al spot",
+			prices: []models.ReferencePrice{
+				refPrice("2026-10-16", pricing.KindInternationalSpot, 400000),
+				refPrice("2026-10-15", pricing.KindBanRep, 350000),
+			},
+			totalCOP: 3_200_000, wantKind: pricing.KindBanRep, wantDate: "2026-10-15",
+		},
+		{
+			name: "sin precio interno reciente usa el spot",
+			prices: []models.ReferencePrice{
+				refPrice("2026-10-10", pricing.KindBanRep, 300000),
+				refPrice("2026-10-16", pricing.KindInternationalSpot, 360000),
+			},
+			totalCOP: 3_200_000, wantKind: pricing.KindInternationalSpot, wantDate: "2026-10-16", wantBelow: true,
+		},
+		{
+			name:     "un fin de semana sin publicación usa el último precio",
+			prices:   []models.ReferencePrice{refPrice("2026-10-11", pricing.KindBanRep, 350000)},
+			totalCOP: 3_500_000, wantKind: pricing.KindBanRep, wantDate: "2026-10-11",
+		},
+		{
+			name:     "el día de la venta es el de Colombia",
+			prices:   []models.ReferencePrice{refPrice("2026-10-16", pricing.KindBanRep, 350000), refPrice("2026-10-17", pricing.KindBanRep, 500000)},
+			soldAt:   time.Date(2026, time.October, 17, 3, 0, 0, 0, time.UTC),
+			totalCOP: 3_500_000, wantKind: pricing.KindBanRep, wantDate: "2026-10-16",
+		},
+		{
+			name:     "precios viejos o futuros no sirven",
+			prices:   []models.ReferencePrice{refPrice("2026-10-10", pricing.KindBanRep, 350000), refPrice("2026-10-17", pricing.KindInternationalSpot, 350000)},
+			totalCOP: 1_000_000,
+		},
+		{name: "sin precios la venta queda sin comparar", totalCOP: 1_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPriceService(&memoryPrices{prices: tt.prices}, nil, 10)
+			sale := &models.Sale{CreatedAt: soldAt, FineGoldGrams: 10, TotalCOP: tt.totalCOP}
+			if !tt.soldAt.IsZero() {
+				sale.CreatedAt = tt.soldAt
+			}
+			if err := s.AssessSale(sale); err != nil {
+				t.Fatalf("AssessSale: %v", err)
+			}
+			if tt.wantKind == "" {
+				if sale.ReferencePriceKind != nil || sale.BelowReference {
+					t.Fatalf("AssessSale = %+v, se esperaba la venta sin comparar", sale)
+				}
+				return
+			}
+			if sale.ReferencePriceKind == nil || *sale.ReferencePriceKind != string(tt.wantKind) ||
+				sale.ReferencePriceDate.Format("2006-01-02") != tt.wantDate {
+				t.Fatalf("referencia = %v del %v, se esperaba %s del %s", sale.ReferencePriceKind, sale.ReferencePriceDate, tt.wantKind, tt.wantDate)
+			}
+			if sale.BelowReference != tt.wantBelow {
+				t.Fatalf("BelowReference = %v, se esperaba %v", sale.BelowReference, tt.wantBelow)
+			}
+		})
+	}
+}
+
+// stubPriceSource entrega cotizaciones fijas o falla.
+type stubPriceSource struct {
+	name   string
+	quotes []pricing.Quote
+	err    error
+}
+
+func (s stubPriceSource) Name() string { return s.name }
+
+func (s stubPriceSource) Fetch(context.Context, time.Time, time.Time) ([]pricing.Quote, error) {
+	return s.quotes, s.err
+}
+
+func TestPriceRefreshKeepsWorkingSources(t *testing.T) {
+	d, _ := pricing.ParseDay("2026-10-16")
+	errDown := errors.New("sin conexión")
+	repo := &memoryPrices{}
+	s := NewPriceService(repo, []pricing.Source{
+		stubPriceSource{name: "caída", err: errDown},
+		stubPriceSource{name: "archivo", quotes: []pricing.Quote{{Date: d, Kind: pricing.KindBanRep, PricePerGramCOP: 352100, Source: "archivo"}}},
+	}, 10)
+
+	n, err := s.Refresh(context.Background(), d, d)
+	if !errors.Is(err, errDown) {
+		t.Fatalf("Refresh = %v, se esperaba %v", err, errDown)
+	}
+	if n != 1 || len(repo.prices) != 1 || repo.prices[0].PricePerGramCOP != 352100 {
+		t.Fatalf("Refresh guardó %d: %+v", n, repo.prices)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+var (
+	ErrQuotaExceeded           = errors.New("la venta supera el tope de producción del minero")
+	ErrProductionNotDeclared   = errors.New("el minero titular no tiene declarada su producción anual")
+	ErrProductionNotApplicable = errors.New("solo los mineros titulares declaran producción anual")
+)
+
+// colombiaTime es la zona de los periodos calendario (Colombia no tiene horario de verano).
+var colombiaTime = time.FixedZone("COT", -5*60*60)
+
+// QuotaExceededError detalla qué tope se superaría con la venta.
+type QuotaExceededError struct {
+	Period    string // "mensual" o "anual"
+	CapGrams  float64
+	UsedGrams float64
+	Requested float64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: tope %s de %.3f g, vendidos %.3f g, disponibles %.3f g, solicitados %.3f g",
+		ErrQuotaExceeded, e.Period, e.CapGrams, e.UsedGrams, max(e.CapGrams-e.UsedGrams, 0), e.Requested)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// QuotaEngine aplica los topes de venta: los mineros de subsistencia tienen un
+// tope mensual y otro anual (configurables) y los titulares el volumen anual
+// declarado en su contrato de explotación. Se cuenta el peso bruto vendido.
+type QuotaEngine struct {
+	monthlyCap float64
+	annualCap  float64
+}
+
+func NewQuotaEngine(cfg *config.Config) *QuotaEngine {
+	return &QuotaEngine{
+		monthlyCap: cfg.SubsistenceMonthlyCapGrams,
+		annualCap:  cfg.SubsistenceAnnualCapGrams,
+	}
+}
+
+// Periods devuelve el inicio del mes y del año calendario de now, en hora de Colombia.
+func (q *QuotaEngine) Periods(now time.Time) (monthStart, yearStart time.Time) {
+	now = now.In(colombiaTime)
+	monthStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, colombiaTime)
+	yearStart = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, colombiaTime)
+	return monthStart, yearStart
+}
+
+// caps devuelve los topes del minero; nil significa que ese periodo no tiene tope.
+func (q *QuotaEngine) caps(miner *models.Miner) (monthly, annual *float64, err error) {
+	switch miner.MinerType {
+	case models.SubsistenceMiner:
+		m, a := q.monthlyCap, q.annualCap
+		return &m, &a, nil
+	case models.TitularMiner:
+		if miner.DeclaredAnnualProductionGrams == nil {
+			return nil, nil, ErrProductionNotDeclared
+		}
+		a := *miner.DeclaredAnnualProductionGrams
+		return nil, &a, nil
+	default:
+		return nil, nil, fmt.Errorf("tipo de minero no válido")
+	}
+}
+
+// Check verifica que vender grams más no supere ningún tope del minero.
+func (q *QuotaEngine) Check(miner *models.Miner, usage models.SaleUsage, grams float64) error {
+	monthly, annual, err := q.caps(miner)
+	if err != nil {
+		return err
+	}
+	// Se redondea a miligramos para que errores de punto flotante no rechacen una venta exacta
+	if monthly != nil && roundGrams(usage.MonthGrams+grams) > *monthly {
+		return &QuotaExceededError{Period: "mensual", CapGrams: *monthly, UsedGrams: usage.MonthGrams, Requested: grams}
+	}
+	if annual != nil && roundGrams(usage.YearGrams+grams) > *annual {
+		return &QuotaExceededError{Period: "anual", CapGrams: *annual, UsedGrams: usage.YearGrams, Requested: grams}
+	}
+	return nil
+}
+
+// Report arma el resumen de cupo usado y disponible del minero.
+func (q *QuotaEngine) Report(miner *models.Miner, usage models.SaleUsage, now time.Time) (*models.QuotaResponse, error) {
+	monthly, annual, err := q.caps(miner)
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(colombiaTime)
+	resp := &models.QuotaResponse{
+		MinerID:         miner.ID,
+		MinerType:       miner.MinerType,
+		Month:           now.Format("2006-01"),
+		Year:            now.Year(),
+		MonthlyCapGrams: monthly,
+		UsedMonthGrams:  usage.MonthGrams,
+		AnnualCapGrams:  annual,
+		UsedYearGrams:   usage.YearGrams,
+	}
+	if monthly != nil {
+		r := roundGrams(max(*monthly-usage.MonthGrams, 0))
+		resp.RemainingMonthGrams = &r
+	}
+	if annual != nil {
+		r := roundGrams(max(*annual-usage.YearGrams, 0))
+		resp.RemainingYearGrams = &r
+	}
+	return resp, nil
+}
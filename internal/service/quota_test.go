@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+func newTestQuota() *QuotaEngine {
+	return NewQuotaEngine(&config.Config{SubsistenceMonthlyCapGrams: 35, SubsistenceAnnualCapGrams: 420})
+}
+
+func TestQuotaPeriodsUseColombiaTime(t *testing.T) {
+	q := newTestQuota()
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantMonth time.Time
+		wantYear  time.Time
+	}{
+		{
+			name:      "mitad de mes",
+			now:       time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2026, time.June, 1, 5, 0, 0, 0, time.UTC),
+			wantYear:  time.Date(2026, time.January, 1, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			// 02:00 UTC del 1 de julio aún es 30 de junio en Colombia
+			name:      "inicio de mes en UTC sigue en el mes anterior",
+			now:       time.Date(2026, time.July, 1, 2, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2026, time.June, 1, 5, 0, 0, 0, time.UTC),
+			wantYear:  time.Date(2026, time.January, 1, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "medianoche exacta en Colombia abre el mes",
+			now:       time.Date(2026, time.July, 1, 5, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2026, time.July, 1, 5, 0, 0, 0, time.UTC),
+			wantYear:  time.Date(2026, time.January, 1, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "año nuevo en UTC sigue en el año anterior",
+			now:       time.Date(2027, time.January, 1, 4, 59, 59, 0, time.UTC),
+			wantMonth: time.Date(2026, time.December, 1, 5, 0, 0, 0, time.UTC),
+			wantYear:  time.Date(2026, time.January, 1, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "año nuevo en Colombia",
+			now:       time.Date(2027, time.January, 1, 5, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2027, time.January, 1, 5, 0, 0, 0, time.UTC),
+			wantYear:  time.Date(2027, time.January, 1, 5, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			month, year := q.Periods(tt.now)
+			if !month.Equal(tt.wantMonth) || !year.Equal(tt.wantYear) {
+				t.Fatalf("Periods(%s) = %s, %s; se esperaba %s, %s",
+					tt.now, month.UTC(), year.UTC(), tt.wantMonth, tt.wantYear)
+			}
+		})
+	}
+}
+
+func TestQuotaCheck(t *testing.T) {
+	q := newTestQuota()
+	declared := 1000.0
+	subsistence := &models.Miner{MinerType: models.SubsistenceMiner}
+	titular := &models.Miner{MinerType: models.TitularMiner, DeclaredAnnualProductionGrams: &declared}
+
+	tests := []struct {
+		name       string
+		miner      *models.Miner
+		usage      models.SaleUsage
+		grams      float64
+		wantErr    error
+		wantPeriod string
+	}{
+		{name: "subsistencia dentro del cupo", miner: subsistence, usage: models.SaleUsage{MonthGrams: 10, YearGrams: 100}, grams: 5},
+		{name: "subsistencia completa el tope mensual exacto", miner: subsistence, usage: models.SaleUsage{MonthGrams: 30, YearGrams: 30}, grams: 5},
+		// Un residuo de punto flotante por debajo del miligramo no cuenta como exceso
+		{name: "redondeo a miligramos", miner: subsistence, usage: models.SaleUsage{MonthGrams: 34.90000000000001, YearGrams: 34.9}, grams: 0.1},
+		{name: "subsistencia supera el tope mensual", miner: subsistence, usage: models.SaleUsage{MonthGrams: 30, YearGrams: 30}, grams: 5.001, wantErr: ErrQuotaExceeded, wantPeriod: "mensual"},
+		{name: "subsistencia supera el tope anual", miner: subsistence, usage: models.SaleUsage{MonthGrams: 0, YearGrams: 419}, grams: 2, wantErr: ErrQuotaExceeded, wantPeriod: "anual"},
+		{name: "titular sin tope mensual", miner: titular, usage: models.SaleUsage{MonthGrams: 500, YearGrams: 500}, grams: 400},
+		{name: "titular supera lo declarado", miner: titular, usage: models.SaleUsage{MonthGrams: 0, YearGrams: 900}, grams: 100.5, wantErr: ErrQuotaExceeded, wantPeriod: "anual"},
+		{name: "titular sin producción declarada", miner: &models.Miner{MinerType: models.TitularMiner}, grams: 1, wantErr: ErrProductionNotDeclared},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := q.Check(tt.miner, tt.usage, tt.grams)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if tt.wantPeriod == "" {
+				return
+			}
+			var exceeded *QuotaExceededError
+			if !errors.As(err, &exceeded) || exceeded.Period != tt.wantPeriod {
+				t.Fatalf("Check = %v, se esperaba exceder el tope %s", err, tt.wantPeriod)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/reporting"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/storage"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+// maxReportDays limita el periodo de un reporte.
+const maxReportDays = 366
+
+// platformName es el nombre del declarante en los reportes propios de Batea.
+const platformName = "Batea"
+
+var (
+	ErrInvalidReportPeriod = errors.New("periodo inválido: 'from' debe ser anterior o igual a 'to' y abarcar máximo un año")
+	ErrNothingToReport     = errors.New("no hay compras pendientes de reportar con esos filtros")
+	ErrInvalidReportBuyer  = errors.New("buyer_id no es un identificador válido")
+	ErrReportOtherBuyer    = errors.New("un comercializador solo puede reportar sus propias compras")
+)
+
+// ReportService genera el reporte de compras a mineros de subsistencia para la
+// autoridad minera. Con buyer nil el declarante es Batea; si no, el
+// comercializador, y solo se incluyen sus propias compras.
+type ReportService interface {
+	Preview(req *models.RegulatoryReportRequest, buyer *models.Buyer, format reporting.Format) ([]byte, error)
+	Submit(userID uuid.UUID, req *models.RegulatoryReportRequest, buyer *models.Buyer) (*models.RegulatoryReport, error)
+	GetReport(id uuid.UUID) (*models.RegulatoryReport, error)
+	ListReports(reporter string, page, limit int) (*utils.Pagination, error)
+	OpenFile(report *models.RegulatoryReport, format reporting.Format) (io.ReadCloser, error)
+}
+
+type reportService struct {
+	repo        repository.ReportRepository
+	store       storage.Storage
+	platformNIT string
+}
+
+// NewReportService recibe el NIT con que Batea presenta sus propios reportes.
+func NewReportService(repo repository.ReportRepository, store storage.Storage, platformNIT string) ReportService {
+	return &reportService{repo: repo, store: store, platformNIT: platformNIT}
+}
+
+// Preview genera el archivo con las compras aún no reportadas, sin registrar nada.
+func (s *reportService) Preview(req *models.RegulatoryReportRequest, buyer *models.Buyer, format reporting.Format) ([]byte, error) {
+	filter, header, err := s.prepare(req, buyer)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.FindUnreported(filter)
+	if err != nil {
+		return nil, err
+	}
+	toColombiaTime(records)
+
+	var buf bytes.Buffer
+	if err := reporting.Write(&buf, format, header, records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Submit genera el reporte en todos los formatos, guarda los archivos y
+// registra las ventas incluidas para que no vuelvan a reportarse.
+func (s *reportService) Submit(userID uuid.UUID, req *models.RegulatoryReportRequest, buyer *models.Buyer) (*models.RegulatoryReport, error) {
+	filter, header, err := s.prepare(req, buyer)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.FindUnreported(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrNothingToReport
+	}
+	toColombiaTime(records)
+
+	totals := reporting.Summarize(records)
+	report := &models.RegulatoryReport{
+		ID:                 uuid.New(),
+		SubmittedBy:        userID,
+		Reporter:           filter.Reporter,
+		BuyerID:            filter.BuyerID,
+		Municipality:       filter.Municipality,
+		PeriodStart:        header.PeriodStart,
+		PeriodEnd:          header.PeriodEnd,
+		SaleCount:          totals.Count,
+		TotalWeightGrams:   totals.WeightGrams,
+		TotalFineGoldGrams: totals.FineGoldGrams,
+		TotalCOP:           totals.TotalCOP,
+	}
+
+	ctx := context.Background()
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			if err := s.store.Delete(ctx, key); err != nil {
+				log.Printf("No se pudo borrar el archivo de reporte huérfano %s: %v", key, err)
+			}
+		}
+	}
+	for _, format := range reporting.Formats {
+		var buf bytes.Buffer
+		if err := reporting.Write(&buf, format, header, records); err != nil {
+			cleanup()
+			return nil, err
+		}
+		digest := sha256.Sum256(buf.Bytes())
+		key := fmt.Sprintf("reports/%s/reporte-%s-%s.%s", report.ID,
+			header.PeriodStart.Format("20060102"), header.PeriodEnd.Format("20060102"), format.Extension())
+		if err := s.store.Put(ctx, key, &buf, format.ContentType()); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("fallo al guardar el reporte: %w", err)
+		}
+		stored = append(stored, key)
+
+		switch format {
+		case reporting.FormatCSV:
+			report.CSVKey, report.CSVSHA256 = key, hex.EncodeToString(digest[:])
+		case reporting.FormatFixedWidth:
+			report.FixedWidthKey, report.FixedWidthSHA256 = key, hex.EncodeToString(digest[:])
+		}
+	}
+
+	saleIDs := make([]uuid.UUID, 0, len(records))
+	for _, r := range records {
+		saleIDs = append(saleIDs, r.SaleID)
+	}
+	if err := s.repo.CreateWithSales(report, saleIDs); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *reportService) GetReport(id uuid.UUID) (*models.RegulatoryReport, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *reportService) ListReports(reporter string, page, limit int) (*utils.Pagination, error) {
+	return s.repo.FindPaginated(reporter, page, limit)
+}
+
+// OpenFile abre el archivo tal como se presentó.
+func (s *reportService) OpenFile(report *models.RegulatoryReport, format reporting.Format) (io.ReadCloser, error) {
+	key := report.CSVKey
+	if format == reporting.FormatFixedWidth {
+		key = report.FixedWidthKey
+	}
+	r, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el reporte: %w", err)
+	}
+	return r, nil
+}
+
+// prepare valida el periodo y arma el filtro y el encabezado del reporte.
+// Los días se interpretan en hora de Colombia.
+func (s *reportService) prepare(req *models.RegulatoryReportRequest, buyer *models.Buyer) (models.RegulatoryReportFilter, reporting.Header, error) {
+	var filter models.RegulatoryReportFilter
+	var header reporting.Header
+
+	from, errFrom := time.Parse("2006-01-02", req.From)
+	to, errTo := time.Parse("2006-01-02", req.To)
+	if errFrom != nil || errTo != nil || to.Before(from) || to.Sub(from) > maxReportDays*24*time.Hour {
+		return filter, header, ErrInvalidReportPeriod
+	}
+
+	filter = models.RegulatoryReportFilter{
+		Reporter:     models.PlatformReporter,
+		From:         time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, colombiaTime),
+		To:           time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, colombiaTime),
+		Municipality: req.Municipality,
+	}
+	header = reporting.Header{
+		ReporterNIT:  s.platformNIT,
+		ReporterName: platformName,
+		PeriodStart:  from,
+		PeriodEnd:    to,
+		GeneratedAt:  time.Now().In(colombiaTime),
+	}
+
+	if req.BuyerID != "" {
+		id, err := uuid.Parse(req.BuyerID)
+		if err != nil {
+			return filter, header, ErrInvalidReportBuyer
+		}
+		filter.BuyerID = &id
+	}
+	if buyer != nil {
+		if filter.BuyerID != nil && *filter.BuyerID != buyer.ID {
+			return filter, header, ErrReportOtherBuyer
+		}
+		filter.Reporter = models.BuyerReporter(buyer.ID)
+		filter.BuyerID = &buyer.ID
+		header.ReporterNIT = buyer.NIT
+		header.ReporterName = buyer.LegalName
+	}
+	return filter, header, nil
+}
+
+func toColombiaTime(records []reporting.Record) {
+	for i := range records {
+		records[i].SoldAt = records[i].SoldAt.In(colombiaTime)
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+var ErrLastAdminRole = errors.New("no puedes quitarte tu propio rol de administrador")
+
+// RoleService define la administración de roles de usuarios.
+type RoleService interface {
+	ListRoles() ([]models.Role, error)
+	AssignRole(userID uuid.UUID, role string) (*models.UserResponse, error)
+	RevokeRole(actorID, userID uuid.UUID, role string) (*models.UserResponse, error)
+}
+
+type roleService struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+}
+
+// NewRoleService crea una nueva instancia del servicio de roles.
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{roleRepo: roleRepo, userRepo: userRepo}
+}
+
+func (s *roleService) ListRoles() ([]models.Role, error) {
+	return s.roleRepo.FindAll()
+}
+
+func (s *roleService) AssignRole(userID uuid.UUID, role string) (*models.UserResponse, error) {
+	if _, err := s.userRepo.FindByID(userID.String()); err != nil {
+		return nil, err
+	}
+	if err := s.roleRepo.AssignToUser(userID, role); err != nil {
+		return nil, err
+	}
+	return s.userWithRoles(userID)
+}
+
+func (s *roleService) RevokeRole(actorID, userID uuid.UUID, role string) (*models.UserResponse, error) {
+	// Evita que un administrador se bloquee a sí mismo
+	if actorID == userID && role == models.RoleAdmin {
+		return nil, ErrLastAdminRole
+	}
+	if _, err := s.userRepo.FindByID(userID.String()); err != nil {
+		return nil, err
+	}
+	if err := s.roleRepo.RemoveFromUser(userID, role); err != nil {
+		return nil, err
+	}
+	return s.userWithRoles(userID)
+}
+
+func (s *roleService) userWithRoles(userID uuid.UUID) (*models.UserResponse, error) {
+	user, err := s.userRepo.FindByIDWithRoles(userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserResponse{
+		ID:          user.ID,
+		PhoneNumber: user.PhoneNumber,
+		IsVerified:  user.IsVerified,
+		Roles:       user.RoleNames(),
+		CreatedAt:   user.CreatedAt,
+	}, nil
+}
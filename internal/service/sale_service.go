@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/utils"
+)
+
+var (
+	ErrMinerNotApproved = errors.New("el minero no tiene su registro aprobado y no puede vender")
+	ErrSelfSale         = errors.New("un comercializador no puede registrar una compra a su propio registro de minero")
+)
+
+// SaleService registra compras de oro. Cada compra requiere el código TOTP
+// vigente del minero, que es quien autoriza la transacción.
+type SaleService interface {
+	CreateSale(buyerUserID uuid.UUID, req *models.CreateSaleRequest) (*models.Sale, error)
+	GetSale(id uuid.UUID) (*models.Sale, error)
+	ListMinerSales(minerID uuid.UUID, page, limit int) (*utils.Pagination, error)
+	GetQuota(miner *models.Miner) (*models.QuotaResponse, error)
+}
+
+type saleService struct {
+	repo         repository.SaleRepository
+	minerService MinerService
+	buyerService BuyerService
+	siteService  MiningSiteService
+	quota        *QuotaEngine
+	certificates CertificateService
+	prices       PriceService
+}
+
+func NewSaleService(repo repository.SaleRepository, minerService MinerService, buyerService BuyerService, siteService MiningSiteService, quota *QuotaEngine, certificates CertificateService, prices PriceService) SaleService {
+	return &saleService{repo: repo, minerService: minerService, buyerService: buyerService, siteService: siteService, quota: quota, certificates: certificates, prices: prices}
+}
+
+func (s *saleService) CreateSale(buyerUserID uuid.UUID, req *models.CreateSaleRequest) (*models.Sale, error) {
+	// Solo comercializadores aprobados, en uno de sus puntos de compra activos
+	buyer, point, err := s.buyerService.AuthorizePurchase(buyerUserID, req.PurchasePointID)
+	if err != nil {
+		return nil, err
+	}
+
+	miner, err := s.minerService.GetMinerByID(req.MinerID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.VerificationStatus != models.VerificationApproved {
+		return nil, ErrMinerNotApproved
+	}
+	if miner.UserID == buyerUserID {
+		return nil, ErrSelfSale
+	}
+
+	// Trazabilidad: el oro debe venir de un sitio registrado del minero
+	site, err := s.siteService.OriginSite(miner, req.OriginSiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	// El cupo se revisa antes de validar el código: una venta que el tope rechaza
+	// no debe gastar el código del minero ni sumar intentos al bloqueo
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	weight := roundGrams(req.WeightGrams)
+	monthStart, yearStart := s.quota.Periods(now)
+	usage, err := s.repo.Usage(miner.ID, monthStart, yearStart)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.quota.Check(miner, usage, weight); err != nil {
+		return nil, err
+	}
+
+	// Sin el código vigente del minero no hay venta
+	if _, err := s.minerService.ValidateTOTP(miner.ID, buyerUserID, req.TOTPCode); err != nil {
+		return nil, err
+	}
+
+	// ID y fecha se fijan aquí para que la entrada del libro mayor refleje la fila exacta
+	sale := &models.Sale{
+		ID:              uuid.New(),
+		CreatedAt:       now,
+		MinerID:         miner.ID,
+		BuyerUserID:     buyerUserID,
+		BuyerID:         &buyer.ID,
+		PurchasePointID: &point.ID,
+		OriginSiteID:    &site.ID,
+		WeightGrams:     weight,
+		Purity:          req.Purity,
+		FineGoldGrams:   roundGrams(req.WeightGrams * req.Purity),
+		PricePerGramCOP: req.PricePerGramCOP,
+		TotalCOP:        int64(math.Round(req.WeightGrams * float64(req.PricePerGramCOP))),
+		PointOfSale:     fmt.Sprintf("%s, %s, %s (%s)", point.Name, point.Address, point.Municipality, point.Department),
+	}
+
+	// La venta es inmutable: la comparación con el precio de referencia se fija al crearla
+	if err := s.prices.AssessSale(sale); err != nil {
+		return nil, fmt.Errorf("fallo al consultar el precio de referencia: %w", err)
+	}
+
+	entry, err := newLedgerEntry(models.LedgerSaleCreated, models.AggregateSale, sale.ID, sale)
+	if err != nil {
+		return nil, err
+	}
+
+	// El tope se verifica de nuevo dentro de la misma transacción que inserta la
+	// venta: otra venta simultánea pudo consumir el cupo entre tanto
+	err = s.repo.CreateWithinQuota(sale, monthStart, yearStart, func(usage models.SaleUsage) error {
+		return s.quota.Check(miner, usage, sale.WeightGrams)
+	}, entry, saleJournal(sale))
+	if err != nil {
+		if errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrProductionNotDeclared) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("fallo al registrar la venta: %w", err)
+	}
+
+	// La venta ya es firme; si el certificado falla se emite al descargarlo
+	sale.Miner = miner
+	if _, err := s.certificates.Issue(sale); err != nil {
+		log.Printf("No se pudo emitir el certificado de origen de la venta %s: %v", sale.ID, err)
+	}
+	return sale, nil
+}
+
+func (s *saleService) GetSale(id uuid.UUID) (*models.Sale, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *saleService) ListMinerSales(minerID uuid.UUID, page, limit int) (*utils.Pagination, error) {
+	return s.repo.FindByMinerPaginated(minerID, page, limit)
+}
+
+// GetQuota devuelve el cupo usado y disponible del minero en el periodo actual.
+func (s *saleService) GetQuota(miner *models.Miner) (*models.QuotaResponse, error) {
+	now := time.Now()
+	monthStart, yearStart := s.quota.Periods(now)
+	usage, err := s.repo.Usage(miner.ID, monthStart, yearStart)
+	if err != nil {
+		return nil, err
+	}
+	return s.quota.Report(miner, usage, now)
+}
+
+// roundGrams redondea a miligramos, la precisión con la que se guarda el peso.
+func roundGrams(g float64) float64 {
+	return math.Round(g*1000) / 1000
+}
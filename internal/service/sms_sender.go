@@ -0,0 +1,52 @@
+package service
+
+import (
+	"log"
+	"sync"
+)
+
+// SMSSender envía mensajes de texto. Permite cambiar de proveedor (Twilio, AWS SNS, etc.)
+// sin tocar la lógica de verificación.
+type SMSSender interface {
+	Send(phoneNumber, message string) error
+}
+
+// LogSMSSender escribe los mensajes en el log. Solo para desarrollo local.
+type LogSMSSender struct{}
+
+func NewLogSMSSender() *LogSMSSender {
+	return &LogSMSSender{}
+}
+
+func (LogSMSSender) Send(phoneNumber, message string) error {
+	log.Printf("[SMS] Para %s: %s", phoneNumber, message)
+	return nil
+}
+
+// InMemorySMSSender guarda los mensajes enviados en memoria para poder leerlos en pruebas.
+type InMemorySMSSender struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+func NewInMemorySMSSender() *InMemorySMSSender {
+	return &InMemorySMSSender{messages: map[string][]string{}}
+}
+
+func (s *InMemorySMSSender) Send(phoneNumber, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[phoneNumber] = append(s.messages[phoneNumber], message)
+	return nil
+}
+
+// LastMessage devuelve el último mensaje enviado al número indicado.
+func (s *InMemorySMSSender) LastMessage(phoneNumber string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msgs := s.messages[phoneNumber]
+	if len(msgs) == 0 {
+		return "", false
+	}
+	return msgs[len(msgs)-1], true
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/storage"
+)
+
+// Prefijos de los documentos que administra el backend; lo demás del bucket no se toca.
+var documentPrefixes = []string{"miners/", "buyers/"}
+
+// sweepBatchSize limita cuántas llaves se consultan por cada query a la base de datos.
+const sweepBatchSize = 500
+
+// StorageSweeper elimina periódicamente los archivos que ninguna fila de la base
+// de datos referencia, por ejemplo los de un registro que falló a mitad de camino
+// o los que dejó un proceso que se cayó entre la subida y el commit.
+type StorageSweeper struct {
+	store   storage.Storage
+	docRepo repository.DocumentRepository
+	grace   time.Duration
+}
+
+// NewStorageSweeper crea el barrido. grace protege los archivos recién subidos
+// cuyo registro todavía puede estar en una transacción abierta.
+func NewStorageSweeper(store storage.Storage, docRepo repository.DocumentRepository, grace time.Duration) *StorageSweeper {
+	return &StorageSweeper{store: store, docRepo: docRepo, grace: grace}
+}
+
+// Start ejecuta Sweep cada interval hasta que se cancele ctx.
+func (s *StorageSweeper) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if deleted, err := s.Sweep(ctx); err != nil {
+					log.Printf("Error en el barrido de archivos huérfanos: %v", err)
+				} else if deleted > 0 {
+					log.Printf("Barrido de almacenamiento: %d archivos huérfanos eliminados", deleted)
+				}
+			}
+		}
+	}()
+}
+
+// Sweep elimina los archivos sin referencia más antiguos que el periodo de gracia
+// y devuelve cuántos se borraron. Las llaves se consultan en lotes a medida que
+// se listan, así que la memoria no crece con el tamaño del bucket.
+func (s *StorageSweeper) Sweep(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.grace)
+	deleted := 0
+	batch := make([]string, 0, sweepBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := s.sweepBatch(ctx, batch)
+		deleted += n
+		batch = batch[:0]
+		return err
+	}
+
+	for _, prefix := range documentPrefixes {
+		err := s.store.List(ctx, prefix, func(obj storage.ObjectInfo) error {
+			if !obj.Updated.Before(cutoff) {
+				return nil
+			}
+			batch = append(batch, obj.Key)
+			if len(batch) < sweepBatchSize {
+				return nil
+			}
+			return flush()
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if err := flush(); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// sweepBatch borra las llaves del lote que ninguna fila referencia.
+func (s *StorageSweeper) sweepBatch(ctx context.Context, batch []string) (int, error) {
+	referenced, err := s.docRepo.ReferencedKeys(batch)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, key := range batch {
+		if referenced[key] {
+			continue
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("No se pudo eliminar el archivo huérfano %s: %v", key, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/storage"
+)
+
+// memoryStore es un Storage en memoria que solo implementa List y Delete.
+type memoryStore struct {
+	storage.Storage
+	objects map[string]time.Time
+	deleted []string
+}
+
+func (m *memoryStore) List(ctx context.Context, prefix string, fn func(storage.ObjectInfo) error) error {
+	for key, updated := range m.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := fn(storage.ObjectInfo{Key: key, Updated: updated}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	delete(m.objects, key)
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+// referencedDocs responde ReferencedKeys con un conjunto fijo y registra el
+// tamaño de cada lote consultado.
+type referencedDocs struct {
+	repository.DocumentRepository
+	keys    map[string]bool
+	batches []int
+	err     error
+}
+
+func (r *referencedDocs) ReferencedKeys(keys []string) (map[string]bool, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.batches = append(r.batches, len(keys))
+	referenced := make(map[string]bool)
+	for _, key := range keys {
+		if r.keys[key] {
+			referenced[key] = true
+		}
+	}
+	return referenced, nil
+}
+
+func TestStorageSweeperSweep(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	store := &memoryStore{objects: map[string]time.Time{
+		"miners/a/rut/reciente.pdf": time.Now(),
+		"otros/huerfano.txt":        old,
+	}}
+	docs := &referencedDocs{keys: map[string]bool{}}
+
+	// Más llaves viejas que un lote, con una de cada diez referenciada
+	total := sweepBatchSize*2 + 7
+	for i := range total {
+		prefix := "miners/"
+		if i%2 == 1 {
+			prefix = "buyers/"
+		}
+		key := fmt.Sprintf("%sdoc/%d.pdf", prefix, i)
+		store.objects[key] = old
+		if i%10 == 0 {
+			docs.keys[key] = true
+		}
+	}
+
+	sweeper := NewStorageSweeper(store, docs, time.Hour)
+	deleted, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+
+	wantDeleted := total - len(docs.keys)
+	if deleted != wantDeleted || len(store.deleted) != wantDeleted {
+		t.Fatalf("se borraron %d (%d llamadas), se esperaban %d", deleted, len(store.deleted), wantDeleted)
+	}
+	for key := range docs.keys {
+		if _, ok := store.objects[key]; !ok {
+			t.Errorf("se borró %s, que está referenciado", key)
+		}
+	}
+	for _, key := range []string{"miners/a/rut/reciente.pdf", "otros/huerfano.txt"} {
+		if _, ok := store.objects[key]; !ok {
+			t.Errorf("se borró %s, que debía conservarse", key)
+		}
+	}
+
+	checked := 0
+	for _, n := range docs.batches {
+		if n > sweepBatchSize {
+			t.Errorf("lote de %d llaves, el máximo es %d", n, sweepBatchSize)
+		}
+		checked += n
+	}
+	if checked != total {
+		t.Errorf("se consultaron %d llaves, se esperaban %d", checked, total)
+	}
+}
+
+func TestStorageSweeperStopsOnRepositoryError(t *testing.T) {
+	store := &memoryStore{objects: map[string]time.Time{
+		"miners/a/rut/viejo.pdf": time.Now().Add(-48 * time.Hour),
+	}}
+	docs := &referencedDocs{err: errors.New("sin conexión")}
+
+	sweeper := NewStorageSweeper(store, docs, time.Hour)
+	if _, err := sweeper.Sweep(context.Background()); !errors.Is(err, docs.err) {
+		t.Fatalf("Sweep = %v, se esperaba el error del repositorio", err)
+	}
+	if len(store.deleted) != 0 {
+		t.Fatalf("se borraron %v sin poder consultar las referencias", store.deleted)
+	}
+}
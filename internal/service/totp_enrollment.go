@@ -0,0 +1,189 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+// recoveryAlphabet omite I, O, 0 y 1 para que los códigos se puedan dictar.
+// Tiene 32 símbolos, así que cada byte aleatorio se reduce sin sesgo.
+const recoveryAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// recoveryCodeLength es la cantidad de símbolos de cada código (50 bits).
+const recoveryCodeLength = 10
+
+var (
+	ErrTOTPNoPendingEnrollment = errors.New("el minero no tiene un registro de TOTP pendiente de confirmar")
+	ErrRecoveryCodeInvalid     = errors.New("código de recuperación inválido o ya usado")
+	ErrIdentityNotVerified     = errors.New("debe confirmar su identidad con un código SMS o de recuperación")
+)
+
+// issueTOTPKey genera un secreto TOTP nuevo para el minero y lo devuelve
+// también cifrado, listo para guardarse como secreto pendiente.
+func (s *minerService) issueTOTPKey(miner *models.Miner) (*otp.Key, string, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      "Batea Fintech",
+		AccountName: miner.Email,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error generando TOTP: %w", err)
+	}
+	sealed, err := sealTOTPSecret(s.totpKeys, miner.ID, key.Secret())
+	if err != nil {
+		return nil, "", err
+	}
+	return key, sealed, nil
+}
+
+func totpEnrollment(miner *models.Miner, key *otp.Key) *models.MinerTOTPResponse {
+	return &models.MinerTOTPResponse{
+		ID:         miner.ID,
+		FullName:   miner.FullName,
+		Email:      miner.Email,
+		TOTPSecret: key.Secret(),
+		QRCodeURL:  key.URL(),
+	}
+}
+
+// ConfirmTOTP activa el secreto pendiente con el primer código que genera la
+// aplicación del minero y devuelve sus códigos de recuperación. Es la única
+// vez que se pueden leer en claro; los anteriores dejan de servir.
+func (s *minerService) ConfirmTOTP(minerID, userID uuid.UUID, code string) ([]string, error) {
+	miner, err := s.ownedMiner(minerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if miner.TOTPPendingSecret == "" {
+		return nil, ErrTOTPNoPendingEnrollment
+	}
+	secret, err := openTOTPSecret(s.totpKeys, miner.ID, miner.TOTPPendingSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	step, matched, err := matchTOTPStep(secret, code, now)
+	if err != nil {
+		return nil, fmt.Errorf("error al validar código TOTP: %w", err)
+	}
+	// El código de confirmación queda como último paso aceptado: no sirve después para una venta
+	if err := s.guardAttempt(miner.ID, userID, now, acceptTOTPStep(step, matched)); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(s.recoveryKey(), models.TOTPRecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ActivateTOTP(miner.ID, miner.TOTPPendingSecret, now, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ReenrollTOTP emite un secreto nuevo, por ejemplo tras perder el teléfono. La
+// identidad se prueba con el código SMS vigente o con un código de
+// recuperación; el secreto anterior se revoca de inmediato y el nuevo queda
+// pendiente hasta ConfirmTOTP.
+func (s *minerService) ReenrollTOTP(minerID, userID uuid.UUID, req *models.ReenrollTOTPRequest) (*models.MinerTOTPResponse, error) {
+	miner, err := s.ownedMiner(minerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.RecoveryCode != "":
+		if err := s.useRecoveryCode(miner, userID, req.RecoveryCode); err != nil {
+			return nil, err
+		}
+	case req.PhoneCode != "" && s.phoneVerifier != nil:
+		if err := s.phoneVerifier.ConfirmIdentity(userID, req.PhoneCode); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrIdentityNotVerified
+	}
+
+	key, sealed, err := s.issueTOTPKey(miner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.StartTOTPEnrollment(miner.ID, sealed); err != nil {
+		return nil, err
+	}
+	return totpEnrollment(miner, key), nil
+}
+
+// useRecoveryCode consume un código de recuperación del minero. Los códigos
+// errados cuentan para el mismo bloqueo de userID que los códigos TOTP.
+func (s *minerService) useRecoveryCode(miner *models.Miner, userID uuid.UUID, code string) error {
+	now := time.Now()
+	hash := hashRecoveryCode(s.recoveryKey(), code)
+	return s.guardAttempt(miner.ID, userID, now, func(*models.TOTPGuard) error {
+		ok, err := s.repo.ConsumeRecoveryCode(miner.ID, hash, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrRecoveryCodeInvalid
+		}
+		return nil
+	})
+}
+
+// recoveryKey es la llave del servidor con la que se calculan los hashes de los
+// códigos de recuperación.
+func (s *minerService) recoveryKey() []byte {
+	return []byte(s.cfg.TOTPRecoverySecret)
+}
+
+// generateRecoveryCodes devuelve n códigos con formato XXXXX-XXXXX y sus hashes.
+func generateRecoveryCodes(key []byte, n int) (codes, hashes []string, err error) {
+	buf := make([]byte, recoveryCodeLength)
+	for range n {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("error generando códigos de recuperación: %w", err)
+		}
+		var b strings.Builder
+		for i, v := range buf {
+			if i == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryAlphabet[int(v)%len(recoveryAlphabet)])
+		}
+		code := b.String()
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(key, code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode calcula el HMAC-SHA256 del código con la llave del servidor:
+// los códigos tienen solo 50 bits, así que con un SHA-256 simple un volcado de
+// la base de datos bastaría para recuperarlos por fuerza bruta. Ignora
+// mayúsculas, guiones y espacios, para aceptar el código tal como el minero lo transcriba.
+func hashRecoveryCode(key []byte, code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"testing"
+)
+
+func TestHashRecoveryCode(t *testing.T) {
+	key := []byte("llave-del-servidor")
+	want := hashRecoveryCode(key, "ABCDE-FGH23")
+
+	// El minero puede transcribir el código con minúsculas, sin guion o con espacios
+	for _, typed := range []string{"abcde-fgh23", "ABCDEFGH23", " abcde fgh23 ", "AbCdE - FgH23"} {
+		if got := hashRecoveryCode(key, typed); got != want {
+			t.Errorf("hashRecoveryCode(%q) = %s, se esperaba %s", typed, got, want)
+		}
+	}
+
+	if hashRecoveryCode([]byte("otra-llave"), "ABCDE-FGH23") == want {
+		t.Fatal("el hash no depende de la llave del servidor")
+	}
+	plain := sha256.Sum256([]byte("ABCDEFGH23"))
+	if want == hex.EncodeToString(plain[:]) {
+		t.Fatal("el hash es un SHA-256 sin llave")
+	}
+	if hashRecoveryCode(key, "ABCDE-FGH24") == want {
+		t.Fatal("dos códigos distintos tienen el mismo hash")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	key := []byte("llave-del-servidor")
+	codes, hashes, err := generateRecoveryCodes(key, 10)
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("se generaron %d códigos y %d hashes, se esperaban 10", len(codes), len(hashes))
+	}
+
+	format := regexp.MustCompile(`^[` + recoveryAlphabet + `]{5}-[` + recoveryAlphabet + `]{5}$`)
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("código %q con formato inválido", code)
+		}
+		if seen[code] {
+			t.Errorf("código %q repetido", code)
+		}
+		seen[code] = true
+		if hashes[i] != hashRecoveryCode(key, code) || len(hashes[i]) != 64 {
+			t.Errorf("hash %d = %s, no corresponde al código %s", i, hashes[i], code)
+		}
+	}
+}
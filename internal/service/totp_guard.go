@@ -0,0 +1,146 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+// totpPeriod es la duración de cada código, en segundos.
+const totpPeriod = 30
+
+var (
+	ErrTOTPReused = errors.New("el código ya fue usado; espere el siguiente código de su aplicación")
+	ErrTOTPLocked = errors.New("demasiados códigos inválidos: el TOTP está bloqueado temporalmente")
+)
+
+// TOTPLockedError indica hasta cuándo está bloqueado el TOTP del minero.
+// errors.Is(err, ErrTOTPLocked) también lo reconoce.
+type TOTPLockedError struct {
+	Until time.Time
+}
+
+func (e *TOTPLockedError) Error() string {
+	return fmt.Sprintf("%s hasta las %s", ErrTOTPLocked, e.Until.In(colombiaTime).Format("15:04:05"))
+}
+
+func (e *TOTPLockedError) Is(target error) bool {
+	return target == ErrTOTPLocked
+}
+
+// RetryAfter es el tiempo que falta para que termine el bloqueo.
+func (e *TOTPLockedError) RetryAfter() time.Duration {
+	return max(time.Until(e.Until), 0)
+}
+
+// matchTOTPStep busca el paso de tiempo cuyo código coincide con code,
+// admitiendo un paso de desfase hacia cada lado.
+func matchTOTPStep(secret, code string, now time.Time) (int64, bool, error) {
+	current := now.Unix() / totpPeriod
+	for step := current - 1; step <= current+1; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// acceptTOTPStep devuelve el chequeo de un código TOTP para guardAttempt:
+// solo se acepta si su paso es posterior al último aceptado.
+func acceptTOTPStep(step int64, matched bool) func(guard *models.TOTPGuard) error {
+	return func(guard *models.TOTPGuard) error {
+		if !matched {
+			return ErrInvalidTOTP
+		}
+		if guard.LastStep != nil && step <= *guard.LastStep {
+			return ErrTOTPReused
+		}
+		guard.LastStep = &step
+		return nil
+	}
+}
+
+// guardAttempt serializa un intento de segundo factor del minero (código TOTP
+// o de recuperación) enviado por callerID y aplica check. Cada fallo de check
+// (código errado o repetido) cuenta en la ventana deslizante de callerID y
+// puede bloquearle el TOTP; con el TOTP bloqueado se rechaza cualquier intento
+// de ese usuario sin evaluarlo, incluso uno correcto. Los demás usuarios, entre
+// ellos el propio minero, siguen pudiendo usar el código.
+func (s *minerService) guardAttempt(minerID, callerID uuid.UUID, now time.Time, check func(guard *models.TOTPGuard) error) error {
+	var result error
+	err := s.repo.UpdateTOTPGuard(minerID, func(guard *models.TOTPGuard) error {
+		pruneTOTPCallers(guard, now.Add(-s.cfg.TOTPFailureWindow), now)
+		attempts := guard.Callers[callerID]
+		if attempts != nil && attempts.LockedUntil != nil && now.Before(*attempts.LockedUntil) {
+			return &TOTPLockedError{Until: *attempts.LockedUntil}
+		}
+
+		result = check(guard)
+		switch {
+		case result == nil:
+			delete(guard.Callers, callerID)
+			return nil
+		case !errors.Is(result, ErrInvalidTOTP) && !errors.Is(result, ErrTOTPReused) && !errors.Is(result, ErrRecoveryCodeInvalid):
+			// Un error que no es del código no cuenta como intento
+			return result
+		}
+
+		// El fallo se guarda aunque la validación no pase
+		if attempts == nil {
+			attempts = &models.TOTPCallerAttempts{}
+		}
+		attempts.Failures = append(attempts.Failures, now)
+		attempts.LockedUntil = nil
+		if s.cfg.TOTPMaxFailures > 0 && len(attempts.Failures) >= s.cfg.TOTPMaxFailures {
+			until := now.Add(s.cfg.TOTPLockout)
+			attempts.LockedUntil = &until
+			attempts.Failures = nil
+			result = &TOTPLockedError{Until: until}
+		}
+		if guard.Callers == nil {
+			guard.Callers = make(map[uuid.UUID]*models.TOTPCallerAttempts)
+		}
+		guard.Callers[callerID] = attempts
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+// pruneTOTPCallers descarta los fallos anteriores a windowStart y los usuarios
+// sin fallos recientes ni bloqueo vigente, para que el estado no crezca con
+// cada comprador que alguna vez envió un código.
+func pruneTOTPCallers(guard *models.TOTPGuard, windowStart, now time.Time) {
+	for id, attempts := range guard.Callers {
+		recent := attempts.Failures[:0]
+		for _, t := range attempts.Failures {
+			if t.After(windowStart) {
+				recent = append(recent, t)
+			}
+		}
+		attempts.Failures = recent
+		if attempts.LockedUntil != nil && !now.Before(*attempts.LockedUntil) {
+			attempts.LockedUntil = nil
+		}
+		if len(attempts.Failures) == 0 && attempts.LockedUntil == nil {
+			delete(guard.Callers, id)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// guardRepo es un MinerRepository que solo guarda el estado TOTP en memoria;
+// como el repositorio real, descarta los cambios si fn devuelve error.
+type guardRepo struct {
+	repository.MinerRepository
+	guard models.TOTPGuard
+}
+
+func (r *guardRepo) UpdateTOTPGuard(minerID uuid.UUID, fn func(guard *models.TOTPGuard) error) error {
+	// Copia profunda, como si se leyera de nuevo de la base de datos
+	raw, _ := json.Marshal(r.guard)
+	var guard models.TOTPGuard
+	if err := json.Unmarshal(raw, &guard); err != nil {
+		return err
+	}
+	if err := fn(&guard); err != nil {
+		return err
+	}
+	r.guard = guard
+	return nil
+}
+
+func newGuardedMinerService() (*minerService, *guardRepo) {
+	repo := &guardRepo{}
+	return &minerService{repo: repo, cfg: &config.Config{
+		TOTPMaxFailures:   3,
+		TOTPFailureWindow: 15 * time.Minute,
+		TOTPLockout:       10 * time.Minute,
+	}}, repo
+}
+
+func totpCodeAt(t *testing.T, at time.Time) string {
+	t.Helper()
+	code, err := totp.GenerateCodeCustom(testTOTPSecret, at, totp.ValidateOpts{
+		Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		t.Fatalf("GenerateCodeCustom: %v", err)
+	}
+	return code
+}
+
+func TestMatchTOTPStep(t *testing.T) {
+	now := time.Date(2026, time.June, 15, 12, 0, 10, 0, time.UTC)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name      string
+		code      string
+		wantStep  int64
+		wantMatch bool
+	}{
+		{name: "código vigente", code: totpCodeAt(t, now), wantStep: current, wantMatch: true},
+		{name: "un paso atrás", code: totpCodeAt(t, now.Add(-totpPeriod*time.Second)), wantStep: current - 1, wantMatch: true},
+		{name: "un paso adelante", code: totpCodeAt(t, now.Add(totpPeriod*time.Second)), wantStep: current + 1, wantMatch: true},
+		{name: "dos pasos atrás", code: totpCodeAt(t, now.Add(-2*totpPeriod*time.Second))},
+		{name: "código errado", code: "000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := matchTOTPStep(testTOTPSecret, tt.code, now)
+			if err != nil {
+				t.Fatalf("matchTOTPStep: %v", err)
+			}
+			if ok != tt.wantMatch || (ok && step != tt.wantStep) {
+				t.Fatalf("matchTOTPStep = %d, %v; se esperaba %d, %v", step, ok, tt.wantStep, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestGuardAttemptLockout(t *testing.T) {
+	start := time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+	minerID, callerID := uuid.New(), uuid.New()
+
+	type attempt struct {
+		after   time.Duration // desde start
+		matched bool
+		step    int64
+		wantErr error
+	}
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "bloquea al tercer fallo dentro de la ventana",
+			attempts: []attempt{
+				{after: 0, wantErr: ErrInvalidTOTP},
+				{after: time.Minute, wantErr: ErrInvalidTOTP},
+				{after: 2 * time.Minute, wantErr: ErrTOTPLocked},
+				// Bloqueado: ni un código correcto pasa
+				{after: 5 * time.Minute, matched: true, step: 100, wantErr: ErrTOTPLocked},
+				// Al vencer el bloqueo se vuelve a evaluar
+				{after: 12 * time.Minute, matched: true, step: 100},
+			},
+		},
+		{
+			name: "los fallos fuera de la ventana no cuentan",
+			attempts: []attempt{
+				{after: 0, wantErr: ErrInvalidTOTP},
+				{after: time.Minute, wantErr: ErrInvalidTOTP},
+				// A los 16 minutos ya salieron de la ventana los dos primeros fallos
+				{after: 16 * time.Minute, wantErr: ErrInvalidTOTP},
+				{after: 17 * time.Minute, wantErr: ErrInvalidTOTP},
+				{after: 18 * time.Minute, wantErr: ErrTOTPLocked},
+			},
+		},
+		{
+			name: "un acierto reinicia los fallos",
+			attempts: []attempt{
+				{after: 0, wantErr: ErrInvalidTOTP},
+				{after: time.Minute, wantErr: ErrInvalidTOTP},
+				{after: 2 * time.Minute, matched: true, step: 100},
+				{after: 3 * time.Minute, wantErr: ErrInvalidTOTP},
+				{after: 4 * time.Minute, wantErr: ErrInvalidTOTP},
+			},
+		},
+		{
+			name: "un código repetido cuenta como fallo",
+			attempts: []attempt{
+				{after: 0, matched: true, step: 100},
+				{after: 10 * time.Second, matched: true, step: 100, wantErr: ErrTOTPReused},
+				{after: 20 * time.Second, matched: true, step: 99, wantErr: ErrTOTPReused},
+				{after: 30 * time.Second, matched: true, step: 100, wantErr: ErrTOTPLocked},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newGuardedMinerService()
+			for i, a := range tt.attempts {
+				err := s.guardAttempt(minerID, callerID, start.Add(a.after), acceptTOTPStep(a.step, a.matched))
+				if !errors.Is(err, a.wantErr) {
+					t.Fatalf("intento %d: guardAttempt = %v, se esperaba %v", i+1, err, a.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestGuardAttemptState(t *testing.T) {
+	start := time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+	minerID, callerID := uuid.New(), uuid.New()
+	s, repo := newGuardedMinerService()
+
+	s.guardAttempt(minerID, callerID, start, acceptTOTPStep(0, false))
+	s.guardAttempt(minerID, callerID, start.Add(time.Minute), acceptTOTPStep(0, false))
+	err := s.guardAttempt(minerID, callerID, start.Add(2*time.Minute), acceptTOTPStep(0, false))
+
+	var locked *TOTPLockedError
+	wantUntil := start.Add(12 * time.Minute)
+	if !errors.As(err, &locked) || !locked.Until.Equal(wantUntil) {
+		t.Fatalf("guardAttempt = %v, se esperaba bloqueo hasta %s", err, wantUntil)
+	}
+	attempts := repo.guard.Callers[callerID]
+	if attempts == nil || attempts.LockedUntil == nil || !attempts.LockedUntil.Equal(wantUntil) || len(attempts.Failures) != 0 {
+		t.Fatalf("estado guardado = %+v, se esperaba el bloqueo sin fallos pendientes", attempts)
+	}
+
+	// Un error ajeno al código no cuenta como intento ni cambia el estado
+	errOther := errors.New("secreto ilegible")
+	after := start.Add(13 * time.Minute)
+	if err := s.guardAttempt(minerID, callerID, after, func(*models.TOTPGuard) error { return errOther }); !errors.Is(err, errOther) {
+		t.Fatalf("guardAttempt = %v, se esperaba %v", err, errOther)
+	}
+	if attempts := repo.guard.Callers[callerID]; attempts == nil || !attempts.LockedUntil.Equal(wantUntil) {
+		t.Fatalf("estado guardado = %+v, se esperaba sin cambios", attempts)
+	}
+
+	// El acierto tras el bloqueo borra el estado del usuario
+	if err := s.guardAttempt(minerID, callerID, after, acceptTOTPStep(42, true)); err != nil {
+		t.Fatalf("guardAttempt: %v", err)
+	}
+	if repo.guard.LastStep == nil || *repo.guard.LastStep != 42 || len(repo.guard.Callers) != 0 {
+		t.Fatalf("estado guardado = %+v, se esperaba paso 42 sin usuarios bloqueados", repo.guard)
+	}
+}
+
+func TestGuardAttemptLockoutIsPerCaller(t *testing.T) {
+	start := time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+	minerID, buyerUserID, minerUserID := uuid.New(), uuid.New(), uuid.New()
+	s, repo := newGuardedMinerService()
+
+	// Un comprador agota sus intentos con el minero
+	for i := range s.cfg.TOTPMaxFailures {
+		s.guardAttempt(minerID, buyerUserID, start.Add(time.Duration(i)*time.Second), acceptTOTPStep(0, false))
+	}
+	now := start.Add(time.Minute)
+	if err := s.guardAttempt(minerID, buyerUserID, now, acceptTOTPStep(100, true)); !errors.Is(err, ErrTOTPLocked) {
+		t.Fatalf("comprador: guardAttempt = %v, se esperaba ErrTOTPLocked", err)
+	}
+
+	// El minero sigue pudiendo retirar con su código y usar sus códigos de recuperación
+	if err := s.guardAttempt(minerID, minerUserID, now, acceptTOTPStep(100, true)); err != nil {
+		t.Fatalf("retiro del minero: guardAttempt = %v", err)
+	}
+	if err := s.guardAttempt(minerID, minerUserID, now, func(*models.TOTPGuard) error { return nil }); err != nil {
+		t.Fatalf("recuperación del minero: guardAttempt = %v", err)
+	}
+	// y sus propios fallos se cuentan aparte de los del comprador
+	if err := s.guardAttempt(minerID, minerUserID, now, acceptTOTPStep(0, false)); !errors.Is(err, ErrInvalidTOTP) {
+		t.Fatalf("minero: guardAttempt = %v, se esperaba ErrInvalidTOTP", err)
+	}
+
+	// El código que aceptó el minero no le sirve al comprador tras el bloqueo: sigue valiendo el paso por minero
+	after := start.Add(20 * time.Minute)
+	if err := s.guardAttempt(minerID, buyerUserID, after, acceptTOTPStep(100, true)); !errors.Is(err, ErrTOTPReused) {
+		t.Fatalf("comprador: guardAttempt = %v, se esperaba ErrTOTPReused", err)
+	}
+	// Los usuarios sin fallos recientes ni bloqueo vigente se descartan
+	if err := s.guardAttempt(minerID, buyerUserID, start.Add(time.Hour), acceptTOTPStep(101, true)); err != nil {
+		t.Fatalf("comprador: guardAttempt = %v", err)
+	}
+	if len(repo.guard.Callers) != 0 {
+		t.Fatalf("Callers = %+v, se esperaba vacío", repo.guard.Callers)
+	}
+}
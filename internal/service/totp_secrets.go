@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+	"github.com/sanchezta/batea-backend/internal/secrets"
+)
+
+// rekeyBatchSize es cuántos mineros se leen por consulta al volver a cifrar.
+const rekeyBatchSize = 200
+
+// sealTOTPSecret cifra el secreto TOTP del minero. El ID del minero va como
+// dato autenticado: el valor cifrado no sirve si se copia a otra fila.
+func sealTOTPSecret(keys *secrets.Envelope, minerID uuid.UUID, secret string) (string, error) {
+	sealed, err := keys.Seal([]byte(secret), minerID[:])
+	if err != nil {
+		return "", fmt.Errorf("error al cifrar el secreto TOTP: %w", err)
+	}
+	return sealed, nil
+}
+
+// openTOTPSecret descifra el secreto guardado. Los registros anteriores al
+// cifrado siguen en claro hasta que se ejecute totprekey.
+func openTOTPSecret(keys *secrets.Envelope, minerID uuid.UUID, stored string) (string, error) {
+	if !secrets.IsSealed(stored) {
+		return stored, nil
+	}
+	secret, err := keys.Open(stored, minerID[:])
+	if err != nil {
+		return "", fmt.Errorf("error al descifrar el secreto TOTP: %w", err)
+	}
+	return string(secret), nil
+}
+
+func (s *minerService) totpSecret(miner *models.Miner) (string, error) {
+	if miner.TOTPSecret == "" {
+		return "", ErrTOTPNotConfigured
+	}
+	return openTOTPSecret(s.totpKeys, miner.ID, miner.TOTPSecret)
+}
+
+// TOTPRekeyResult resume una pasada de TOTPRekeyer.
+type TOTPRekeyResult struct {
+	Scanned int // mineros revisados, incluidos los eliminados
+	Rekeyed int // mineros cuyos secretos se cifraron de nuevo con la llave vigente
+	Failed  int // mineros con un secreto que no se pudo abrir o que cambió durante la pasada
+}
+
+// TOTPRekeyer vuelve a cifrar con la llave maestra vigente los secretos TOTP,
+// vigentes o pendientes de confirmar, que están en claro o cifrados con una
+// llave anterior.
+type TOTPRekeyer struct {
+	repo repository.MinerRepository
+	keys *secrets.Envelope
+}
+
+func NewTOTPRekeyer(repo repository.MinerRepository, keys *secrets.Envelope) *TOTPRekeyer {
+	return &TOTPRekeyer{repo: repo, keys: keys}
+}
+
+// Run recorre todos los mineros por lotes. Un secreto que no se puede abrir
+// se cuenta como fallido y no detiene la pasada.
+func (r *TOTPRekeyer) Run() (TOTPRekeyResult, error) {
+	var result TOTPRekeyResult
+	after := uuid.Nil
+	for {
+		batch, err := r.repo.FindTOTPSecrets(after, rekeyBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		for i := range batch {
+			miner := &batch[i]
+			after = miner.ID
+			result.Scanned++
+
+			changed, err := r.rekeyMiner(miner)
+			var openErr *rekeyOpenError
+			switch {
+			case errors.As(err, &openErr):
+				log.Printf("Minero %s: %v", miner.ID, err)
+				result.Failed++
+			case errors.Is(err, repository.ErrTOTPSecretChanged):
+				log.Printf("Minero %s: el secreto cambió durante la pasada; vuelva a ejecutar", miner.ID)
+				result.Failed++
+			case err != nil:
+				return result, err
+			case changed:
+				result.Rekeyed++
+			}
+		}
+	}
+}
+
+// rekeyMiner cifra de nuevo los dos secretos del minero y los guarda juntos.
+func (r *TOTPRekeyer) rekeyMiner(miner *models.Miner) (bool, error) {
+	active, activeChanged, err := r.rekey(miner.ID, miner.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+	pending, pendingChanged, err := r.rekey(miner.ID, miner.TOTPPendingSecret)
+	if err != nil {
+		return false, err
+	}
+	if !activeChanged && !pendingChanged {
+		return false, nil
+	}
+	return true, r.repo.UpdateTOTPSecrets(miner, active, pending)
+}
+
+// rekeyOpenError marca un secreto guardado que no se pudo abrir: cuenta como
+// fallido en vez de interrumpir la pasada.
+type rekeyOpenError struct {
+	err error
+}
+
+func (e *rekeyOpenError) Error() string { return e.err.Error() }
+
+// rekey devuelve stored cifrado con la llave vigente; changed es false si ya
+// lo estaba o si está vacío.
+func (r *TOTPRekeyer) rekey(minerID uuid.UUID, stored string) (sealed string, changed bool, err error) {
+	if stored == "" {
+		return stored, false, nil
+	}
+	stale, err := r.keys.NeedsRekey(stored)
+	if err != nil || !stale {
+		return stored, false, err
+	}
+	secret, err := openTOTPSecret(r.keys, minerID, stored)
+	if err != nil {
+		return "", false, &rekeyOpenError{err: err}
+	}
+	sealed, err = sealTOTPSecret(r.keys, minerID, secret)
+	if err != nil {
+		return "", false, err
+	}
+	return sealed, true, nil
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/sanchezta/batea-backend/internal/identity"
 	"github.com/sanchezta/batea-backend/internal/models"
 	"github.com/sanchezta/batea-backend/internal/repository"
 	"github.com/sanchezta/batea-backend/internal/utils"
@@ -11,6 +13,9 @@ import (
 var (
 	ErrUserAlreadyExists = errors.New("ya existe un usuario con este número de teléfono")
 	ErrPasswordHash      = errors.New("error al encriptar la contraseña")
+	ErrMissingCredential = errors.New("debe enviar una contraseña o un id_token")
+	ErrIdentityDisabled  = errors.New("el inicio de sesión con Identity Platform no está configurado")
+	ErrPhoneMismatch     = errors.New("el número de teléfono no coincide con el del ID token")
 )
 
 type UserService interface {
@@ -19,10 +24,12 @@ type UserService interface {
 
 type userService struct {
 	userRepo repository.UserRepository
+	roleRepo repository.RoleRepository
+	verifier identity.Verifier // nil si Identity Platform no está configurado
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo}
+func NewUserService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, verifier identity.Verifier) UserService {
+	return &userService{userRepo, roleRepo, verifier}
 }
 
 func (s *userService) RegisterUser(req *models.UserRegisterRequest) (*models.UserResponse, error) {
@@ -34,20 +41,45 @@ func (s *userService) RegisterUser(req *models.UserRegisterRequest) (*models.Use
 		return nil, ErrUserAlreadyExists
 	}
 
-	var hash string
-	if req.FirebaseUID == "" {
-		hash, err = utils.HashPassword(req.Password)
+	user := &models.User{
+		PhoneNumber: req.PhoneNumber,
+	}
+
+	switch {
+	case req.IDToken != "":
+		// El UID y la verificación del teléfono salen del token firmado
+		claims, err := s.verifyIDToken(req.IDToken)
+		if err != nil {
+			return nil, err
+		}
+		if claims.PhoneNumber != "" && claims.PhoneNumber != req.PhoneNumber {
+			return nil, ErrPhoneMismatch
+		}
+		linked, err := s.userRepo.FindByFirebaseUID(claims.UID)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		if linked != nil {
+			return nil, ErrUserAlreadyExists
+		}
+		user.FirebaseUID = claims.UID
+		user.IsVerified = claims.PhoneVerified
+	case req.Password != "":
+		user.PasswordHash, err = utils.HashPassword(req.Password)
 		if err != nil {
 			return nil, ErrPasswordHash
 		}
+	default:
+		return nil, ErrMissingCredential
 	}
 
-	user := &models.User{
-		PhoneNumber:  req.PhoneNumber,
-		PasswordHash: hash,
-		IsVerified:   req.IsVerified,
-		FirebaseUID:  req.FirebaseUID, // Para cuando uses Identity Platform
+	// Todo usuario nuevo inicia con el rol de minero; GORM inserta el usuario y
+	// la fila de user_roles en la misma transacción
+	role, err := s.roleRepo.FindByName(models.RoleMiner)
+	if err != nil {
+		return nil, fmt.Errorf("error al asignar el rol inicial: %w", err)
 	}
+	user.Roles = []models.Role{*role}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
@@ -57,6 +89,18 @@ func (s *userService) RegisterUser(req *models.UserRegisterRequest) (*models.Use
 		ID:          user.ID,
 		PhoneNumber: user.PhoneNumber,
 		IsVerified:  user.IsVerified,
+		Roles:       []string{models.RoleMiner},
 		CreatedAt:   user.CreatedAt,
 	}, nil
 }
+
+func (s *userService) verifyIDToken(idToken string) (*identity.Claims, error) {
+	if s.verifier == nil {
+		return nil, ErrIdentityDisabled
+	}
+	claims, err := s.verifier.Verify(idToken)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo verificar el ID token: %w", err)
+	}
+	return claims, nil
+}
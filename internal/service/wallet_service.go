@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+	"github.com/sanchezta/batea-backend/internal/repository"
+)
+
+var ErrSaleNotJournaled = errors.New("la venta es anterior a las billeteras y no tiene saldo por cobrar")
+
+// WalletService expone las billeteras de mineros y comercializadores sobre el
+// diario de partida doble. Los asientos de ventas y pagos los contabilizan los
+// repositorios de venta y de pago en su propia transacción; los que arma este
+// archivo mueven dinero entre las cuentas por cobrar, disponible y retenida del
+// minero.
+//
+// Una venta no le da saldo disponible al minero: queda por cobrar hasta que
+// SettleSale registra que llegó el dinero del comercializador. Así los pagos
+// a mineros solo salen de fondos ya recibidos.
+type WalletService interface {
+	GetWallet(ownerType models.AccountOwnerType, ownerID uuid.UUID, page, limit int) (*models.WalletResponse, error)
+	SettleSale(saleID uuid.UUID) (*models.JournalTransaction, error)
+}
+
+type walletService struct {
+	repo     repository.JournalRepository
+	saleRepo repository.SaleRepository
+}
+
+func NewWalletService(repo repository.JournalRepository, saleRepo repository.SaleRepository) WalletService {
+	return &walletService{repo: repo, saleRepo: saleRepo}
+}
+
+func (s *walletService) GetWallet(ownerType models.AccountOwnerType, ownerID uuid.UUID, page, limit int) (*models.WalletResponse, error) {
+	balances, err := s.repo.Balances(ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := s.repo.Statement(models.AccountRef{OwnerType: ownerType, OwnerID: ownerID, Kind: models.AccountWallet}, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WalletResponse{
+		Currency:     "COP",
+		AvailableCOP: balances[models.AccountWallet],
+		PendingCOP:   balances[models.AccountPending],
+		HeldCOP:      balances[models.AccountHold],
+		Page:         statement.Page,
+		Limit:        statement.Limit,
+		TotalRows:    statement.TotalRows,
+		TotalPages:   statement.TotalPages,
+		Statement:    statement.Data.([]models.StatementLine),
+	}, nil
+}
+
+// SettleSale registra que el comercializador pagó la venta: el dinero entra a
+// la cuenta de liquidación de la plataforma y pasa de por cobrar a disponible
+// en la billetera del minero. Repetirlo devuelve el asiento original.
+func (s *walletService) SettleSale(saleID uuid.UUID) (*models.JournalTransaction, error) {
+	sale, err := s.saleRepo.FindByID(saleID)
+	if err != nil {
+		return nil, err
+	}
+	// Las ventas anteriores al diario no dejaron nada por cobrar
+	if _, err := s.repo.FindByKey(saleJournalKey(sale)); err != nil {
+		if errors.Is(err, repository.ErrJournalNotFound) {
+			return nil, ErrSaleNotJournaled
+		}
+		return nil, err
+	}
+
+	posted, err := s.repo.Post(settlementJournal(sale))
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("fallo al contabilizar el pago de la venta: %w", err)
+	}
+	return posted, nil
+}
+
+// holdJournal pasa fondos del disponible del minero a su cuenta retenida.
+func holdJournal(minerID uuid.UUID, amountCOP int64, reference uuid.UUID, key string) *models.JournalTransaction {
+	return transferJournal(models.JournalHold, "Retención de fondos", key, reference, amountCOP,
+		minerAccount(minerID, models.AccountWallet), minerAccount(minerID, models.AccountHold))
+}
+
+// releaseJournal devuelve fondos retenidos al disponible.
+func releaseJournal(minerID uuid.UUID, amountCOP int64, reference uuid.UUID, key string) *models.JournalTransaction {
+	return transferJournal(models.JournalRelease, "Liberación de fondos retenidos", key, reference, amountCOP,
+		minerAccount(minerID, models.AccountHold), minerAccount(minerID, models.AccountWallet))
+}
+
+// payoutJournal da salida a fondos retenidos que ya se pagaron al minero.
+func payoutJournal(minerID uuid.UUID, amountCOP int64, reference uuid.UUID, key string) *models.JournalTransaction {
+	return transferJournal(models.JournalPayout, "Pago al minero", key, reference, amountCOP,
+		minerAccount(minerID, models.AccountHold), models.SystemAccount(models.AccountPayouts))
+}
+
+func transferJournal(kind models.JournalKind, description, key string, reference uuid.UUID, amountCOP int64, from, to models.AccountRef) *models.JournalTransaction {
+	txn := newJournalTransaction(kind, key, reference, description)
+	txn.Entries = []models.JournalEntry{
+		{Account: from, Direction: models.Debit, AmountCOP: amountCOP},
+		{Account: to, Direction: models.Credit, AmountCOP: amountCOP},
+	}
+	return txn
+}
+
+// saleJournal registra que el comercializador le debe al minero el total de la
+// venta, que el minero tiene por cobrar. Se contabiliza en la misma transacción
+// que inserta la venta, que siempre tiene comprador (solo las ventas antiguas
+// tienen BuyerID nil).
+func saleJournal(sale *models.Sale) *models.JournalTransaction {
+	txn := newJournalTransaction(models.JournalSale, saleJournalKey(sale), sale.ID,
+		fmt.Sprintf("Venta de %.3f g de oro", sale.WeightGrams))
+	txn.CreatedAt = sale.CreatedAt
+	txn.Entries = []models.JournalEntry{
+		{Account: buyerAccount(*sale.BuyerID), Direction: models.Debit, AmountCOP: sale.TotalCOP},
+		{Account: minerAccount(sale.MinerID, models.AccountPending), Direction: models.Credit, AmountCOP: sale.TotalCOP},
+	}
+	return txn
+}
+
+// settlementJournal salda la deuda del comercializador con el dinero recibido
+// y libera lo que el minero tenía por cobrar de la venta.
+func settlementJournal(sale *models.Sale) *models.JournalTransaction {
+	txn := newJournalTransaction(models.JournalSettlement, saleJournalKey(sale)+":settle", sale.ID,
+		"Pago recibido del comercializador")
+	txn.Entries = []models.JournalEntry{
+		{Account: models.SystemAccount(models.AccountSettlement), Direction: models.Debit, AmountCOP: sale.TotalCOP},
+		{Account: buyerAccount(*sale.BuyerID), Direction: models.Credit, AmountCOP: sale.TotalCOP},
+		{Account: minerAccount(sale.MinerID, models.AccountPending), Direction: models.Debit, AmountCOP: sale.TotalCOP},
+		{Account: minerAccount(sale.MinerID, models.AccountWallet), Direction: models.Credit, AmountCOP: sale.TotalCOP},
+	}
+	return txn
+}
+
+func saleJournalKey(sale *models.Sale) string {
+	return "sale:" + sale.ID.String()
+}
+
+func newJournalTransaction(kind models.JournalKind, key string, reference uuid.UUID, description string) *models.JournalTransaction {
+	return &models.JournalTransaction{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		IdempotencyKey: key,
+		Kind:           kind,
+		Reference:      &reference,
+		Description:    description,
+	}
+}
+
+func minerAccount(minerID uuid.UUID, kind models.AccountKind) models.AccountRef {
+	return models.AccountRef{OwnerType: models.OwnerMiner, OwnerID: minerID, Kind: kind}
+}
+
+func buyerAccount(buyerID uuid.UUID) models.AccountRef {
+	return models.AccountRef{OwnerType: models.OwnerBuyer, OwnerID: buyerID, Kind: models.AccountWallet}
+}
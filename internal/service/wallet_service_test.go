@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+// journalEffect suma el efecto de cada asiento sobre el saldo de su cuenta
+// (créditos menos débitos) y comprueba que la transacción esté balanceada.
+func journalEffect(t *testing.T, txn *models.JournalTransaction) map[models.AccountRef]int64 {
+	t.Helper()
+	effect := make(map[models.AccountRef]int64)
+	var total int64
+	for _, e := range txn.Entries {
+		if e.AmountCOP <= 0 {
+			t.Fatalf("%s: asiento con monto %d", txn.Kind, e.AmountCOP)
+		}
+		amount := e.AmountCOP
+		if e.Direction == models.Debit {
+			amount = -amount
+		}
+		effect[e.Account] += amount
+		total += amount
+	}
+	if total != 0 {
+		t.Fatalf("%s: la transacción no está balanceada (neto %d)", txn.Kind, total)
+	}
+	return effect
+}
+
+func TestWalletJournalsBalance(t *testing.T) {
+	minerID, buyerID, ref := uuid.New(), uuid.New(), uuid.New()
+	sale := &models.Sale{ID: ref, MinerID: minerID, BuyerID: &buyerID, WeightGrams: 10, TotalCOP: 3_500_000}
+	const amount = 250_000
+
+	wallet := minerAccount(minerID, models.AccountWallet)
+	pending := minerAccount(minerID, models.AccountPending)
+	hold := minerAccount(minerID, models.AccountHold)
+	buyer := buyerAccount(buyerID)
+
+	tests := []struct {
+		name string
+		txn  *models.JournalTransaction
+		want map[models.AccountRef]int64
+	}{
+		{
+			name: "la venta queda por cobrar",
+			txn:  saleJournal(sale),
+			want: map[models.AccountRef]int64{buyer: -3_500_000, pending: 3_500_000},
+		},
+		{
+			name: "el pago del comercializador libera la venta",
+			txn:  settlementJournal(sale),
+			want: map[models.AccountRef]int64{
+				models.SystemAccount(models.AccountSettlement): -3_500_000,
+				buyer:   3_500_000,
+				pending: -3_500_000,
+				wallet:  3_500_000,
+			},
+		},
+		{
+			name: "retención",
+			txn:  holdJournal(minerID, amount, ref, "hold"),
+			want: map[models.AccountRef]int64{wallet: -amount, hold: amount},
+		},
+		{
+			name: "liberación",
+			txn:  releaseJournal(minerID, amount, ref, "release"),
+			want: map[models.AccountRef]int64{hold: -amount, wallet: amount},
+		},
+		{
+			name: "pago al minero",
+			txn:  payoutJournal(minerID, amount, ref, "payout"),
+			want: map[models.AccountRef]int64{hold: -amount, models.SystemAccount(models.AccountPayouts): amount},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := journalEffect(t, tt.txn)
+			if len(got) != len(tt.want) {
+				t.Fatalf("efecto = %v, se esperaba %v", got, tt.want)
+			}
+			for account, want := range tt.want {
+				if got[account] != want {
+					t.Errorf("%s/%s: efecto %d, se esperaba %d", account.OwnerType, account.Kind, got[account], want)
+				}
+			}
+			if tt.txn.Reference == nil || *tt.txn.Reference != ref {
+				t.Errorf("referencia = %v, se esperaba %s", tt.txn.Reference, ref)
+			}
+		})
+	}
+}
+
+func TestSaleJournalKeysAreIdempotent(t *testing.T) {
+	buyerID := uuid.New()
+	sale := &models.Sale{ID: uuid.New(), MinerID: uuid.New(), BuyerID: &buyerID, TotalCOP: 1000}
+
+	// Reconstruir el asiento de la misma venta da la misma llave, y la venta y su pago no chocan
+	if saleJournal(sale).IdempotencyKey != saleJournal(sale).IdempotencyKey {
+		t.Fatal("la llave del asiento de venta no es estable")
+	}
+	if saleJournal(sale).IdempotencyKey == settlementJournal(sale).IdempotencyKey {
+		t.Fatal("la venta y su pago comparten llave de idempotencia")
+	}
+}
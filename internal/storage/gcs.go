@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// GCSStorage guarda los objetos en un bucket de Google Cloud Storage.
+type GCSStorage struct {
+	client   *gcs.Client
+	bucket   string
+	endpoint string // solo con emulador (fake-gcs-server)
+}
+
+// NewGCSStorage crea el cliente de GCS. Si endpoint no está vacío se usa un
+// servidor local compatible (por ejemplo fake-gcs-server en http://localhost:4443)
+// sin autenticación; también se respeta STORAGE_EMULATOR_HOST.
+func NewGCSStorage(ctx context.Context, bucket, credentialsPath, endpoint string) (*GCSStorage, error) {
+	var opts []option.ClientOption
+	switch {
+	case endpoint != "":
+		// Las lecturas van por la API JSON: la XML escapa las "/" de la llave y el
+		// emulador no encuentra el objeto
+		opts = append(opts,
+			option.WithEndpoint(strings.TrimRight(endpoint, "/")+"/storage/v1/"),
+			option.WithoutAuthentication(),
+			gcs.WithJSONReads(),
+		)
+	case credentialsPath != "":
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+
+	client, err := gcs.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear el cliente de GCS: %w", err)
+	}
+
+	// El emulador arranca vacío: se crea el bucket si no existe
+	if endpoint != "" {
+		if _, err := client.Bucket(bucket).Attrs(ctx); errors.Is(err, gcs.ErrBucketNotExist) {
+			if err := client.Bucket(bucket).Create(ctx, "local", nil); err != nil {
+				return nil, fmt.Errorf("error al crear el bucket en el emulador: %w", err)
+			}
+		}
+	}
+	return &GCSStorage{client: client, bucket: bucket, endpoint: strings.TrimRight(endpoint, "/")}, nil
+}
+
+func (s *GCSStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	w := s.client.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return fmt.Errorf("error al subir el objeto a GCS: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error al finalizar la subida a GCS: %w", err)
+	}
+	return nil
+}
+
+func (s *GCSStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("error al leer el objeto de GCS: %w", err)
+	}
+	return r, nil
+}
+
+func (s *GCSStorage) Delete(ctx context.Context, key string) error {
+	err := s.client.Bucket(s.bucket).Object(key).Delete(ctx)
+	if err != nil && !errors.Is(err, gcs.ErrObjectNotExist) {
+		return fmt.Errorf("error al eliminar el objeto de GCS: %w", err)
+	}
+	return nil
+}
+
+func (s *GCSStorage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	it := s.client.Bucket(s.bucket).Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error al listar el bucket de GCS: %w", err)
+		}
+		if err := fn(ObjectInfo{Key: attrs.Name, Updated: attrs.Updated}); err != nil {
+			return err
+		}
+	}
+}
+
+// SignedURL genera una URL V4 firmada de solo lectura. Con el emulador, que no
+// valida firmas, devuelve la URL de descarga directa.
+func (s *GCSStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if s.endpoint != "" {
+		return fmt.Sprintf("%s/download/storage/v1/b/%s/o/%s?alt=media",
+			s.endpoint, url.PathEscape(s.bucket), url.PathEscape(key)), nil
+	}
+	u, err := s.client.Bucket(s.bucket).SignedURL(key, &gcs.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(ttl),
+		Scheme:  gcs.SigningSchemeV4,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error al firmar la URL de GCS: %w", err)
+	}
+	return u, nil
+}
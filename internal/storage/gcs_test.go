@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeGCSStorage conecta con el fake-gcs-server de GCS_ENDPOINT (por ejemplo
+// http://localhost:4443) en un bucket propio de la prueba. Sin emulador disponible
+// la prueba se omite.
+func newFakeGCSStorage(t *testing.T) *GCSStorage {
+	t.Helper()
+	endpoint := os.Getenv("GCS_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("GCS_ENDPOINT no está definido; se omite la prueba contra fake-gcs-server")
+	}
+	client := http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(strings.TrimRight(endpoint, "/") + "/storage/v1/b")
+	if err != nil {
+		t.Skipf("fake-gcs-server no responde en %s: %v", endpoint, err)
+	}
+	resp.Body.Close()
+
+	bucket := fmt.Sprintf("batea-test-%d", time.Now().UnixNano())
+	s, err := NewGCSStorage(context.Background(), bucket, "", endpoint)
+	if err != nil {
+		t.Fatalf("NewGCSStorage: %v", err)
+	}
+	return s
+}
+
+func TestGCSStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newFakeGCSStorage(t)
+	key := "miners/abc/id_photo_front/foto.jpg"
+
+	if err := s.Put(ctx, key, strings.NewReader("contenido"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(got) != "contenido" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+
+	objects, err := listAll(ctx, s, "miners/abc/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != key {
+		t.Fatalf("List = %+v, se esperaba solo %s", objects, key)
+	}
+	if objects, _ := listAll(ctx, s, "buyers/"); len(objects) != 0 {
+		t.Fatalf("List con otro prefijo = %+v, se esperaba vacío", objects)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("Get tras Delete = %v, se esperaba ErrObjectNotFound", err)
+	}
+	// Borrar un objeto inexistente no es un error
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete repetido: %v", err)
+	}
+}
+
+func TestGCSStorageSignedURL(t *testing.T) {
+	ctx := context.Background()
+	s := newFakeGCSStorage(t)
+	key := "buyers/xyz/rucom_certificate/certificado.pdf"
+
+	if err := s.Put(ctx, key, strings.NewReader("%PDF-1.4"), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	signed, err := s.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+
+	// El emulador no firma: la URL descarga el objeto directamente
+	resp, err := http.Get(signed)
+	if err != nil {
+		t.Fatalf("GET %s: %v", signed, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "%PDF-1.4" {
+		t.Fatalf("GET %s = %d %q", signed, resp.StatusCode, body)
+	}
+}
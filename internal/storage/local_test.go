@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost/api/v1/files/", []byte("llave-de-prueba"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	return s
+}
+
+// listAll junta en un slice todo lo que List entrega para prefix.
+func listAll(ctx context.Context, s Storage, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := s.List(ctx, prefix, func(obj ObjectInfo) error {
+		objects = append(objects, obj)
+		return nil
+	})
+	return objects, err
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+	key := "miners/abc/id_photo_front/foto.jpg"
+
+	if err := s.Put(ctx, key, strings.NewReader("contenido"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(got) != "contenido" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+
+	objects, err := listAll(ctx, s, "miners/abc/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != key {
+		t.Fatalf("List = %+v, se esperaba solo %s", objects, key)
+	}
+	if objects, _ := listAll(ctx, s, "buyers/"); len(objects) != 0 {
+		t.Fatalf("List con otro prefijo = %+v, se esperaba vacío", objects)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("Get tras Delete = %v, se esperaba ErrObjectNotFound", err)
+	}
+	// Borrar un objeto inexistente no es un error
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete repetido: %v", err)
+	}
+}
+
+func TestLocalStorageRejectsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+
+	for _, key := range []string{"", "../fuera.txt", "miners/../../fuera.txt", "miners//doble", "miners/./punto"} {
+		t.Run(key, func(t *testing.T) {
+			if err := s.Put(ctx, key, strings.NewReader("x"), "text/plain"); err == nil {
+				t.Errorf("Put(%q) no devolvió error", key)
+			}
+			if _, err := s.SignedURL(ctx, key, time.Minute); err == nil {
+				t.Errorf("SignedURL(%q) no devolvió error", key)
+			}
+		})
+	}
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+	other, err := NewLocalStorage(t.TempDir(), "http://localhost/api/v1/files", []byte("otra-llave"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "miners/abc/rucon/certificado con espacio.pdf"
+
+	// signed devuelve la llave, la expiración y la firma de una URL generada
+	signed := func(t *testing.T, ttl time.Duration) (string, string, string) {
+		t.Helper()
+		raw, err := s.SignedURL(ctx, key, ttl)
+		if err != nil {
+			t.Fatalf("SignedURL: %v", err)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("URL inválida %q: %v", raw, err)
+		}
+		gotKey := strings.TrimPrefix(u.Path, "/api/v1/files/")
+		return gotKey, u.Query().Get("expires"), u.Query().Get("signature")
+	}
+
+	validKey, validExp, validSig := signed(t, time.Minute)
+	if validKey != key {
+		t.Fatalf("la URL apunta a %q, se esperaba %q", validKey, key)
+	}
+	expiredKey, expiredExp, expiredSig := signed(t, -time.Minute)
+
+	tests := []struct {
+		name      string
+		verifier  *LocalStorage
+		key       string
+		expires   string
+		signature string
+		wantErr   bool
+	}{
+		{"vigente", s, validKey, validExp, validSig, false},
+		{"expirada", s, expiredKey, expiredExp, expiredSig, true},
+		{"expiración alterada", s, validKey, validExp + "0", validSig, true},
+		{"expiración no numérica", s, validKey, "mañana", validSig, true},
+		{"otra llave de objeto", s, "miners/abc/rucon/otro.pdf", validExp, validSig, true},
+		{"firma alterada", s, validKey, validExp, strings.Repeat("0", len(validSig)), true},
+		{"otra llave de firma", other, validKey, validExp, validSig, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.verifier.VerifySignature(tt.key, tt.expires, tt.signature)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("VerifySignature = %v, se esperaba ErrInvalidSignature", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("VerifySignature: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/sanchezta/batea-backend/internal/config"
+)
+
+var ErrObjectNotFound = errors.New("objeto no encontrado en el almacenamiento")
+
+// Storage abstrae dónde se guardan los documentos subidos (disco local o GCS).
+// Las llaves son rutas relativas con "/" como separador, por ejemplo "miners/<id>/id_photo_front/<id>.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// List llama fn con cada objeto cuya llave empieza con prefix, a medida que
+	// los recorre, para no cargar el listado completo en memoria. Si fn devuelve
+	// un error el recorrido se detiene y List lo devuelve.
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+}
+
+// ObjectInfo describe un objeto almacenado.
+type ObjectInfo struct {
+	Key     string
+	Updated time.Time
+}
+
+// New crea el almacenamiento configurado: "gcs" usa Google Cloud Storage y
+// "local" guarda en UploadDir (por defecto, salvo que haya un bucket configurado).
+func New(ctx context.Context, cfg *config.Config) (Storage, error) {
+	switch cfg.StorageBackend {
+	case "gcs":
+		if cfg.GCSBucketName == "" {
+			return nil, errors.New("STORAGE_BACKEND=gcs requiere GCS_BUCKET_NAME")
+		}
+		log.Printf("Almacenamiento de documentos: GCS (bucket %s)", cfg.GCSBucketName)
+		return NewGCSStorage(ctx, cfg.GCSBucketName, cfg.GoogleCredentialsPath, cfg.GCSEndpoint)
+	case "local":
+		if cfg.FileURLSecret == "" {
+			return nil, errors.New("STORAGE_BACKEND=local requiere FILE_URL_SECRET")
+		}
+		log.Printf("Almacenamiento de documentos: local (%s)", cfg.UploadDir)
+		return NewLocalStorage(cfg.UploadDir, cfg.PublicBaseURL+"/api/v1/files", []byte(cfg.FileURLSecret))
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND '%s' no reconocido (use local o gcs)", cfg.StorageBackend)
+	}
+}
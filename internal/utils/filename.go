@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// maxFileNameLength limita el largo del nombre original que se guarda como metadata.
+const maxFileNameLength = 120
+
+// SanitizeFileName deja solo la parte final del nombre (sin directorios) y
+// reemplaza todo lo que no sea letra, dígito, punto, guion o guion bajo.
+// El resultado es apto para mostrar, nunca se usa para construir rutas.
+func SanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	clean := strings.TrimLeft(b.String(), ".")
+	if len(clean) > maxFileNameLength {
+		clean = clean[len(clean)-maxFileNameLength:]
+	}
+	if clean == "" {
+		return "archivo"
+	}
+	return clean
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Issuer de los tokens de acceso emitidos por el backend
+const TokenIssuer = "batea-backend"
+
+var ErrInvalidAccessToken = errors.New("token de acceso inválido o expirado")
+
+// AccessClaims son los claims del token de acceso firmado (HS256).
+type AccessClaims struct {
+	jwt.RegisteredClaims
+}
+
+// GenerateAccessToken firma un token de acceso de corta duración para el usuario.
+func GenerateAccessToken(secret string, userID uuid.UUID, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ID:        uuid.NewString(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// ParseAccessToken valida la firma y vigencia del token y devuelve el ID del usuario.
+func ParseAccessToken(secret, tokenString string) (uuid.UUID, error) {
+	var claims AccessClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidAccessToken, err)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, ErrInvalidAccessToken
+	}
+	return userID, nil
+}
+
+// GenerateRefreshToken genera un token opaco aleatorio (256 bits) codificado en base64url.
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken devuelve el hash SHA-256 (hex) de un token para almacenarlo en la base de datos.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateNumericCode genera un código numérico aleatorio de n dígitos (por ejemplo para SMS).
+func GenerateNumericCode(n int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + d.Int64()))
+	}
+	return sb.String(), nil
+}
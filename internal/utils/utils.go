@@ -1,97 +1,131 @@
-//
-
 package utils
 
 import (
 	"fmt"
+	"image"
+	_ "image/jpeg" // registra el decodificador JPEG
+	_ "image/png"  // registra el decodificador PNG
+	"io"
 	"mime/multipart"
-	"os"
-	"path/filepath"
 	"strings"
 
-	"github.com/sanchezta/batea-backend/internal/config"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/ledongthuc/pdf"
 	"github.com/sanchezta/batea-backend/internal/models"
 )
 
-// SaveFile guarda un archivo de la solicitud multipart en el disco local (simulación de GCP).
-func SaveFile(cfg *config.Config, file *multipart.FileHeader, subdir string) (string, error) {
-	if file == nil {
-		return "", fmt.Errorf("el archivo no puede ser nulo")
+// ValidateFile valida tamaño y tipo real del archivo. El tipo se detecta por
+// los bytes (número mágico), no por el Content-Type ni la extensión que envía
+// el cliente. Además se abre el contenido: los PDF deben poder leerse y respetar
+// el límite de páginas, y las imágenes deben decodificar con dimensiones válidas.
+func ValidateFile(field models.DocumentField) error {
+	f := field.FileHeader
+
+	// Si no es requerido y el archivo no se subió, es válido.
+	if !field.Required && f == nil {
+		return nil
 	}
 
-	// 1. Crear el subdirectorio (por ejemplo, 'cedulas' o 'pdfs')
-	fullDir := filepath.Join(cfg.UploadDir, subdir)
-	if err := os.MkdirAll(fullDir, 0755); err != nil {
-		return "", fmt.Errorf("error al crear el directorio de carga: %w", err)
+	// Si es requerido y el archivo no se subió.
+	if field.Required && f == nil {
+		return fmt.Errorf("documento requerido no proporcionado")
 	}
 
-	// 2. Generar nombre de archivo único
-	// Usamos el nombre original con un prefijo o sufijo para evitar colisiones
-	fileName := strings.ReplaceAll(file.Filename, " ", "_")
-	targetPath := filepath.Join(fullDir, fileName)
+	// Validación de tamaño máximo
+	if f.Size > field.MaxSizeBytes {
+		return fmt.Errorf("el archivo '%s' excede el tamaño máximo permitido de %s MB",
+			f.Filename, byteCountToMB(field.MaxSizeBytes))
+	}
 
-	// 3. Abrir el archivo de entrada
-	src, err := file.Open()
+	src, err := f.Open()
 	if err != nil {
-		return "", fmt.Errorf("error al abrir el archivo de subida: %w", err)
+		return fmt.Errorf("no se pudo leer el archivo '%s': %w", f.Filename, err)
 	}
 	defer src.Close()
 
-	// 4. Crear el archivo de destino
-	dst, err := os.Create(targetPath)
+	detected, err := mimetype.DetectReader(src)
 	if err != nil {
-		return "", fmt.Errorf("error al crear el archivo de destino: %w", err)
+		return fmt.Errorf("no se pudo leer el archivo '%s': %w", f.Filename, err)
 	}
-	defer dst.Close()
-
-	// 5. Copiar el contenido
-	if _, err := dst.ReadFrom(src); err != nil {
-		return "", fmt.Errorf("error al copiar el archivo: %w", err)
+	if !mimetype.EqualsAny(detected.String(), field.AllowedMimeTypes...) {
+		return fmt.Errorf("el contenido de '%s' es de tipo %s. Tipos permitidos: %s",
+			f.Filename, detected.String(), strings.Join(field.AllowedMimeTypes, ", "))
 	}
 
-	// La ruta que se guarda en DB es relativa al directorio de uploads (o la URL de GCP)
-	relativePath := filepath.Join(subdir, fileName)
-	return relativePath, nil
-}
-
-// ValidateFile realiza las validaciones de tipo MIME y tamaño.
-func ValidateFile(field models.DocumentField) error {
-	f := field.FileHeader
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("no se pudo leer el archivo '%s': %w", f.Filename, err)
+	}
 
-	// Si no es requerido y el archivo no se subió, es válido.
-	if !field.Required && f == nil {
-		return nil
+	switch {
+	case detected.Is("application/pdf"):
+		return validatePDF(f.Filename, src, f.Size, field.MaxPages)
+	case strings.HasPrefix(detected.String(), "image/"):
+		return validateImage(f.Filename, src, field.MinImageSide, field.MaxImageSide)
 	}
+	return nil
+}
 
-	// Si es requerido y el archivo no se subió.
-	if field.Required && f == nil {
-		return fmt.Errorf("documento requerido no proporcionado")
+// DetectContentType devuelve el tipo MIME real del archivo según su contenido.
+func DetectContentType(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
 	}
+	defer src.Close()
 
-	// Validación de tamaño máximo (simulando límite de páginas)
-	if f.Size > field.MaxSizeBytes {
-		return fmt.Errorf("el archivo '%s' excede el tamaño máximo permitido de %s MB. (Simulación de límite de páginas)",
-			f.Filename, byteCountToMB(field.MaxSizeBytes))
+	detected, err := mimetype.DetectReader(src)
+	if err != nil {
+		return "", err
 	}
+	// Sin parámetros como "; charset=..."
+	return strings.SplitN(detected.String(), ";", 2)[0], nil
+}
 
-	// Validación de tipo MIME
-	// La detección precisa del tipo MIME requiere leer el contenido, pero para un multipart simple,
-	// podemos usar la extensión del nombre o una validación ligera.
-	// Nota: `CheckContentType` de Gin es más estricto, aquí hacemos una validación básica.
-	
-	validMime := false
-	for _, mimeType := range field.AllowedMimeTypes {
-		if strings.Contains(strings.ToLower(f.Header.Get("Content-Type")), strings.ToLower(mimeType)) || strings.HasSuffix(strings.ToLower(f.Filename), "."+mimeType) {
-			validMime = true
-			break
+// validatePDF comprueba que el PDF tenga una estructura legible y cuenta sus páginas.
+func validatePDF(name string, r io.ReaderAt, size int64, maxPages int) (err error) {
+	// El parser entra en pánico con algunos archivos corruptos
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("el PDF '%s' está dañado o no se puede leer", name)
 		}
+	}()
+
+	doc, err := pdf.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("el PDF '%s' está dañado o no se puede leer: %w", name, err)
+	}
+	pages := doc.NumPage()
+	if pages == 0 {
+		return fmt.Errorf("el PDF '%s' no tiene páginas", name)
 	}
+	if maxPages > 0 && pages > maxPages {
+		return fmt.Errorf("el PDF '%s' tiene %d páginas; el máximo permitido es %d", name, pages, maxPages)
+	}
+	return nil
+}
 
-	if !validMime {
-		return fmt.Errorf("el tipo de archivo para '%s' no es válido. Tipos permitidos: %s",
-			f.Filename, strings.Join(field.AllowedMimeTypes, ", "))
+// validateImage revisa las dimensiones antes de decodificar la imagen completa,
+// para no reservar memoria con imágenes gigantes.
+func validateImage(name string, r io.ReadSeeker, minSide, maxSide int) error {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return fmt.Errorf("la imagen '%s' está dañada o no se puede leer: %w", name, err)
+	}
+	if minSide > 0 && (cfg.Width < minSide || cfg.Height < minSide) {
+		return fmt.Errorf("la imagen '%s' mide %dx%d px; el mínimo es %dx%d px",
+			name, cfg.Width, cfg.Height, minSide, minSide)
+	}
+	if maxSide > 0 && (cfg.Width > maxSide || cfg.Height > maxSide) {
+		return fmt.Errorf("la imagen '%s' mide %dx%d px; el máximo es %dx%d px",
+			name, cfg.Width, cfg.Height, maxSide, maxSide)
 	}
 
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, _, err := image.Decode(r); err != nil {
+		return fmt.Errorf("la imagen '%s' está dañada o no se puede leer: %w", name, err)
+	}
 	return nil
 }
 
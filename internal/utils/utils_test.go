@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/sanchezta/batea-backend/internal/models"
+)
+
+// fileHeader arma el *multipart.FileHeader que recibiría un controlador.
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(32 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func testPDF(t *testing.T, pages int) []byte {
+	t.Helper()
+	doc := gofpdf.New("P", "mm", "Letter", "")
+	doc.SetFont("Helvetica", "", 12)
+	for i := range pages {
+		doc.AddPage()
+		doc.Cell(40, 10, strings.Repeat("Página ", i+1))
+	}
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Cabeceras reales de ejecutables Linux (ELF) y Windows (PE)
+var (
+	elfExecutable = append([]byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x3e\x00"), make([]byte, 64)...)
+	peExecutable  = append([]byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff"), make([]byte, 64)...)
+)
+
+func TestValidateFilePDF(t *testing.T) {
+	valid := testPDF(t, 2)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		maxPages int
+		maxSize  int64
+		wantErr  string
+	}{
+		{name: "PDF válido", filename: "rucom.pdf", content: valid, maxPages: 2},
+		{name: "sin límite de páginas", filename: "rucom.pdf", content: testPDF(t, 12)},
+		{name: "excede el máximo de páginas", filename: "rucom.pdf", content: testPDF(t, 3), maxPages: 2, wantErr: "tiene 3 páginas; el máximo permitido es 2"},
+		{name: "ejecutable renombrado", filename: "rucom.pdf", content: elfExecutable, wantErr: "Tipos permitidos"},
+		{name: "imagen renombrada", filename: "rucom.pdf", content: testPNG(t, 800, 600), wantErr: "es de tipo image/png"},
+		{name: "PDF truncado", filename: "rucom.pdf", content: valid[:len(valid)/2], wantErr: "está dañado"},
+		{name: "cabecera PDF sin contenido", filename: "rucom.pdf", content: []byte("%PDF-1.4\n%%EOF\n"), wantErr: "está dañado"},
+		{name: "excede el tamaño", filename: "rucom.pdf", content: valid, maxSize: int64(len(valid) - 1), wantErr: "excede el tamaño máximo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = 5 * models.Megabyte
+			}
+			err := ValidateFile(models.DocumentField{
+				FileHeader:       fileHeader(t, tt.filename, tt.content),
+				Required:         true,
+				MaxSizeBytes:     maxSize,
+				AllowedMimeTypes: []string{"application/pdf"},
+				MaxPages:         tt.maxPages,
+			})
+			checkValidateErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateFileImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		wantErr  string
+	}{
+		{name: "JPEG válido", filename: "cedula.jpg", content: testJPEG(t, 800, 600)},
+		{name: "PNG válido", filename: "cedula.png", content: testPNG(t, 640, 640)},
+		{name: "en el mínimo y el máximo", filename: "cedula.png", content: testPNG(t, 320, 2000)},
+		{name: "extensión distinta al contenido", filename: "cedula.png", content: testJPEG(t, 800, 600)},
+		{name: "ancho menor al mínimo", filename: "cedula.jpg", content: testJPEG(t, 319, 600), wantErr: "el mínimo es 320x320 px"},
+		{name: "alto menor al mínimo", filename: "cedula.png", content: testPNG(t, 600, 100), wantErr: "el mínimo es 320x320 px"},
+		{name: "mayor al máximo", filename: "cedula.png", content: testPNG(t, 2001, 600), wantErr: "el máximo es 2000x2000 px"},
+		{name: "ejecutable de Windows renombrado", filename: "cedula.jpg", content: peExecutable, wantErr: "Tipos permitidos"},
+		{name: "ejecutable de Linux renombrado", filename: "cedula.jpg", content: elfExecutable, wantErr: "Tipos permitidos"},
+		{name: "PDF renombrado", filename: "cedula.jpg", content: testPDF(t, 1), wantErr: "es de tipo application/pdf"},
+		{name: "imagen truncada", filename: "cedula.png", content: testPNG(t, 800, 600)[:60], wantErr: "está dañada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFile(models.DocumentField{
+				FileHeader:       fileHeader(t, tt.filename, tt.content),
+				Required:         true,
+				MaxSizeBytes:     5 * models.Megabyte,
+				AllowedMimeTypes: []string{"image/jpeg", "image/png"},
+				MinImageSide:     320,
+				MaxImageSide:     2000,
+			})
+			checkValidateErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateFileRequired(t *testing.T) {
+	if err := ValidateFile(models.DocumentField{Required: false}); err != nil {
+		t.Fatalf("documento opcional sin archivo: %v", err)
+	}
+	if err := ValidateFile(models.DocumentField{Required: true}); err == nil {
+		t.Fatal("documento requerido sin archivo no devolvió error")
+	}
+}
+
+// panicReader imita al parser de PDF cuando entra en pánico con un archivo corrupto.
+type panicReader struct{}
+
+func (panicReader) ReadAt([]byte, int64) (int, error) {
+	panic("índice fuera de rango")
+}
+
+func TestValidatePDFRecoversFromPanic(t *testing.T) {
+	err := validatePDF("rucom.pdf", panicReader{}, 1024, 0)
+	checkValidateErr(t, err, "está dañado")
+}
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		want     string
+	}{
+		{name: "PDF", filename: "rucom.pdf", content: testPDF(t, 1), want: "application/pdf"},
+		{name: "JPEG con nombre de PDF", filename: "rucom.pdf", content: testJPEG(t, 10, 10), want: "image/jpeg"},
+		{name: "ejecutable con nombre de imagen", filename: "foto.jpg", content: elfExecutable, want: "application/x-executable"},
+		{name: "texto sin charset", filename: "notas.pdf", content: []byte("hola mundo\n"), want: "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectContentType(fileHeader(t, tt.filename, tt.content))
+			if err != nil || got != tt.want {
+				t.Fatalf("DetectContentType = %q, %v; se esperaba %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func checkValidateErr(t *testing.T, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Fatalf("error inesperado: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %v, se esperaba uno con %q", err, want)
+	}
+}